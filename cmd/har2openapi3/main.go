@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/grokify/spectrum/har/haropenapi3"
+	"github.com/grokify/spectrum/openapi3"
+	flags "github.com/jessevdk/go-flags"
+)
+
+// install: go get github.com/grokify/spectrum/cmd/har2openapi3
+
+type Options struct {
+	HARFiles         []string `short:"i" long:"input" description:"Input HAR filepath, can be repeated" required:"true"`
+	OAS3File         string   `short:"o" long:"output" description:"Output filepath" required:"true"`
+	Title            string   `short:"t" long:"title" description:"API title"`
+	Version          string   `short:"v" long:"version" description:"API version"`
+	Hosts            []string `long:"host" description:"Only include requests for host, can be repeated"`
+	PathVarMinValues int      `long:"pathvarmin" description:"Distinct path segment values to create a path variable"`
+	RequiredRatio    float64  `long:"required" description:"Frequency from 0 to 1 for required parameters and properties"`
+}
+
+func main() {
+	opts := Options{}
+	_, err := flags.Parse(&opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	spec, err := haropenapi3.ReadFiles(&haropenapi3.Options{
+		Title:            strings.TrimSpace(opts.Title),
+		Version:          strings.TrimSpace(opts.Version),
+		Hosts:            opts.Hosts,
+		PathVarMinValues: opts.PathVarMinValues,
+		RequiredRatio:    opts.RequiredRatio,
+	}, opts.HARFiles...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sm := openapi3.SpecMore{Spec: spec}
+	err = sm.WriteFileJSON(strings.TrimSpace(opts.OAS3File), 0644, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("WROTE [%v] OPERATIONS [%d]\n", opts.OAS3File, sm.OperationsCount())
+
+	fmt.Println("DONE")
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"github.com/grokify/spectrum/openapi3"
+	"github.com/grokify/spectrum/openapi3edit"
+)
+
+type EditCommand struct {
+	SpecFile string `short:"s" long:"spec" description:"Input spec file" required:"true"`
+	Pipeline string `short:"p" long:"pipeline" description:"Pipeline file of edit steps" required:"true"`
+	Output   string `short:"o" long:"output" description:"Output spec file, stdout if empty"`
+}
+
+func (cmd *EditCommand) Execute(args []string) error {
+	pipeline, err := openapi3edit.ReadPipelineFile(cmd.Pipeline)
+	if err != nil {
+		return err
+	}
+	spec, err := openapi3.ReadFile(cmd.SpecFile, false)
+	if err != nil {
+		return err
+	}
+	se := openapi3edit.NewSpecEdit(spec)
+	if err := se.RunPipeline(pipeline); err != nil {
+		return err
+	}
+	return writeSpec(se.SpecMore.Spec, cmd.Output)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/grokify/spectrum/openapi3"
+)
+
+type FmtCommand struct {
+	Check      bool   `short:"c" long:"check" description:"List files which are not formatted and exit with an error instead of writing output"`
+	Write      bool   `short:"w" long:"write" description:"Write the result to the input files instead of stdout"`
+	Paths      string `long:"paths" description:"Path order" choice:"sorted" choice:"x-order" default:"sorted"`
+	Properties string `long:"properties" description:"Schema property order" choice:"alphabetical" choice:"source" default:"alphabetical"`
+	Args       struct {
+		Files []string `positional-arg-name:"file" required:"1"`
+	} `positional-args:"yes"`
+}
+
+func (cmd *FmtCommand) Execute(args []string) error {
+	opts := &openapi3.CanonicalOptions{
+		PathOrder:     cmd.Paths,
+		PropertyOrder: cmd.Properties}
+	unformatted := []string{}
+	for _, filename := range cmd.Args.Files {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		out, err := openapi3.FormatCanonical(data, opts, rxJSONFile.MatchString(filename))
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		switch {
+		case cmd.Check:
+			if !bytes.Equal(data, out) {
+				unformatted = append(unformatted, filename)
+				fmt.Println(filename)
+			}
+		case cmd.Write:
+			if !bytes.Equal(data, out) {
+				if err := os.WriteFile(filename, out, 0644); err != nil {
+					return err
+				}
+			}
+		default:
+			if _, err := os.Stdout.Write(out); err != nil {
+				return err
+			}
+		}
+	}
+	if len(unformatted) > 0 {
+		return fmt.Errorf("files are not formatted [%d]", len(unformatted))
+	}
+	return nil
+}
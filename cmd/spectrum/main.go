@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"regexp"
+
+	"github.com/grokify/spectrum/openapi3"
+	flags "github.com/jessevdk/go-flags"
+)
+
+// install: go install github.com/grokify/spectrum/cmd/spectrum
+
+type Options struct{}
+
+func main() {
+	parser := flags.NewParser(&Options{}, flags.Default)
+	_, err := parser.AddCommand("edit", "Run an edit pipeline",
+		"Runs the edit steps of a YAML or JSON pipeline file on a spec.", &EditCommand{})
+	if err != nil {
+		panic(err)
+	}
+	_, err = parser.AddCommand("fmt", "Format specs",
+		"Formats JSON and YAML specs with canonical key order.", &FmtCommand{})
+	if err != nil {
+		panic(err)
+	}
+	_, err = parser.AddCommand("overlay", "Apply overlays",
+		"Applies one or more OpenAPI Overlay files to a spec in order.", &OverlayCommand{})
+	if err != nil {
+		panic(err)
+	}
+	_, err = parser.AddCommand("overlay-diff", "Generate an overlay",
+		"Generates an OpenAPI Overlay which transforms one spec into another.", &OverlayDiffCommand{})
+	if err != nil {
+		panic(err)
+	}
+	if _, err := parser.Parse(); err != nil {
+		var flagsErr *flags.Error
+		if errors.As(err, &flagsErr) && flagsErr.Type == flags.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(1)
+	}
+}
+
+var rxJSONFile = regexp.MustCompile(`(?i)\.json$`)
+
+// writeSpec writes a canonically formatted spec as JSON for `.json` files
+// and YAML otherwise, or to stdout as YAML when `filename` is empty.
+func writeSpec(spec *openapi3.Spec, filename string) error {
+	sm := openapi3.SpecMore{Spec: spec}
+	if filename == "" {
+		bytes, err := sm.MarshalCanonical(nil, false)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(bytes)
+		return err
+	}
+	return sm.WriteFileCanonical(filename, 0644, nil)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+
+	"github.com/grokify/spectrum/openapi3"
+	"github.com/grokify/spectrum/openapi3edit"
+)
+
+type OverlayCommand struct {
+	SpecFile string   `short:"s" long:"spec" description:"Input spec file" required:"true"`
+	Overlays []string `short:"v" long:"overlay" description:"Overlay file, applied in order" required:"true"`
+	Output   string   `short:"o" long:"output" description:"Output spec file, stdout if empty"`
+}
+
+func (cmd *OverlayCommand) Execute(args []string) error {
+	spec, err := openapi3.ReadFile(cmd.SpecFile, false)
+	if err != nil {
+		return err
+	}
+	se := openapi3edit.NewSpecEdit(spec)
+	for _, filename := range cmd.Overlays {
+		ov, err := openapi3edit.ReadOverlayFile(filename)
+		if err != nil {
+			return err
+		}
+		if err := se.ApplyOverlay(ov); err != nil {
+			return err
+		}
+	}
+	return writeSpec(se.SpecMore.Spec, cmd.Output)
+}
+
+type OverlayDiffCommand struct {
+	From   string `short:"f" long:"from" description:"Original spec file" required:"true"`
+	To     string `short:"t" long:"to" description:"Modified spec file" required:"true"`
+	Output string `short:"o" long:"output" description:"Output overlay file, stdout if empty"`
+}
+
+func (cmd *OverlayDiffCommand) Execute(args []string) error {
+	spec1, err := openapi3.ReadFile(cmd.From, false)
+	if err != nil {
+		return err
+	}
+	spec2, err := openapi3.ReadFile(cmd.To, false)
+	if err != nil {
+		return err
+	}
+	ov, err := openapi3edit.OverlayDiff(spec1, spec2)
+	if err != nil {
+		return err
+	}
+	bytes, err := ov.MarshalYAML()
+	if err != nil {
+		return err
+	} else if cmd.Output == "" {
+		_, err = os.Stdout.Write(bytes)
+		return err
+	}
+	return os.WriteFile(cmd.Output, bytes, 0644)
+}
@@ -0,0 +1,369 @@
+package haropenapi3
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/mogo/errors/errorsutil"
+	"github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	PathVarMinValuesDefault = 10
+	RequiredRatioDefault    = 1.0
+)
+
+// HeadersSkipDefault are lower case request headers that are not converted to
+// parameters because they are set by clients, proxies or security schemes.
+var HeadersSkipDefault = []string{
+	"accept", "accept-encoding", "accept-language", "authorization",
+	"cache-control", "connection", "content-length", "content-type",
+	"cookie", "dnt", "host", "if-modified-since", "if-none-match", "origin",
+	"pragma", "referer", "te", "upgrade-insecure-requests", "user-agent"}
+
+// Options configures HAR conversion.
+type Options struct {
+	Title   string
+	Version string
+	// Hosts limits conversion to requests for these hosts. All hosts are used if empty.
+	Hosts []string
+	// PathVarMinValues is the number of distinct literal values at a path position
+	// that turns the position into a path variable. Identifier-like segments such as
+	// integers and UUIDs are always variables.
+	PathVarMinValues int
+	// RequiredRatio is the minimum frequency, from 0 to 1, at which a parameter or
+	// property must be observed to be required.
+	RequiredRatio float64
+	// HeadersSkip are lower case header names to exclude. `HeadersSkipDefault` is
+	// used if nil. Headers starting with `:` or `sec-` are always excluded.
+	HeadersSkip []string
+}
+
+func (opts *Options) defaults() *Options {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.PathVarMinValues == 0 {
+		o.PathVarMinValues = PathVarMinValuesDefault
+	}
+	if o.RequiredRatio <= 0 {
+		o.RequiredRatio = RequiredRatioDefault
+	}
+	if o.HeadersSkip == nil {
+		o.HeadersSkip = HeadersSkipDefault
+	}
+	return &o
+}
+
+func (opts *Options) headerSkip(name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if strings.HasPrefix(name, ":") || strings.HasPrefix(name, "sec-") {
+		return true
+	}
+	for _, skip := range opts.HeadersSkip {
+		if name == skip {
+			return true
+		}
+	}
+	return false
+}
+
+func (opts *Options) hostInclude(host string) bool {
+	if len(opts.Hosts) == 0 {
+		return true
+	}
+	for _, h := range opts.Hosts {
+		if strings.EqualFold(strings.TrimSpace(h), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadFiles reads one or more HAR files and converts all entries into a single spec.
+func ReadFiles(opts *Options, filenames ...string) (*openapi3.Spec, error) {
+	hars := []*HAR{}
+	for _, filename := range filenames {
+		h, err := ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		hars = append(hars, h)
+	}
+	return Convert(opts, hars...)
+}
+
+// entryURL is a HAR entry with its parsed URL.
+type entryURL struct {
+	entry Entry
+	url   *url.URL
+}
+
+// operationSamples holds the HAR entries for one generic path and method.
+type operationSamples struct {
+	path    string
+	method  string
+	entries []entryURL
+}
+
+// Convert infers an OpenAPI 3 spec from the entries of one or more HAR
+// documents. Request URLs are clustered into path templates, and parameters,
+// request bodies and responses are inferred from all matching entries.
+func Convert(opts *Options, hars ...*HAR) (*openapi3.Spec, error) {
+	opts = opts.defaults()
+	entries := []entryURL{}
+	servers := map[string]int{}
+	for _, h := range hars {
+		if h == nil {
+			continue
+		}
+		for _, e := range h.Log.Entries {
+			u, err := url.Parse(e.Request.URL)
+			if err != nil {
+				return nil, errorsutil.Wrapf(err, "error parsing har request url (%s)", e.Request.URL)
+			}
+			if !opts.hostInclude(u.Host) {
+				continue
+			}
+			servers[u.Scheme+"://"+u.Host]++
+			entries = append(entries, entryURL{entry: e, url: u})
+		}
+	}
+	paths := []string{}
+	for _, e := range entries {
+		paths = append(paths, e.url.Path)
+	}
+	pc := newPathClusterer(paths, opts.PathVarMinValues)
+
+	ops := map[string]*operationSamples{}
+	for _, e := range entries {
+		method := strings.ToUpper(strings.TrimSpace(e.entry.Request.Method))
+		generic := pc.Template(e.url.Path)
+		key := generic + " " + method
+		opSamples, ok := ops[key]
+		if !ok {
+			opSamples = &operationSamples{path: generic, method: method}
+			ops[key] = opSamples
+		}
+		opSamples.entries = append(opSamples.entries, e)
+	}
+
+	spec := openapi3.NewSpec(openapi3.OASVersionDefault, opts.Title, opts.Version)
+	spec.Paths = oas3.NewPaths()
+	for _, svrURL := range sortedKeys(servers) {
+		spec.Servers = append(spec.Servers, &oas3.Server{URL: svrURL})
+	}
+	for _, key := range sortedKeys(ops) {
+		opSamples := ops[key]
+		op, err := convertOperation(opSamples, opts)
+		if err != nil {
+			return nil, errorsutil.Wrapf(err, "error converting operation (%s)", key)
+		}
+		spec.AddOperation(PathTemplateNames(opSamples.path), opSamples.method, op)
+	}
+	return spec, nil
+}
+
+func convertOperation(opSamples *operationSamples, opts *Options) (*oas3.Operation, error) {
+	op := oas3.NewOperation()
+	total := len(opSamples.entries)
+	named := PathTemplateNames(opSamples.path)
+	names := openapi3.PathParams(named)
+	pathSegs := pathSegments(opSamples.path)
+
+	pathSamples := map[string]*openapi3.SchemaInferer{}
+	querySamples := map[string]*openapi3.SchemaInferer{}
+	headerSamples := map[string]*openapi3.SchemaInferer{}
+	headerNames := map[string]string{}
+	bodySamples := map[string]*openapi3.SchemaInferer{}
+	respSamples := map[int]map[string]*openapi3.SchemaInferer{}
+
+	for _, e := range opSamples.entries {
+		segs := pathSegments(e.url.Path)
+		varIdx := 0
+		for i, seg := range pathSegs {
+			if seg != PathVarGeneric || varIdx >= len(names) || i >= len(segs) {
+				continue
+			}
+			addSample(pathSamples, opts, names[varIdx]).AddText(segs[i])
+			varIdx++
+		}
+		seen := map[string]int{}
+		for _, nv := range queryValues(e) {
+			if seen[nv.Name] == 0 {
+				addSample(querySamples, opts, nv.Name).AddText(nv.Value)
+			}
+			seen[nv.Name]++
+		}
+		for _, nv := range e.entry.Request.Headers {
+			if opts.headerSkip(nv.Name) {
+				continue
+			}
+			lc := strings.ToLower(nv.Name)
+			if _, ok := headerNames[lc]; !ok {
+				headerNames[lc] = nv.Name
+			}
+			addSample(headerSamples, opts, lc).AddText(nv.Value)
+		}
+		if pd := e.entry.Request.PostData; pd != nil {
+			if err := addBodySample(bodySamples, opts, pd); err != nil {
+				return nil, errorsutil.Wrapf(err, "error parsing request body for url (%s)", e.entry.Request.URL)
+			}
+		}
+		resp := e.entry.Response
+		if resp.Status <= 0 {
+			continue
+		}
+		if _, ok := respSamples[resp.Status]; !ok {
+			respSamples[resp.Status] = map[string]*openapi3.SchemaInferer{}
+		}
+		mt := mediaTypeBase(resp.Content.MimeType)
+		if len(mt) == 0 || len(resp.Content.Text) == 0 {
+			continue
+		}
+		ss := addSample(respSamples[resp.Status], opts, mt)
+		if isJSONMediaType(mt) {
+			data, err := resp.Content.Bytes()
+			if err != nil {
+				return nil, err
+			}
+			if err := ss.AddJSON(data); err != nil {
+				return nil, errorsutil.Wrapf(err, "error parsing response body for url (%s)", e.entry.Request.URL)
+			}
+		} else {
+			ss.Add(resp.Content.Text)
+		}
+	}
+
+	for _, name := range names {
+		sch := oas3.NewStringSchema()
+		if ss, ok := pathSamples[name]; ok {
+			sch = ss.Schema()
+		}
+		op.Parameters = append(op.Parameters, &oas3.ParameterRef{
+			Value: oas3.NewPathParameter(name).WithSchema(sch)})
+	}
+	for _, name := range sortedKeys(querySamples) {
+		ss := querySamples[name]
+		param := oas3.NewQueryParameter(name).WithSchema(ss.Schema())
+		param.Required = float64(ss.Count())/float64(total) >= opts.RequiredRatio
+		op.Parameters = append(op.Parameters, &oas3.ParameterRef{Value: param})
+	}
+	for _, lc := range sortedKeys(headerSamples) {
+		ss := headerSamples[lc]
+		param := oas3.NewHeaderParameter(headerNames[lc]).WithSchema(ss.Schema())
+		param.Required = float64(ss.Count())/float64(total) >= opts.RequiredRatio
+		op.Parameters = append(op.Parameters, &oas3.ParameterRef{Value: param})
+	}
+	if len(bodySamples) > 0 {
+		content := oas3.NewContent()
+		for _, mt := range sortedKeys(bodySamples) {
+			content[mt] = oas3.NewMediaType().WithSchema(bodySamples[mt].Schema())
+		}
+		op.RequestBody = &oas3.RequestBodyRef{
+			Value: oas3.NewRequestBody().WithContent(content)}
+	}
+
+	op.Responses = oas3.NewResponsesWithCapacity(len(respSamples))
+	statuses := []int{}
+	for status := range respSamples {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	for _, status := range statuses {
+		resp := oas3.NewResponse().WithDescription(openapi3.ResponseDescriptionDefault(strconv.Itoa(status)))
+		if len(respSamples[status]) > 0 {
+			resp.Content = oas3.NewContent()
+			for _, mt := range sortedKeys(respSamples[status]) {
+				resp.Content[mt] = oas3.NewMediaType().WithSchema(respSamples[status][mt].Schema())
+			}
+		}
+		op.Responses.Set(strconv.Itoa(status), &oas3.ResponseRef{Value: resp})
+	}
+	return op, nil
+}
+
+// queryValues returns the HAR `queryString` values, falling back to the
+// request URL query.
+func queryValues(e entryURL) []NameValue {
+	if len(e.entry.Request.QueryString) > 0 {
+		return e.entry.Request.QueryString
+	}
+	nvs := []NameValue{}
+	q := e.url.Query()
+	for _, name := range sortedKeys(q) {
+		for _, v := range q[name] {
+			nvs = append(nvs, NameValue{Name: name, Value: v})
+		}
+	}
+	return nvs
+}
+
+func addBodySample(samples map[string]*openapi3.SchemaInferer, opts *Options, pd *PostData) error {
+	mt := mediaTypeBase(pd.MimeType)
+	if len(mt) == 0 {
+		mt = httputilmore.ContentTypeAppOctetStream
+	}
+	ss := addSample(samples, opts, mt)
+	switch {
+	case isJSONMediaType(mt):
+		if len(strings.TrimSpace(pd.Text)) > 0 {
+			return ss.AddJSON([]byte(pd.Text))
+		}
+	case len(pd.Params) > 0:
+		obj := map[string]any{}
+		for _, p := range pd.Params {
+			obj[p.Name] = p.Value
+		}
+		ss.Add(obj)
+	case mt == httputilmore.ContentTypeAppFormURLEncoded:
+		vals, err := url.ParseQuery(pd.Text)
+		if err != nil {
+			return err
+		}
+		obj := map[string]any{}
+		for name := range vals {
+			obj[name] = vals.Get(name)
+		}
+		ss.Add(obj)
+	default:
+		ss.Add(pd.Text)
+	}
+	return nil
+}
+
+func addSample(samples map[string]*openapi3.SchemaInferer, opts *Options, key string) *openapi3.SchemaInferer {
+	ss, ok := samples[key]
+	if !ok {
+		ss = openapi3.NewSchemaInferer(&openapi3.SchemaInferOptions{
+			RequiredRatio: opts.RequiredRatio})
+		samples[key] = ss
+	}
+	return ss
+}
+
+// isJSONMediaType returns true for `application/json` and `+json` media types.
+func isJSONMediaType(mt string) bool {
+	return strings.Contains(strings.ToLower(mt), "json")
+}
+
+func mediaTypeBase(mt string) string {
+	if idx := strings.Index(mt, ";"); idx > -1 {
+		mt = mt[:idx]
+	}
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
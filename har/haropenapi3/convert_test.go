@@ -0,0 +1,88 @@
+package haropenapi3
+
+import (
+	"testing"
+)
+
+var pathTemplateNamesTests = []struct {
+	generic string
+	named   string
+}{
+	{"/users/{}", "/users/{userId}"},
+	{"/users/{}/repos/{}", "/users/{userId}/repos/{repoId}"},
+	{"/{}/{}", "/{id}/{id2}"},
+	{"/categories/{}", "/categories/{categoryId}"},
+	{"/v1/status", "/v1/status"},
+}
+
+func TestPathTemplateNames(t *testing.T) {
+	for _, tt := range pathTemplateNamesTests {
+		named := PathTemplateNames(tt.generic)
+		if named != tt.named {
+			t.Errorf("haropenapi3.PathTemplateNames(\"%s\") mismatch: want [%s], got [%s]",
+				tt.generic, tt.named, named)
+		}
+	}
+}
+
+func testEntry(method, rawURL, reqBody, respBody string) Entry {
+	e := Entry{
+		Request: Request{
+			Method:  method,
+			URL:     rawURL,
+			Headers: []NameValue{{Name: "User-Agent", Value: "test"}, {Name: "X-Tenant", Value: "acme"}}},
+		Response: Response{
+			Status:  200,
+			Content: Content{MimeType: "application/json; charset=utf-8", Text: respBody}}}
+	if len(reqBody) > 0 {
+		e.Request.PostData = &PostData{MimeType: "application/json", Text: reqBody}
+	}
+	return e
+}
+
+func TestConvert(t *testing.T) {
+	har1 := &HAR{Log: Log{Entries: []Entry{
+		testEntry("GET", "https://api.example.com/users/1?expand=true", "", `{"id":1,"name":"Alice","email":"a@example.com"}`),
+		testEntry("GET", "https://api.example.com/users/2", "", `{"id":2,"name":"Bob"}`),
+	}}}
+	har2 := &HAR{Log: Log{Entries: []Entry{
+		testEntry("POST", "https://api.example.com/users", `{"name":"Carol"}`, `{"id":3,"name":"Carol"}`),
+		testEntry("GET", "https://other.example.com/ignored", "", `{}`),
+	}}}
+	spec, err := Convert(&Options{Title: "Users", Hosts: []string{"api.example.com"}}, har1, har2)
+	if err != nil {
+		t.Fatalf("haropenapi3.Convert() error [%v]", err)
+	}
+	if len(spec.Servers) != 1 || spec.Servers[0].URL != "https://api.example.com" {
+		t.Errorf("haropenapi3.Convert() servers mismatch")
+	}
+	if spec.Paths.Len() != 2 {
+		t.Fatalf("haropenapi3.Convert() paths count mismatch: want [2], got [%d]", spec.Paths.Len())
+	}
+	get := spec.Paths.Find("/users/{userId}").Get
+	if get == nil {
+		t.Fatalf("haropenapi3.Convert() operation missing [GET /users/{userId}]")
+	}
+	// path, query `expand` and header `X-Tenant`
+	if len(get.Parameters) != 3 {
+		t.Fatalf("haropenapi3.Convert() [GET /users/{userId}] parameters mismatch: want [3], got [%d]", len(get.Parameters))
+	}
+	if p := get.Parameters[0].Value; p.Name != "userId" || p.Schema.Value.Type != "integer" {
+		t.Errorf("haropenapi3.Convert() path parameter mismatch")
+	}
+	if p := get.Parameters[1].Value; p.Name != "expand" || p.Required || p.Schema.Value.Type != "boolean" {
+		t.Errorf("haropenapi3.Convert() query parameter mismatch")
+	}
+	if p := get.Parameters[2].Value; p.Name != "X-Tenant" || !p.Required {
+		t.Errorf("haropenapi3.Convert() header parameter mismatch")
+	}
+	respSch := get.Responses.Value("200").Value.Content["application/json"].Schema.Value
+	if len(respSch.Properties) != 3 || len(respSch.Required) != 2 {
+		t.Errorf("haropenapi3.Convert() response schema mismatch: want [3] properties and [2] required, got [%d] and [%d]",
+			len(respSch.Properties), len(respSch.Required))
+	}
+	post := spec.Paths.Find("/users").Post
+	if post == nil || post.RequestBody == nil || post.RequestBody.Value.Content["application/json"] == nil {
+		t.Errorf("haropenapi3.Convert() request body missing [POST /users]")
+	}
+}
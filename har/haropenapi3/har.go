@@ -0,0 +1,81 @@
+package haropenapi3
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+
+	"github.com/grokify/mogo/errors/errorsutil"
+)
+
+// HAR is an HTTP Archive 1.2 document. Only the fields used to infer an
+// OpenAPI spec are included.
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+type Log struct {
+	Version string  `json:"version,omitempty"`
+	Entries []Entry `json:"entries"`
+}
+
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime,omitempty"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+}
+
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion,omitempty"`
+	Headers     []NameValue `json:"headers,omitempty"`
+	QueryString []NameValue `json:"queryString,omitempty"`
+	PostData    *PostData   `json:"postData,omitempty"`
+}
+
+type Response struct {
+	Status     int         `json:"status"`
+	StatusText string      `json:"statusText,omitempty"`
+	Headers    []NameValue `json:"headers,omitempty"`
+	Content    Content     `json:"content"`
+}
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type PostData struct {
+	MimeType string      `json:"mimeType"`
+	Text     string      `json:"text,omitempty"`
+	Params   []NameValue `json:"params,omitempty"`
+}
+
+type Content struct {
+	Size     int64  `json:"size,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Bytes returns the response body, decoding `base64` encoded content.
+func (c Content) Bytes() ([]byte, error) {
+	if c.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(c.Text)
+	}
+	return []byte(c.Text), nil
+}
+
+// ReadFile reads a HAR file.
+func ReadFile(filename string) (*HAR, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	h := &HAR{}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, errorsutil.Wrapf(err, "error parsing har file (%s)", filename)
+	}
+	return h, nil
+}
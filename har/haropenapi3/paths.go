@@ -0,0 +1,191 @@
+package haropenapi3
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grokify/spectrum/internal/ramlutil"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const PathVarGeneric = "{}"
+
+var (
+	rxSegmentInteger = regexp.MustCompile(`^[0-9]+$`)
+	rxSegmentUUID    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	rxSegmentHex     = regexp.MustCompile(`^[0-9a-fA-F]{12,}$`)
+	rxSegmentToken   = regexp.MustCompile(`^[A-Za-z0-9_\-]{16,}$`)
+	rxSegmentDigit   = regexp.MustCompile(`[0-9]`)
+)
+
+// IsIDSegment returns true if a URL path segment looks like an identifier,
+// such as an integer, UUID, hex string or long token containing digits.
+func IsIDSegment(seg string) bool {
+	return rxSegmentInteger.MatchString(seg) ||
+		rxSegmentUUID.MatchString(seg) ||
+		rxSegmentHex.MatchString(seg) ||
+		(rxSegmentToken.MatchString(seg) && rxSegmentDigit.MatchString(seg))
+}
+
+// pathNode is a trie of URL path segments. Variable segments use the generic
+// `{}` form produced by `openapi3.PathVarsToGeneric`.
+type pathNode struct {
+	children map[string]*pathNode
+}
+
+func newPathNode() *pathNode {
+	return &pathNode{children: map[string]*pathNode{}}
+}
+
+func (n *pathNode) add(segs []string) {
+	if len(segs) == 0 {
+		return
+	}
+	child, ok := n.children[segs[0]]
+	if !ok {
+		child = newPathNode()
+		n.children[segs[0]] = child
+	}
+	child.add(segs[1:])
+}
+
+func (n *pathNode) merge(src *pathNode) {
+	for seg, srcChild := range src.children {
+		child, ok := n.children[seg]
+		if !ok {
+			n.children[seg] = srcChild
+			continue
+		}
+		child.merge(srcChild)
+	}
+}
+
+// generalize replaces literal children with a single `{}` child when there
+// are at least `minValues` distinct literal siblings.
+func (n *pathNode) generalize(minValues int) {
+	literals := []string{}
+	for seg := range n.children {
+		if seg != PathVarGeneric {
+			literals = append(literals, seg)
+		}
+	}
+	if minValues > 0 && len(literals) >= minValues {
+		v, ok := n.children[PathVarGeneric]
+		if !ok {
+			v = newPathNode()
+			n.children[PathVarGeneric] = v
+		}
+		for _, seg := range literals {
+			v.merge(n.children[seg])
+			delete(n.children, seg)
+		}
+	}
+	for _, child := range n.children {
+		child.generalize(minValues)
+	}
+}
+
+// template returns the generic path template for a segment list.
+func (n *pathNode) template(segs []string) []string {
+	out := []string{}
+	cur := n
+	for _, seg := range segs {
+		if child, ok := cur.children[seg]; ok {
+			out = append(out, seg)
+			cur = child
+		} else if child, ok := cur.children[PathVarGeneric]; ok {
+			out = append(out, PathVarGeneric)
+			cur = child
+		} else {
+			out = append(out, seg)
+			cur = newPathNode()
+		}
+	}
+	return out
+}
+
+// pathClusterer clusters concrete request paths into generic path templates.
+type pathClusterer struct {
+	root *pathNode
+}
+
+// newPathClusterer builds path templates from concrete paths. Identifier-like
+// segments always become variables. Other segments become variables when a
+// position has at least `minValues` distinct values.
+func newPathClusterer(paths []string, minValues int) *pathClusterer {
+	pc := &pathClusterer{root: newPathNode()}
+	for _, p := range paths {
+		pc.root.add(genericSegments(p))
+	}
+	pc.root.generalize(minValues)
+	return pc
+}
+
+// Template returns the generic template, e.g. `/users/{}/repos`, for a concrete path.
+func (pc *pathClusterer) Template(p string) string {
+	return "/" + strings.Join(pc.root.template(genericSegments(p)), "/")
+}
+
+func genericSegments(p string) []string {
+	segs := pathSegments(p)
+	for i, seg := range segs {
+		if IsIDSegment(seg) {
+			segs[i] = PathVarGeneric
+		}
+	}
+	return segs
+}
+
+func pathSegments(p string) []string {
+	p = strings.Trim(p, "/")
+	if len(p) == 0 {
+		return []string{}
+	}
+	return strings.Split(p, "/")
+}
+
+// PathTemplateNames names the variables in a generic path template. This is
+// the reverse of `openapi3.PathVarsToGeneric`, e.g. `/users/{}/repos/{}`
+// becomes `/users/{userId}/repos/{repoId}`.
+func PathTemplateNames(generic string) string {
+	segs := pathSegments(generic)
+	used := map[string]int{}
+	prev := ""
+	for i, seg := range segs {
+		if seg != PathVarGeneric {
+			prev = seg
+			continue
+		}
+		name := "id"
+		if len(prev) > 0 {
+			name = lowerCamel(ramlutil.Singularize(prev)) + "Id"
+		}
+		used[name]++
+		if used[name] > 1 {
+			name = fmt.Sprintf("%s%d", name, used[name])
+		}
+		segs[i] = "{" + name + "}"
+		prev = ""
+	}
+	named := "/" + strings.Join(segs, "/")
+	if !openapi3.PathMatchGeneric(named, generic) {
+		return generic
+	}
+	return named
+}
+
+// lowerCamel converts kebab and snake case segments to lower camel case.
+func lowerCamel(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ' '
+	})
+	for i, part := range parts {
+		if i == 0 {
+			parts[i] = strings.ToLower(part[:1]) + part[1:]
+		} else {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
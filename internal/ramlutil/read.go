@@ -0,0 +1,149 @@
+// Package ramlutil provides the RAML reading, template and security scheme
+// helpers shared by the RAML v0.8 and RAML 1.0 converters.
+package ramlutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/grokify/mogo/errors/errorsutil"
+	"gopkg.in/yaml.v3"
+)
+
+const TagInclude = "!include"
+
+var (
+	ErrDocumentEmpty   = errors.New("raml document is empty")
+	ErrDocumentNotMap  = errors.New("raml document root is not a map")
+	ErrIncludeNotFound = errors.New("raml include file not found")
+	ErrIncludeCycle    = errors.New("raml include cycle")
+
+	rxYAMLIncludeExt = regexp.MustCompile(`(?i)\.(raml|ya?ml)$`)
+)
+
+// ReadFileDocument reads a RAML file and returns it as a `map[string]any` with
+// all `!include` tags resolved.
+func ReadFileDocument(filename string) (map[string]any, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseDocument(data, filepath.Dir(filename), includeSet(filename))
+	if err != nil {
+		return nil, errorsutil.Wrapf(err, "error parsing raml file (%s)", filename)
+	}
+	return doc, nil
+}
+
+// ParseDocument parses RAML YAML bytes and returns it as a `map[string]any`
+// with all `!include` tags resolved. Map keys are always strings, including
+// response status codes.
+func ParseDocument(data []byte, baseDir string) (map[string]any, error) {
+	return parseDocument(data, baseDir, map[string]bool{})
+}
+
+// parseDocument parses a document where `including` is the set of absolute
+// filenames currently being read, used to detect `!include` cycles.
+func parseDocument(data []byte, baseDir string, including map[string]bool) (map[string]any, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	if node.Kind == 0 {
+		return nil, ErrDocumentEmpty
+	}
+	val, err := nodeToAny(&node, baseDir, including)
+	if err != nil {
+		return nil, err
+	}
+	msa, ok := val.(map[string]any)
+	if !ok {
+		return nil, ErrDocumentNotMap
+	}
+	return msa, nil
+}
+
+// nodeToAny converts a `yaml.Node` to generic Go values, resolving `!include`
+// tags. Included RAML and YAML files are parsed, while other files such as
+// JSON Schema and examples are returned as strings.
+func nodeToAny(node *yaml.Node, baseDir string, including map[string]bool) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return nodeToAny(node.Content[0], baseDir, including)
+	case yaml.AliasNode:
+		return nodeToAny(node.Alias, baseDir, including)
+	case yaml.MappingNode:
+		msa := map[string]any{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			val, err := nodeToAny(node.Content[i+1], baseDir, including)
+			if err != nil {
+				return nil, err
+			}
+			msa[node.Content[i].Value] = val
+		}
+		return msa, nil
+	case yaml.SequenceNode:
+		vals := []any{}
+		for _, child := range node.Content {
+			val, err := nodeToAny(child, baseDir, including)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, val)
+		}
+		return vals, nil
+	default:
+		if node.Tag == TagInclude {
+			return readInclude(strings.TrimSpace(node.Value), baseDir, including)
+		}
+		var val any
+		if err := node.Decode(&val); err != nil {
+			return nil, err
+		}
+		return val, nil
+	}
+}
+
+// readInclude reads an `!include` file. It returns `ErrIncludeCycle` if the
+// file is already being included further up the include chain.
+func readInclude(filename, baseDir string, including map[string]bool) (any, error) {
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(baseDir, filename)
+	}
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	if including[filename] {
+		return nil, errorsutil.Wrapf(ErrIncludeCycle, "filename (%s)", filename)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errorsutil.Wrapf(ErrIncludeNotFound, "filename (%s): %s", filename, err.Error())
+	}
+	if !rxYAMLIncludeExt.MatchString(filename) {
+		return string(data), nil
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("error parsing raml include (%s): %w", filename, err)
+	} else if node.Kind == 0 {
+		return nil, nil
+	}
+	including[filename] = true
+	defer delete(including, filename)
+	return nodeToAny(&node, filepath.Dir(filename), including)
+}
+
+func includeSet(filename string) map[string]bool {
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	return map[string]bool{filename: true}
+}
@@ -0,0 +1,216 @@
+package ramlutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/mogo/errors/errorsutil"
+	"github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/grokify/mogo/net/urlutil"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	KeyDisplayName   = "displayName"
+	KeyURIParameters = "uriParameters"
+
+	ParamResourcePath     = "resourcePath"
+	ParamResourcePathName = "resourcePathName"
+	ParamMethodName       = "methodName"
+
+	resourceTypeDepthMax = 20
+)
+
+// ResourceConverter walks RAML resources, applies resource types and traits
+// and adds the converted operations to `Spec`.
+type ResourceConverter struct {
+	Spec          *openapi3.Spec
+	ResourceTypes map[string]map[string]any
+	Traits        map[string]map[string]any
+	// TemplateKeysIgnore are removed from resource types and traits before
+	// they are merged, e.g. `usage` in RAML 1.0.
+	TemplateKeysIgnore []string
+	// ConvertMethod converts a method after traits are applied. `uriParams`
+	// holds the `uriParameters` of the resource and its parents.
+	ConvertMethod func(resourcePath string, resource, methodMap, uriParams map[string]any) (*oas3.Operation, error)
+	// PathItem is called for each resource with operations after the path
+	// item description and summary are set. It may be nil.
+	PathItem func(pathItem *oas3.PathItem, resource map[string]any)
+}
+
+// ConvertResources is a recursive function. Use "" for the basePath for RAML
+// root. `uriParams` contains the `uriParameters` inherited from parent
+// resources.
+func (rc *ResourceConverter) ConvertResources(basePath string, msa map[string]any, uriParams map[string]any) error {
+	keys := []string{}
+	for k := range msa {
+		if strings.Index(strings.TrimSpace(k), "/") == 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		childAny := msa[k]
+		child := map[string]any{}
+		if childAny != nil {
+			childMap, ok := childAny.(map[string]any)
+			if !ok {
+				return fmt.Errorf("value is not map[string]any for key [%s]", k)
+			}
+			child = DeepCopy(childMap).(map[string]any)
+		}
+		childAbsPath := urlutil.JoinAbsolute(basePath, strings.TrimSpace(k))
+		if err := rc.applyResourceType(childAbsPath, child, 0); err != nil {
+			return errorsutil.Wrapf(err, "error applying resource type for path (%s)", childAbsPath)
+		}
+		childURIParams := map[string]any{}
+		for name, np := range uriParams {
+			childURIParams[name] = np
+		}
+		if ups, ok := child[KeyURIParameters].(map[string]any); ok {
+			for name, np := range ups {
+				childURIParams[name] = np
+			}
+		}
+		if err := rc.convertResource(childAbsPath, child, childURIParams); err != nil {
+			return err
+		}
+		if err := rc.ConvertResources(childAbsPath, child, childURIParams); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyResourceType merges the resource type referenced by `type`, including
+// inherited resource types, into the resource.
+func (rc *ResourceConverter) applyResourceType(resourcePath string, resource map[string]any, depth int) error {
+	typeAny, ok := resource[KeyType]
+	if !ok || typeAny == nil {
+		return nil
+	} else if depth > resourceTypeDepthMax {
+		return fmt.Errorf("raml resource type inheritance too deep for path (%s)", resourcePath)
+	}
+	delete(resource, KeyType)
+	names, params, err := TemplateRefs(typeAny)
+	if err != nil {
+		return err
+	}
+	for i, name := range names {
+		tmpl, ok := rc.ResourceTypes[name]
+		if !ok {
+			return fmt.Errorf("raml resource type not found (%s)", name)
+		}
+		applied := rc.templateApply(tmpl, reservedParams(resourcePath, params[i]))
+		if err := rc.applyResourceType(resourcePath, applied, depth+1); err != nil {
+			return err
+		}
+		TemplateMerge(resource, applied)
+	}
+	return nil
+}
+
+// applyTraits merges traits referenced by `is` in the resource and method
+// into the method. Method level values take precedence.
+func (rc *ResourceConverter) applyTraits(resourcePath, method string, resource, methodMap map[string]any) error {
+	refs := []any{}
+	if isAny, ok := resource[KeyIs].([]any); ok {
+		refs = append(refs, isAny...)
+	}
+	if isAny, ok := methodMap[KeyIs].([]any); ok {
+		refs = append(refs, isAny...)
+	}
+	delete(methodMap, KeyIs)
+	names, params, err := TemplateRefs(refs)
+	if err != nil {
+		return err
+	}
+	for i, name := range names {
+		tmpl, ok := rc.Traits[name]
+		if !ok {
+			return fmt.Errorf("raml trait not found (%s)", name)
+		}
+		p := reservedParams(resourcePath, params[i])
+		p[ParamMethodName] = strings.ToLower(method)
+		TemplateMerge(methodMap, rc.templateApply(tmpl, p))
+	}
+	return nil
+}
+
+func (rc *ResourceConverter) templateApply(tmpl map[string]any, params map[string]any) map[string]any {
+	applied := TemplateApply(tmpl, params).(map[string]any)
+	for _, key := range rc.TemplateKeysIgnore {
+		delete(applied, key)
+	}
+	return applied
+}
+
+func reservedParams(resourcePath string, params map[string]any) map[string]any {
+	p := map[string]any{
+		ParamResourcePath:     resourcePath,
+		ParamResourcePathName: ResourcePathName(resourcePath)}
+	for k, v := range params {
+		p[k] = v
+	}
+	return p
+}
+
+func (rc *ResourceConverter) convertResource(resourcePath string, resource map[string]any, uriParams map[string]any) error {
+	methods := []string{}
+	for k := range resource {
+		if _, err := httputilmore.ParseHTTPMethod(k); err == nil {
+			methods = append(methods, k)
+		}
+	}
+	sort.Strings(methods)
+	for _, k := range methods {
+		methodCanonical, _ := httputilmore.ParseHTTPMethod(k)
+		methodMap := map[string]any{}
+		if m, ok := resource[k].(map[string]any); ok {
+			methodMap = m
+		} else if resource[k] != nil {
+			return fmt.Errorf("raml method is not a map for path (%s) method (%s)", resourcePath, k)
+		}
+		if err := rc.applyTraits(resourcePath, k, resource, methodMap); err != nil {
+			return errorsutil.Wrapf(err, "error applying traits for path (%s) method (%s)", resourcePath, k)
+		}
+		op, err := rc.ConvertMethod(resourcePath, resource, methodMap, uriParams)
+		if err != nil {
+			return errorsutil.Wrapf(err, "error converting path (%s) method (%s)", resourcePath, k)
+		}
+		rc.Spec.AddOperation(resourcePath, string(methodCanonical), op)
+	}
+	pathItem := rc.Spec.Paths.Find(resourcePath)
+	if pathItem != nil {
+		if desc, ok := resource[KeyDescription].(string); ok {
+			pathItem.Description = strings.TrimSpace(desc)
+		}
+		if dispName, ok := resource[KeyDisplayName].(string); ok {
+			pathItem.Summary = strings.TrimSpace(dispName)
+		}
+		if rc.PathItem != nil {
+			rc.PathItem(pathItem, resource)
+		}
+	}
+	return nil
+}
+
+// ExampleValue returns a RAML example, parsing strings which hold a JSON
+// object or array.
+func ExampleValue(exAny any) any {
+	exStr, ok := exAny.(string)
+	if !ok {
+		return exAny
+	}
+	trimmed := strings.TrimSpace(exStr)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var v any
+		if err := json.Unmarshal([]byte(trimmed), &v); err == nil {
+			return v
+		}
+	}
+	return exStr
+}
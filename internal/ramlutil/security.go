@@ -0,0 +1,181 @@
+package ramlutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	SecurityTypeOAuth2      = "OAuth 2.0"
+	SecurityTypeOAuth1      = "OAuth 1.0"
+	SecurityTypeBasic       = "Basic Authentication"
+	SecurityTypeDigest      = "Digest Authentication"
+	SecurityTypePassThrough = "Pass Through"
+
+	KeyDescribedBy     = "describedBy"
+	KeyDescription     = "description"
+	KeyHeaders         = "headers"
+	KeyQueryParameters = "queryParameters"
+	KeySettings        = "settings"
+	KeyType            = "type"
+)
+
+// SecuritySchemeOptions configures `SecurityScheme()` for a RAML version.
+type SecuritySchemeOptions struct {
+	// Grants maps RAML `authorizationGrants` to OpenAPI 3 OAuth flow names.
+	Grants map[string]string
+	// GrantDefault is the grant used when none are declared.
+	GrantDefault string
+	// Issue is called for constructs which cannot be represented. It may be
+	// nil.
+	Issue func(message string)
+}
+
+func (opts SecuritySchemeOptions) issue(message string) {
+	if opts.Issue != nil {
+		opts.Issue(message)
+	}
+}
+
+// SecurityScheme converts a RAML security scheme to an OpenAPI 3 security
+// scheme. `Pass Through` and custom `x-` schemes are converted to API keys
+// when `describedBy` declares exactly one header or query parameter. It
+// returns `nil` if the scheme cannot be represented.
+func SecurityScheme(schemeMap map[string]any, opts SecuritySchemeOptions) *oas3.SecurityScheme {
+	ramlType, _ := schemeMap[KeyType].(string)
+	desc, _ := schemeMap[KeyDescription].(string)
+	scheme := oas3.NewSecurityScheme()
+	scheme.Description = strings.TrimSpace(desc)
+	switch strings.TrimSpace(ramlType) {
+	case SecurityTypeOAuth2:
+		settings, _ := schemeMap[KeySettings].(map[string]any)
+		scheme.Type = "oauth2"
+		scheme.Flows = oauth2Flows(settings, opts)
+	case SecurityTypeOAuth1:
+		opts.issue("OAuth 1.0 is not supported by OpenAPI 3 and is mapped to http scheme `oauth`")
+		scheme.Type = "http"
+		scheme.Scheme = "oauth"
+	case SecurityTypeBasic:
+		scheme.Type = "http"
+		scheme.Scheme = "basic"
+	case SecurityTypeDigest:
+		scheme.Type = "http"
+		scheme.Scheme = "digest"
+	default:
+		describedBy, _ := schemeMap[KeyDescribedBy].(map[string]any)
+		headers, _ := describedBy[KeyHeaders].(map[string]any)
+		queryParams, _ := describedBy[KeyQueryParameters].(map[string]any)
+		if len(headers) == 1 && len(queryParams) == 0 {
+			scheme.Type = "apiKey"
+			scheme.In = openapi3.InHeader
+			for k := range headers {
+				scheme.Name = strings.TrimSuffix(k, "?")
+			}
+		} else if len(queryParams) == 1 && len(headers) == 0 {
+			scheme.Type = "apiKey"
+			scheme.In = openapi3.InQuery
+			for k := range queryParams {
+				scheme.Name = strings.TrimSuffix(k, "?")
+			}
+		} else {
+			opts.issue(fmt.Sprintf("security scheme type `%s` cannot be represented in OpenAPI 3", ramlType))
+			return nil
+		}
+	}
+	return scheme
+}
+
+func oauth2Flows(settings map[string]any, opts SecuritySchemeOptions) *oas3.OAuthFlows {
+	authURL, _ := settings["authorizationUri"].(string)
+	tokenURL, _ := settings["accessTokenUri"].(string)
+	scopes := map[string]string{}
+	if scopesAny, ok := settings["scopes"].([]any); ok {
+		for _, s := range scopesAny {
+			scopes[fmt.Sprintf("%v", s)] = ""
+		}
+	}
+	grants := []string{}
+	if grantsAny, ok := settings["authorizationGrants"].([]any); ok {
+		for _, g := range grantsAny {
+			grants = append(grants, strings.TrimSpace(fmt.Sprintf("%v", g)))
+		}
+	}
+	if len(grants) == 0 {
+		grants = append(grants, opts.GrantDefault)
+	}
+	flows := &oas3.OAuthFlows{}
+	for _, grant := range grants {
+		flow := &oas3.OAuthFlow{Scopes: scopes}
+		switch opts.Grants[grant] {
+		case "authorizationCode":
+			flow.AuthorizationURL = authURL
+			flow.TokenURL = tokenURL
+			flows.AuthorizationCode = flow
+		case "implicit":
+			flow.AuthorizationURL = authURL
+			flows.Implicit = flow
+		case "password":
+			flow.TokenURL = tokenURL
+			flows.Password = flow
+		case "clientCredentials":
+			flow.TokenURL = tokenURL
+			flows.ClientCredentials = flow
+		default:
+			opts.issue(fmt.Sprintf("oauth 2.0 grant `%s` cannot be represented in OpenAPI 3", grant))
+		}
+	}
+	return flows
+}
+
+// SecurityRequirements converts a RAML `securedBy` list. A `null` entry is
+// anonymous access which is an empty requirement in OpenAPI 3. Entries naming
+// a scheme which is not in `schemes`, such as a scheme that could not be
+// converted, are dropped. It returns `nil` if all entries are dropped.
+func SecurityRequirements(val any, schemes oas3.SecuritySchemes) (oas3.SecurityRequirements, error) {
+	secReqs := oas3.SecurityRequirements{}
+	items, ok := val.([]any)
+	if !ok {
+		items = []any{val}
+	}
+	for _, item := range items {
+		switch v := item.(type) {
+		case nil:
+			secReqs = append(secReqs, oas3.SecurityRequirement{})
+		case string:
+			if _, ok := schemes[strings.TrimSpace(v)]; ok {
+				secReqs = append(secReqs, oas3.SecurityRequirement{strings.TrimSpace(v): []string{}})
+			}
+		case map[string]any:
+			secReq := oas3.SecurityRequirement{}
+			for name, paramsAny := range v {
+				if _, ok := schemes[strings.TrimSpace(name)]; !ok {
+					secReq = nil
+					break
+				}
+				scopes := []string{}
+				if params, ok := paramsAny.(map[string]any); ok {
+					if scopesAny, ok := params["scopes"].([]any); ok {
+						for _, s := range scopesAny {
+							scopes = append(scopes, fmt.Sprintf("%v", s))
+						}
+					}
+				}
+				sort.Strings(scopes)
+				secReq[strings.TrimSpace(name)] = scopes
+			}
+			if secReq != nil {
+				secReqs = append(secReqs, secReq)
+			}
+		default:
+			return secReqs, fmt.Errorf("raml securedBy item not supported (%v)", item)
+		}
+	}
+	if len(secReqs) == 0 {
+		return nil, nil
+	}
+	return secReqs, nil
+}
@@ -0,0 +1,286 @@
+package ramlutil
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grokify/mogo/text/stringcase"
+)
+
+const KeyIs = "is"
+
+var rxTemplateParam = regexp.MustCompile(`<<\s*([^<>|\s]+)\s*((?:\|\s*![a-z]+\s*)*)>>`)
+
+var rxTemplateFunc = regexp.MustCompile(`!([a-z]+)`)
+
+// NamedTemplates converts RAML `resourceTypes` and `traits` which are
+// sequences of single key maps into a single map.
+func NamedTemplates(val any) (map[string]map[string]any, error) {
+	tmpls := map[string]map[string]any{}
+	if val == nil {
+		return tmpls, nil
+	}
+	addMap := func(msa map[string]any) error {
+		for name, tmplAny := range msa {
+			if tmplAny == nil {
+				tmpls[name] = map[string]any{}
+				continue
+			}
+			tmpl, ok := tmplAny.(map[string]any)
+			if !ok {
+				return fmt.Errorf("raml template is not a map (%s)", name)
+			}
+			tmpls[name] = tmpl
+		}
+		return nil
+	}
+	switch v := val.(type) {
+	case []any:
+		for _, item := range v {
+			msa, ok := item.(map[string]any)
+			if !ok {
+				return tmpls, fmt.Errorf("raml template list item is not a map (%v)", item)
+			}
+			if err := addMap(msa); err != nil {
+				return tmpls, err
+			}
+		}
+	case map[string]any:
+		if err := addMap(v); err != nil {
+			return tmpls, err
+		}
+	default:
+		return tmpls, fmt.Errorf("raml templates is not a list or map (%v)", val)
+	}
+	return tmpls, nil
+}
+
+// NamedTemplatesAny is like `NamedTemplates` but allows non-map values such
+// as schema and type expression strings.
+func NamedTemplatesAny(val any) (map[string]any, error) {
+	out := map[string]any{}
+	switch v := val.(type) {
+	case nil:
+	case []any:
+		for _, item := range v {
+			msa, ok := item.(map[string]any)
+			if !ok {
+				return out, fmt.Errorf("raml list item is not a map (%v)", item)
+			}
+			for k, child := range msa {
+				out[k] = child
+			}
+		}
+	case map[string]any:
+		for k, child := range v {
+			out[k] = child
+		}
+	default:
+		return out, fmt.Errorf("raml value is not a list or map (%v)", val)
+	}
+	return out, nil
+}
+
+// TemplateRefs parses a `type` value or `is` list into template names and
+// parameters. A reference is either a string name or a single key map of name
+// to parameters.
+func TemplateRefs(val any) ([]string, []map[string]any, error) {
+	names := []string{}
+	params := []map[string]any{}
+	add := func(item any) error {
+		switch v := item.(type) {
+		case string:
+			names = append(names, strings.TrimSpace(v))
+			params = append(params, map[string]any{})
+		case map[string]any:
+			for name, pAny := range v {
+				p, ok := pAny.(map[string]any)
+				if !ok && pAny != nil {
+					return fmt.Errorf("raml template parameters are not a map (%s)", name)
+				} else if p == nil {
+					p = map[string]any{}
+				}
+				names = append(names, strings.TrimSpace(name))
+				params = append(params, p)
+			}
+		case nil:
+		default:
+			return fmt.Errorf("raml template reference not supported (%v)", item)
+		}
+		return nil
+	}
+	if items, ok := val.([]any); ok {
+		for _, item := range items {
+			if err := add(item); err != nil {
+				return names, params, err
+			}
+		}
+	} else if err := add(val); err != nil {
+		return names, params, err
+	}
+	return names, params, nil
+}
+
+// TemplateApply substitutes `<<param>>` placeholders in all keys and string
+// values of a deep copy of the template.
+func TemplateApply(tmpl any, params map[string]any) any {
+	switch v := tmpl.(type) {
+	case map[string]any:
+		out := map[string]any{}
+		for k, child := range v {
+			out[templateSubstitute(k, params)] = TemplateApply(child, params)
+		}
+		return out
+	case []any:
+		out := []any{}
+		for _, child := range v {
+			out = append(out, TemplateApply(child, params))
+		}
+		return out
+	case string:
+		return templateSubstitute(v, params)
+	default:
+		return v
+	}
+}
+
+func templateSubstitute(s string, params map[string]any) string {
+	return rxTemplateParam.ReplaceAllStringFunc(s, func(m string) string {
+		sm := rxTemplateParam.FindStringSubmatch(m)
+		pAny, ok := params[sm[1]]
+		if !ok {
+			return m
+		}
+		p := fmt.Sprintf("%v", pAny)
+		for _, fm := range rxTemplateFunc.FindAllStringSubmatch(sm[2], -1) {
+			p = templateFunc(fm[1], p)
+		}
+		return p
+	})
+}
+
+// templateFunc applies a RAML template function such as `!singularize`
+// or `!uppercamelcase`. Unknown functions return the value unchanged.
+func templateFunc(name, val string) string {
+	switch name {
+	case "singularize":
+		return Singularize(val)
+	case "pluralize":
+		return Pluralize(val)
+	case "uppercase":
+		return strings.ToUpper(val)
+	case "lowercase":
+		return strings.ToLower(val)
+	case "lowercamelcase":
+		return stringcase.ToCamelCase(val)
+	case "uppercamelcase":
+		return stringcase.ToPascalCase(val)
+	case "lowerunderscorecase":
+		return stringcase.ToSnakeCase(val)
+	case "upperunderscorecase":
+		return strings.ToUpper(stringcase.ToSnakeCase(val))
+	case "lowerhyphencase":
+		return stringcase.ToKebabCase(val)
+	case "upperhyphencase":
+		return strings.ToUpper(stringcase.ToKebabCase(val))
+	}
+	return val
+}
+
+// TemplateMerge merges a template into a resource or method. Values already
+// present in `dst` take precedence. Keys ending in `?` are optional and are only
+// merged when `dst` already has the corresponding key.
+func TemplateMerge(dst, tmpl map[string]any) {
+	for k, tmplVal := range tmpl {
+		optional := strings.HasSuffix(k, "?")
+		key := strings.TrimSuffix(k, "?")
+		dstVal, ok := dst[key]
+		if !ok {
+			if !optional {
+				dst[key] = tmplVal
+			}
+			continue
+		}
+		switch dstTyped := dstVal.(type) {
+		case map[string]any:
+			if tmplMap, ok := tmplVal.(map[string]any); ok {
+				TemplateMerge(dstTyped, tmplMap)
+			}
+		case []any:
+			if key == KeyIs {
+				if tmplSlice, ok := tmplVal.([]any); ok {
+					dst[key] = append(tmplSlice, dstTyped...)
+				}
+			}
+		case nil:
+			if tmplMap, ok := tmplVal.(map[string]any); ok {
+				dst[key] = tmplMap
+			}
+		}
+	}
+}
+
+// ResourcePathName returns the rightmost path segment that is not a URI
+// parameter, as defined for the reserved `resourcePathName` parameter.
+func ResourcePathName(resourcePath string) string {
+	parts := strings.Split(strings.Trim(resourcePath, "/"), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if len(parts[i]) > 0 && !strings.Contains(parts[i], "{") {
+			return parts[i]
+		}
+	}
+	return ""
+}
+
+// Singularize returns a simple English singular form as used by
+// the RAML `!singularize` template function.
+func Singularize(s string) string {
+	lc := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lc, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(lc, "sses"), strings.HasSuffix(lc, "xes"),
+		strings.HasSuffix(lc, "ches"), strings.HasSuffix(lc, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(lc, "ss"):
+		return s
+	case strings.HasSuffix(lc, "s") && len(s) > 1:
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// Pluralize returns a simple English plural form as used by
+// the RAML `!pluralize` template function.
+func Pluralize(s string) string {
+	lc := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lc, "y") && len(s) > 1 && !strings.ContainsAny(lc[len(lc)-2:len(lc)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(lc, "s"), strings.HasSuffix(lc, "x"),
+		strings.HasSuffix(lc, "ch"), strings.HasSuffix(lc, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
+
+// DeepCopy returns a deep copy of generic maps and slices so templates can
+// be applied more than once.
+func DeepCopy(val any) any {
+	switch v := val.(type) {
+	case map[string]any:
+		out := map[string]any{}
+		for k, child := range v {
+			out[k] = DeepCopy(child)
+		}
+		return out
+	case []any:
+		out := []any{}
+		for _, child := range v {
+			out = append(out, DeepCopy(child))
+		}
+		return out
+	}
+	return val
+}
@@ -0,0 +1,413 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/mogo/encoding/jsonpointer"
+	"github.com/grokify/mogo/type/maputil"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	rxBundleNameInvalid = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	rxBundleURLScheme   = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+)
+
+// BundleFile reads a root spec and follows relative external `$ref`s to
+// local JSON and YAML files, returning one self-contained spec. Referenced
+// content is added to `components` by the kind of the referencing location,
+// e.g. `schemas` for a property and `parameters` for an operation parameter.
+// Component names are the last segment of `#/components/{kind}/{name}`
+// style fragments or the file base name otherwise, with a numeric suffix
+// added when a name is already used by different content. Path items, which
+// have no component kind in OpenAPI 3.0, are inlined. Root components which
+// reference a file are replaced by the file content. References with a URL
+// scheme such as `https:` are left unchanged.
+func BundleFile(filename string) (*Spec, error) {
+	b := &bundler{
+		files:      map[string]any{},
+		names:      map[string]map[string]string{},
+		components: map[string]map[string]any{},
+		rootFiles:  map[string]string{},
+		inlining:   map[string]bool{}}
+	rootFile, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	b.rootFile = rootFile
+	root, err := b.file(rootFile)
+	if err != nil {
+		return nil, err
+	}
+	rootMap, ok := root.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("spec is not an object [%s]", filename)
+	}
+	// Existing root components keep their names, including components that
+	// reference a file, as written by `SpecMore.Split()`.
+	if comps, ok := rootMap[PathComponents].(map[string]any); ok {
+		for _, kind := range maputil.Keys(comps) {
+			items, ok := comps[kind].(map[string]any)
+			if !ok {
+				continue
+			}
+			for _, name := range maputil.Keys(items) {
+				item := items[name]
+				target := b.target(rootFile, "/"+PathComponents+"/"+jsonpointer.PropertyNameEscape(kind)+"/"+jsonpointer.PropertyNameEscape(name))
+				if itemMap, ok := item.(map[string]any); ok {
+					if ref, ok := itemMap["$ref"].(string); ok && !strings.HasPrefix(ref, "#") && !rxBundleURLScheme.MatchString(ref) {
+						refFile, fragment, err := bundleRefFile(ref, rootFile)
+						if err != nil {
+							return nil, err
+						}
+						target = b.target(refFile, fragment)
+						if fragment == "" {
+							b.rootFiles[refFile] = "/" + PathComponents + "/" + jsonpointer.PropertyNameEscape(kind) + "/" + jsonpointer.PropertyNameEscape(name)
+						}
+					}
+				}
+				b.reserve(kind, name, target)
+			}
+		}
+	}
+	out, err := b.walk(copyJSONValue(rootMap), rootFile, []string{})
+	if err != nil {
+		return nil, err
+	}
+	outMap := out.(map[string]any)
+	if len(b.components) > 0 {
+		comps, ok := outMap[PathComponents].(map[string]any)
+		if !ok {
+			comps = map[string]any{}
+			outMap[PathComponents] = comps
+		}
+		for kind, items := range b.components {
+			kindMap, ok := comps[kind].(map[string]any)
+			if !ok {
+				kindMap = map[string]any{}
+				comps[kind] = kindMap
+			}
+			for name, item := range items {
+				kindMap[name] = item
+			}
+		}
+	}
+	bytes, err := json.Marshal(outMap)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(bytes)
+}
+
+type bundler struct {
+	rootFile string
+	files    map[string]any
+	// names maps component kind to target to name.
+	names map[string]map[string]string
+	// components holds bundled components by kind and name.
+	components map[string]map[string]any
+	// rootFiles maps files referenced by root components to the component
+	// pointer.
+	rootFiles map[string]string
+	inlining  map[string]bool
+}
+
+// file returns the parsed content of a JSON or YAML file.
+func (b *bundler) file(filename string) (any, error) {
+	if doc, ok := b.files[filename]; ok {
+		return doc, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	// YAML is a superset of JSON so both are read as YAML.
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("file [%s]: %w", filename, err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("file [%s]: %w", filename, err)
+	}
+	b.files[filename] = doc
+	return doc, nil
+}
+
+func (b *bundler) target(filename, pointer string) string {
+	return filename + "#" + pointer
+}
+
+func (b *bundler) reserve(kind, name, target string) {
+	if _, ok := b.names[kind]; !ok {
+		b.names[kind] = map[string]string{}
+	}
+	b.names[kind][target] = name
+}
+
+// name returns the component name for a target, adding a numeric suffix
+// when the name is used by a different target.
+func (b *bundler) name(kind, target, base string) (string, bool) {
+	if name, ok := b.names[kind][target]; ok {
+		return name, false
+	}
+	used := map[string]bool{}
+	for _, name := range b.names[kind] {
+		used[name] = true
+	}
+	name := base
+	for i := 2; used[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	b.reserve(kind, name, target)
+	return name, true
+}
+
+// walk rewrites references in a value in place. `filename` is the file the
+// value is read from and `path` is its location in the bundled spec, used to
+// determine the component kind of references.
+func (b *bundler) walk(val any, filename string, path []string) (any, error) {
+	switch v := val.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			return b.ref(v, ref, filename, path)
+		}
+		// Keys are walked in order so colliding names are numbered the same
+		// way on every run.
+		for _, k := range maputil.Keys(v) {
+			if isBundleValueKey(path, k) {
+				continue
+			}
+			out, err := b.walk(v[k], filename, append(slices.Clone(path), k))
+			if err != nil {
+				return nil, err
+			}
+			v[k] = out
+		}
+		return v, nil
+	case []any:
+		for i, item := range v {
+			out, err := b.walk(item, filename, append(slices.Clone(path), strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = out
+		}
+		return v, nil
+	default:
+		return val, nil
+	}
+}
+
+// ref resolves one reference object.
+func (b *bundler) ref(obj map[string]any, ref, filename string, path []string) (any, error) {
+	if rxBundleURLScheme.MatchString(ref) {
+		return obj, nil
+	}
+	refFile, fragment, err := bundleRefFile(ref, filename)
+	if err != nil {
+		return nil, err
+	} else if refFile == b.rootFile {
+		obj["$ref"] = "#" + fragment
+		return obj, nil
+	}
+	target := b.target(refFile, fragment)
+	if compPointer, ok := b.rootFiles[refFile]; ok && fragment != "" && fragment != "/" {
+		// Pointers into a file of a root component point into the component.
+		obj["$ref"] = "#" + compPointer + "/" + strings.TrimPrefix(fragment, "/")
+		return obj, nil
+	}
+	kind := bundleRefKind(path)
+	if kind == "" {
+		// Content without a component kind is inlined.
+		if b.inlining[target] {
+			return nil, fmt.Errorf("circular ref [%s]", target)
+		}
+		b.inlining[target] = true
+		defer delete(b.inlining, target)
+		content, err := b.resolve(refFile, fragment)
+		if err != nil {
+			return nil, err
+		}
+		return b.walk(copyJSONValue(content), refFile, path)
+	}
+	name, isNew := b.name(kind, target, bundleComponentName(refFile, fragment, kind))
+	if len(path) == 3 && path[0] == PathComponents && path[2] == name {
+		// A root component referencing a file is replaced by the file content.
+		content, err := b.resolve(refFile, fragment)
+		if err != nil {
+			return nil, err
+		}
+		return b.walk(copyJSONValue(content), refFile, path)
+	}
+	obj["$ref"] = "#/" + PathComponents + "/" + kind + "/" + jsonpointer.PropertyNameEscape(name)
+	if !isNew {
+		return obj, nil
+	}
+	content, err := b.resolve(refFile, fragment)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := b.components[kind]; !ok {
+		b.components[kind] = map[string]any{}
+	}
+	// Register before walking so circular references resolve to the name.
+	b.components[kind][name] = nil
+	out, err := b.walk(copyJSONValue(content), refFile, []string{PathComponents, kind, name})
+	if err != nil {
+		return nil, err
+	}
+	b.components[kind][name] = out
+	return obj, nil
+}
+
+// bundleRefFile returns the absolute file and fragment of a reference
+// relative to the file containing it.
+func bundleRefFile(ref, filename string) (string, string, error) {
+	refFile, fragment, _ := strings.Cut(ref, "#")
+	if refFile == "" {
+		return filename, fragment, nil
+	}
+	unescaped, err := url.PathUnescape(refFile)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid ref [%s]: %w", ref, err)
+	}
+	return filepath.Join(filepath.Dir(filename), filepath.FromSlash(unescaped)), fragment, nil
+}
+
+// resolve returns the value at a JSON Pointer fragment in a file.
+func (b *bundler) resolve(filename, fragment string) (any, error) {
+	doc, err := b.file(filename)
+	if err != nil {
+		return nil, err
+	}
+	fragment, err = url.PathUnescape(fragment)
+	if err != nil {
+		return nil, err
+	}
+	if fragment == "" || fragment == "/" {
+		return doc, nil
+	}
+	cur := doc
+	for _, part := range strings.Split(strings.TrimPrefix(fragment, "/"), "/") {
+		part = jsonpointer.PropertyNameUnescape(part)
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[part]
+			if !ok {
+				return nil, fmt.Errorf("ref not found [%s#%s]", filename, fragment)
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("ref not found [%s#%s]", filename, fragment)
+			}
+			cur = v[i]
+		default:
+			return nil, fmt.Errorf("ref not found [%s#%s]", filename, fragment)
+		}
+	}
+	return cur, nil
+}
+
+// bundleComponentName returns the name for bundled content, using the
+// last fragment segment or the file base name.
+func bundleComponentName(filename, fragment, kind string) string {
+	name := ""
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		segs := strings.Split(strings.Trim(unescaped, "/"), "/")
+		name = jsonpointer.PropertyNameUnescape(segs[len(segs)-1])
+	}
+	if name == "" {
+		base := filepath.Base(filename)
+		name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	name = rxBundleNameInvalid.ReplaceAllString(name, "_")
+	if name == "" {
+		name = kind
+	}
+	return name
+}
+
+// bundleRefKind returns the component kind for a reference at a location,
+// or an empty string when the content is to be inlined.
+func bundleRefKind(path []string) string {
+	n := len(path)
+	if n == 0 {
+		return ""
+	}
+	last := path[n-1]
+	parent := ""
+	if n > 1 {
+		parent = path[n-2]
+	}
+	switch {
+	case n == 3 && path[0] == PathComponents:
+		return path[1]
+	case n == 2 && path[0] == "paths":
+		return ""
+	case parent == "properties",
+		parent == "allOf", parent == "anyOf", parent == "oneOf",
+		last == "schema", last == "items", last == "not", last == "additionalProperties":
+		return PathSchemas
+	case parent == PathParameters:
+		return PathParameters
+	case parent == "responses":
+		return "responses"
+	case last == "requestBody":
+		return "requestBodies"
+	case parent == "headers":
+		return "headers"
+	case parent == "examples":
+		return "examples"
+	case parent == "links":
+		return "links"
+	case parent == "callbacks":
+		return "callbacks"
+	}
+	return ""
+}
+
+// isBundleValueKey returns true for keys holding instance values, such as
+// `example`, which are not searched for references.
+func isBundleValueKey(path []string, key string) bool {
+	n := len(path)
+	if n > 0 && path[n-1] == "properties" {
+		return false
+	}
+	switch key {
+	case "example", "default", "enum":
+		return true
+	case "value":
+		return n > 1 && path[n-2] == "examples"
+	}
+	return false
+}
+
+func copyJSONValue(val any) any {
+	switch v := val.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = copyJSONValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = copyJSONValue(item)
+		}
+		return out
+	default:
+		return val
+	}
+}
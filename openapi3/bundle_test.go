@@ -0,0 +1,189 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var bundleTestFiles = map[string]string{
+	"openapi.yaml": `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    $ref: paths/pets.yaml
+  /owners:
+    get:
+      parameters:
+        - $ref: "common.yaml#/components/parameters/Limit"
+      responses:
+        "200":
+          description: Owners
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Owner"
+components:
+  schemas:
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+    Pet:
+      type: object
+      properties:
+        local:
+          type: string
+`,
+	"paths/pets.yaml": `get:
+  parameters:
+    - $ref: "../common.yaml#/components/parameters/Limit"
+  responses:
+    "200":
+      description: Pets
+      content:
+        application/json:
+          schema:
+            $ref: ../schemas/pet.json
+`,
+	"common.yaml": `components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema:
+        type: integer
+`,
+	"schemas/pet.json": `{
+  "type": "object",
+  "properties": {
+    "owner": {"$ref": "../openapi.yaml#/components/schemas/Owner"},
+    "parent": {"$ref": "pet.json"},
+    "tag": {"$ref": "#/definitions/Tag"}
+  },
+  "definitions": {
+    "Tag": {"type": "string"}
+  }
+}`,
+}
+
+func TestBundleFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range bundleTestFiles {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	spec, err := BundleFile(filepath.Join(dir, "openapi.yaml"))
+	if err != nil {
+		t.Fatalf("openapi3.BundleFile() error [%v]", err)
+	}
+	pets := spec.Paths.Find("/pets")
+	if pets == nil || pets.Get == nil {
+		t.Fatalf("openapi3.BundleFile() path item not inlined")
+	}
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{pets.Get.Parameters[0].Ref, "#/components/parameters/Limit"},
+		{spec.Paths.Find("/owners").Get.Parameters[0].Ref, "#/components/parameters/Limit"},
+		// `pet` collides with the root `Pet` only by case so keeps its name.
+		{pets.Get.Responses.Value("200").Value.Content["application/json"].Schema.Ref, "#/components/schemas/pet"},
+		{spec.Components.Schemas["pet"].Value.Properties["owner"].Ref, "#/components/schemas/Owner"},
+		{spec.Components.Schemas["pet"].Value.Properties["parent"].Ref, "#/components/schemas/pet"},
+		{spec.Components.Schemas["pet"].Value.Properties["tag"].Ref, "#/components/schemas/Tag"},
+		{spec.Components.Schemas["Tag"].Value.Type, TypeString},
+		{spec.Components.Parameters["Limit"].Value.Name, "limit"},
+		{spec.Components.Schemas["Pet"].Value.Properties["local"].Value.Type, TypeString},
+	}
+	for i, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("openapi3.BundleFile() mismatch (%d): want [%s], got [%s]", i, tt.want, tt.got)
+		}
+	}
+}
+
+func TestBundleComponentNameCollision(t *testing.T) {
+	b := &bundler{names: map[string]map[string]string{}}
+	b.reserve(PathSchemas, "Pet", "/root.yaml#/components/schemas/Pet")
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/a.yaml#/Pet", "Pet2"},
+		{"/b.yaml#/Pet", "Pet3"},
+		{"/a.yaml#/Pet", "Pet2"},
+		{"/root.yaml#/components/schemas/Pet", "Pet"},
+	}
+	for _, tt := range tests {
+		if got, _ := b.name(PathSchemas, tt.target, "Pet"); got != tt.want {
+			t.Errorf("bundler.name() mismatch: want [%s], got [%s]", tt.want, got)
+		}
+	}
+}
+
+var bundleStableTestFiles = map[string]string{
+	"openapi.yaml": `openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /cats:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: "a/Pet.yaml"}
+  /dogs:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: "b/Pet.yaml"}
+`,
+	"a/Pet.yaml": `{type: object, properties: {lives: {type: integer}}}`,
+	"b/Pet.yaml": `{type: object, properties: {barks: {type: boolean}}}`,
+}
+
+func TestBundleFileStable(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range bundleStableTestFiles {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := ""
+	for i := 0; i < 10; i++ {
+		spec, err := BundleFile(filepath.Join(dir, "openapi.yaml"))
+		if err != nil {
+			t.Fatalf("openapi3.BundleFile() error [%v]", err)
+		}
+		bytes, err := json.Marshal(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			want = string(bytes)
+			if ref := spec.Paths.Find("/cats").Get.Responses.Value("200").Value.Content["application/json"].Schema.Ref; ref != "#/components/schemas/Pet" {
+				t.Errorf("openapi3.BundleFile() ref mismatch: want [%s], got [%s]", "#/components/schemas/Pet", ref)
+			}
+		} else if string(bytes) != want {
+			t.Fatalf("openapi3.BundleFile() output differs between runs:\n%s\n%s", want, string(bytes))
+		}
+	}
+}
@@ -0,0 +1,469 @@
+package openapi3
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// XOrder sets the position of a path item when paths are ordered with
+	// `CanonicalPathsXOrder`.
+	XOrder = "x-order"
+
+	CanonicalPathsSorted = "sorted"
+	CanonicalPathsXOrder = "x-order"
+
+	CanonicalPropertiesAlphabetical = "alphabetical"
+	CanonicalPropertiesSource       = "source"
+)
+
+// CanonicalOptions configures canonical formatting. Keys of the document,
+// info, path items, operations, parameters, responses, tags, components and
+// schemas are in OpenAPI specification order, HTTP methods are in the order
+// `get`, `put`, `post`, `delete`, `options`, `head`, `patch` and `trace`, and
+// `required` lists are sorted. Other keys are sorted with extensions last.
+// Instance values such as `example`, `default` and `enum` and extension
+// values are unchanged.
+type CanonicalOptions struct {
+	// PathOrder is `sorted`, the default, or `x-order` to order path items
+	// by their numeric `x-order` extension, followed by path items without
+	// one in sorted order.
+	PathOrder string
+	// PropertyOrder is `alphabetical`, the default, or `source` to keep the
+	// order of schema properties in the input.
+	PropertyOrder string
+}
+
+var (
+	canonicalKeysRoot = []string{"openapi", "info", "jsonSchemaDialect", "servers", "paths", "webhooks", PathComponents, "security", PropertyTags, "externalDocs"}
+	canonicalKeysInfo = []string{"title", PropertySummary, "description", "termsOfService", "contact", "license", "version"}
+	canonicalKeysPath = []string{"$ref", PropertySummary, "description",
+		"get", "put", "post", "delete", "options", "head", "patch", "trace", "servers", PathParameters}
+	canonicalKeysOperation = []string{PropertyTags, PropertySummary, "description", "externalDocs", PropertyOperationID,
+		PathParameters, "requestBody", "responses", "callbacks", "deprecated", "security", "servers"}
+	canonicalKeysParameter = []string{"$ref", "name", "in", "description", "required", "deprecated", "allowEmptyValue",
+		"style", "explode", "allowReserved", "schema", "example", "examples", "content"}
+	canonicalKeysResponse   = []string{"$ref", "description", "headers", "content", "links"}
+	canonicalKeysTag        = []string{"name", "description", "externalDocs"}
+	canonicalKeysComponents = []string{PathSchemas, "responses", PathParameters, "examples", "requestBodies", "headers",
+		PathSecuritySchemes, "links", "callbacks", "pathItems"}
+	canonicalKeysSchema = []string{"$ref", "title", "description", "type", "format", "nullable", "enum", "const", "default",
+		"multipleOf", "minimum", "exclusiveMinimum", "maximum", "exclusiveMaximum", "minLength", "maxLength", "pattern",
+		"items", "minItems", "maxItems", "uniqueItems", "required", "properties", "additionalProperties",
+		"minProperties", "maxProperties", "allOf", "oneOf", "anyOf", "not", "discriminator",
+		"readOnly", "writeOnly", "xml", "externalDocs", "example", "examples", "deprecated"}
+)
+
+// canonical contexts of a node, which set its key order and the contexts of
+// its children.
+const (
+	canonicalDefault = iota
+	canonicalRoot
+	canonicalInfo
+	canonicalPaths
+	canonicalPathItem
+	canonicalOperation
+	canonicalParameters
+	canonicalParameter
+	canonicalResponses
+	canonicalResponse
+	canonicalContent
+	canonicalTags
+	canonicalComponents
+	canonicalComponentParameters
+	canonicalComponentResponses
+	canonicalSchemas
+	canonicalSchemaList
+	canonicalSchema
+	canonicalProperties
+	canonicalValue
+)
+
+// FormatCanonical formats a JSON or YAML spec document canonically and
+// returns it as indented JSON or as YAML. YAML comments are kept.
+func FormatCanonical(data []byte, opts *CanonicalOptions, jsonOutput bool) ([]byte, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	node := doc
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		node = doc.Content[0]
+	}
+	c := canonicalizer{}
+	if opts != nil {
+		c.opts = *opts
+	}
+	c.node(node, canonicalRoot)
+	if jsonOutput {
+		buf := &bytes.Buffer{}
+		if err := canonicalWriteJSON(buf, node, ""); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\n")
+		return buf.Bytes(), nil
+	}
+	canonicalResetStyle(node)
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalCanonical returns the spec formatted by `FormatCanonical()`. As
+// the spec does not keep the source order of schema properties, they are
+// alphabetical.
+func (sm *SpecMore) MarshalCanonical(opts *CanonicalOptions, jsonOutput bool) ([]byte, error) {
+	if sm.Spec == nil {
+		return nil, ErrSpecNotSet
+	}
+	bytes, err := sm.Spec.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return FormatCanonical(bytes, opts, jsonOutput)
+}
+
+var rxCanonicalJSONFile = regexp.MustCompile(`(?i)\.json$`)
+
+// WriteFileCanonical writes the spec formatted by `MarshalCanonical()`, as
+// JSON for `.json` files and YAML otherwise.
+func (sm *SpecMore) WriteFileCanonical(filename string, perm os.FileMode, opts *CanonicalOptions) error {
+	bytes, err := sm.MarshalCanonical(opts, rxCanonicalJSONFile.MatchString(filename))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, bytes, perm)
+}
+
+type canonicalizer struct {
+	opts CanonicalOptions
+}
+
+func (c *canonicalizer) node(n *yaml.Node, ctx int) {
+	if ctx == canonicalValue {
+		return
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		c.sortMapping(n, ctx)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			c.node(n.Content[i+1], canonicalChild(ctx, n.Content[i].Value))
+		}
+	case yaml.SequenceNode:
+		elemCtx := canonicalDefault
+		switch ctx {
+		case canonicalParameters:
+			elemCtx = canonicalParameter
+		case canonicalTags:
+			elemCtx = canonicalTags
+		case canonicalSchemaList:
+			elemCtx = canonicalSchema
+		}
+		for _, item := range n.Content {
+			c.node(item, elemCtx)
+		}
+	}
+}
+
+// canonicalChild returns the context of the value of `key` in a mapping.
+func canonicalChild(ctx int, key string) int {
+	if strings.HasPrefix(key, "x-") && ctx != canonicalProperties {
+		return canonicalValue
+	}
+	switch ctx {
+	case canonicalRoot:
+		switch key {
+		case "info":
+			return canonicalInfo
+		case "paths", "webhooks":
+			return canonicalPaths
+		case PathComponents:
+			return canonicalComponents
+		case PropertyTags:
+			return canonicalTags
+		}
+	case canonicalPaths:
+		return canonicalPathItem
+	case canonicalPathItem:
+		if key == PathParameters {
+			return canonicalParameters
+		} else if isCanonicalMethod(key) {
+			return canonicalOperation
+		}
+	case canonicalOperation:
+		switch key {
+		case PathParameters:
+			return canonicalParameters
+		case "responses":
+			return canonicalResponses
+		}
+	case canonicalResponses, canonicalComponentResponses:
+		return canonicalResponse
+	case canonicalComponentParameters:
+		return canonicalParameter
+	case canonicalComponents:
+		switch key {
+		case PathSchemas:
+			return canonicalSchemas
+		case PathParameters:
+			return canonicalComponentParameters
+		case "responses":
+			return canonicalComponentResponses
+		}
+	case canonicalSchemas, canonicalProperties:
+		return canonicalSchema
+	case canonicalSchema:
+		switch key {
+		case "properties":
+			return canonicalProperties
+		case "items", "additionalProperties", "not":
+			return canonicalSchema
+		case "allOf", "anyOf", "oneOf":
+			return canonicalSchemaList
+		case "example", "examples", "default", "enum", "const":
+			return canonicalValue
+		}
+		return canonicalDefault
+	case canonicalContent:
+		return canonicalDefault
+	}
+	switch key {
+	case "schema":
+		return canonicalSchema
+	case "content":
+		return canonicalContent
+	case "example", "examples", "default", "enum", "value":
+		return canonicalValue
+	}
+	return canonicalDefault
+}
+
+func isCanonicalMethod(key string) bool {
+	switch key {
+	case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+		return true
+	}
+	return false
+}
+
+func canonicalKeys(ctx int) []string {
+	switch ctx {
+	case canonicalRoot:
+		return canonicalKeysRoot
+	case canonicalInfo:
+		return canonicalKeysInfo
+	case canonicalPathItem:
+		return canonicalKeysPath
+	case canonicalOperation:
+		return canonicalKeysOperation
+	case canonicalParameter:
+		return canonicalKeysParameter
+	case canonicalResponse:
+		return canonicalKeysResponse
+	case canonicalTags:
+		return canonicalKeysTag
+	case canonicalComponents:
+		return canonicalKeysComponents
+	case canonicalSchema:
+		return canonicalKeysSchema
+	}
+	return nil
+}
+
+type canonicalPair struct {
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+func (c *canonicalizer) sortMapping(n *yaml.Node, ctx int) {
+	if ctx == canonicalSchema {
+		canonicalSortRequired(n)
+	}
+	if ctx == canonicalProperties && c.opts.PropertyOrder == CanonicalPropertiesSource {
+		return
+	}
+	pairs := []canonicalPair{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		pairs = append(pairs, canonicalPair{key: n.Content[i], value: n.Content[i+1]})
+	}
+	rank := map[string]int{}
+	for i, key := range canonicalKeys(ctx) {
+		rank[key] = i
+	}
+	xOrder := ctx == canonicalPaths && c.opts.PathOrder == CanonicalPathsXOrder
+	sort.SliceStable(pairs, func(i, j int) bool {
+		ki, kj := pairs[i].key.Value, pairs[j].key.Value
+		if xOrder {
+			oi, iok := canonicalXOrder(pairs[i].value)
+			oj, jok := canonicalXOrder(pairs[j].value)
+			if iok != jok {
+				return iok
+			} else if iok && oi != oj {
+				return oi < oj
+			}
+		}
+		ri, iok := rank[ki]
+		rj, jok := rank[kj]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok != jok:
+			return iok
+		}
+		xi, xj := strings.HasPrefix(ki, "x-"), strings.HasPrefix(kj, "x-")
+		if xi != xj {
+			return xj
+		}
+		return ki < kj
+	})
+	content := make([]*yaml.Node, 0, len(n.Content))
+	for _, pair := range pairs {
+		content = append(content, pair.key, pair.value)
+	}
+	n.Content = content
+}
+
+func canonicalSortRequired(n *yaml.Node) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value != "required" || n.Content[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		items := n.Content[i+1].Content
+		sort.SliceStable(items, func(a, b int) bool {
+			return items[a].Value < items[b].Value
+		})
+	}
+}
+
+func canonicalXOrder(n *yaml.Node) (float64, bool) {
+	if n.Kind != yaml.MappingNode {
+		return 0, false
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == XOrder {
+			if f, err := strconv.ParseFloat(n.Content[i+1].Value, 64); err == nil {
+				return f, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// canonicalResetStyle removes the flow style of JSON input so YAML output
+// is in block style. Quoting is kept, and plain strings which a YAML 1.1
+// reader such as `sigs.k8s.io/yaml` would not read back as the same string,
+// such as `yes` and `off`, are double quoted.
+func canonicalResetStyle(n *yaml.Node) {
+	n.Style &^= yaml.FlowStyle
+	if n.Kind == yaml.ScalarNode && n.ShortTag() == "!!str" &&
+		n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 &&
+		!canonicalPlainString(n.Value) {
+		n.Style |= yaml.DoubleQuotedStyle
+	}
+	for _, item := range n.Content {
+		canonicalResetStyle(item)
+	}
+}
+
+// canonicalPlainString reports whether a YAML 1.1 reader reads a plain
+// scalar as the same string. Multi-line strings are written in literal
+// style.
+func canonicalPlainString(s string) bool {
+	if strings.Contains(s, "\n") {
+		return true
+	}
+	j, err := sigsyaml.YAMLToJSON([]byte(s))
+	if err != nil {
+		return false
+	}
+	var val any
+	if err := json.Unmarshal(j, &val); err != nil {
+		return false
+	}
+	str, ok := val.(string)
+	return ok && str == s
+}
+
+// canonicalWriteJSON writes a node as JSON indented with two spaces,
+// keeping the order of mapping keys.
+func canonicalWriteJSON(buf *bytes.Buffer, n *yaml.Node, indent string) error {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return canonicalWriteJSON(buf, n.Alias, indent)
+	case yaml.MappingNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{")
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n" + indent + "  ")
+			if err := canonicalWriteJSONValue(buf, n.Content[i].Value); err != nil {
+				return err
+			}
+			buf.WriteString(": ")
+			if err := canonicalWriteJSON(buf, n.Content[i+1], indent+"  "); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "}")
+	case yaml.SequenceNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[")
+		for i, item := range n.Content {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n" + indent + "  ")
+			if err := canonicalWriteJSON(buf, item, indent+"  "); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "]")
+	default:
+		switch n.ShortTag() {
+		case "!!str", "!!int", "!!float", "!!bool", "!!null":
+			var val any
+			if err := n.Decode(&val); err != nil {
+				return err
+			}
+			return canonicalWriteJSONValue(buf, val)
+		}
+		// Tags without a JSON type, such as `!!timestamp`, are written as
+		// their source string.
+		return canonicalWriteJSONValue(buf, n.Value)
+	}
+	return nil
+}
+
+func canonicalWriteJSONValue(buf *bytes.Buffer, val any) error {
+	b := &bytes.Buffer{}
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(val); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimRight(b.Bytes(), "\n"))
+	return nil
+}
@@ -0,0 +1,215 @@
+package openapi3
+
+import (
+	"reflect"
+	"testing"
+)
+
+const canonicalTestSpec = `# Pets API
+
+paths:
+  /pets/{id}:
+    x-order: 2
+    delete:
+      responses:
+        "204":
+          description: Deleted
+    get:
+      responses:
+        "200":
+          description: OK
+      operationId: getPet
+  /pets:
+    x-order: 1
+    post:
+      responses:
+        "201":
+          description: Created
+components:
+  schemas:
+    Pet:
+      required: [name, id]
+      properties:
+        name: {type: string}
+        id: {type: integer, example: {z: 1, a: 2}}
+      type: object
+info: {version: "1.0.0", title: Pets}
+openapi: 3.0.3
+`
+
+const canonicalTestSorted = `# Pets API
+
+openapi: 3.0.3
+info:
+  title: Pets
+  version: "1.0.0"
+paths:
+  /pets:
+    post:
+      responses:
+        "201":
+          description: Created
+    x-order: 1
+  /pets/{id}:
+    get:
+      operationId: getPet
+      responses:
+        "200":
+          description: OK
+    delete:
+      responses:
+        "204":
+          description: Deleted
+    x-order: 2
+components:
+  schemas:
+    Pet:
+      type: object
+      required:
+        - id
+        - name
+      properties:
+        id:
+          type: integer
+          example:
+            z: 1
+            a: 2
+        name:
+          type: string
+`
+
+const canonicalTestJSON = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Pets",
+    "version": "1.0.0"
+  },
+  "paths": {
+    "/pets": {
+      "post": {
+        "responses": {
+          "201": {
+            "description": "Created"
+          }
+        }
+      },
+      "x-order": 1
+    },
+    "/pets/{id}": {
+      "get": {
+        "operationId": "getPet",
+        "responses": {
+          "200": {
+            "description": "OK"
+          }
+        }
+      },
+      "delete": {
+        "responses": {
+          "204": {
+            "description": "Deleted"
+          }
+        }
+      },
+      "x-order": 2
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": [
+          "id",
+          "name"
+        ],
+        "properties": {
+          "name": {
+            "type": "string"
+          },
+          "id": {
+            "type": "integer",
+            "example": {
+              "z": 1,
+              "a": 2
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+func TestFormatCanonical(t *testing.T) {
+	tests := []struct {
+		opts       *CanonicalOptions
+		jsonOutput bool
+		want       string
+	}{
+		{nil, false, canonicalTestSorted},
+		{&CanonicalOptions{PathOrder: CanonicalPathsSorted, PropertyOrder: CanonicalPropertiesAlphabetical}, false, canonicalTestSorted},
+		{&CanonicalOptions{PathOrder: CanonicalPathsXOrder, PropertyOrder: CanonicalPropertiesSource}, true, canonicalTestJSON},
+	}
+	for _, tt := range tests {
+		got, err := FormatCanonical([]byte(canonicalTestSpec), tt.opts, tt.jsonOutput)
+		if err != nil {
+			t.Fatalf("openapi3.FormatCanonical() error [%v]", err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("openapi3.FormatCanonical() mismatch: want [%s], got [%s]", tt.want, string(got))
+		}
+		again, err := FormatCanonical(got, tt.opts, tt.jsonOutput)
+		if err != nil {
+			t.Fatalf("openapi3.FormatCanonical() error [%v]", err)
+		}
+		if string(again) != string(got) {
+			t.Errorf("openapi3.FormatCanonical() idempotence mismatch: want [%s], got [%s]", string(got), string(again))
+		}
+	}
+}
+
+func TestFormatCanonicalRoundTrip(t *testing.T) {
+	data := `openapi: 3.0.3
+info: {title: Flags, version: 2024-01-01}
+paths: {}
+components:
+  schemas:
+    Flag:
+      type: object
+      properties:
+        "on": {type: string, enum: ["yes", "no", "on", "off"], example: "yes"}
+        plain: {type: string, enum: [yes, off], example: 2024-01-01}
+`
+	wantEnum := map[string][]any{
+		"on":    {"yes", "no", "on", "off"},
+		"plain": {"yes", "off"},
+	}
+	wantExample := map[string]any{"on": "yes", "plain": "2024-01-01"}
+	for _, jsonOutput := range []bool{false, true} {
+		out, err := FormatCanonical([]byte(data), nil, jsonOutput)
+		if err != nil {
+			t.Fatalf("openapi3.FormatCanonical() error [%v]", err)
+		}
+		spec, err := Parse(out)
+		if err != nil {
+			t.Fatalf("openapi3.Parse() error [%v]", err)
+		}
+		if spec.Info.Version != "2024-01-01" {
+			t.Errorf("openapi3.FormatCanonical() round trip mismatch: json [%v] want version [2024-01-01], got [%s]", jsonOutput, spec.Info.Version)
+		}
+		props := spec.Components.Schemas["Flag"].Value.Properties
+		for name, want := range wantEnum {
+			prop, ok := props[name]
+			if !ok {
+				t.Errorf("openapi3.FormatCanonical() round trip mismatch: json [%v] want property [%s]", jsonOutput, name)
+				continue
+			}
+			if !reflect.DeepEqual(prop.Value.Enum, want) {
+				t.Errorf("openapi3.FormatCanonical() round trip mismatch: json [%v] property [%s] want enum [%v], got [%v]", jsonOutput, name, want, prop.Value.Enum)
+			}
+			if prop.Value.Example != wantExample[name] {
+				t.Errorf("openapi3.FormatCanonical() round trip mismatch: json [%v] property [%s] want example [%v], got [%v]", jsonOutput, name, wantExample[name], prop.Value.Example)
+			}
+		}
+	}
+}
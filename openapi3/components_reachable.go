@@ -0,0 +1,171 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/grokify/mogo/encoding/jsonpointer"
+)
+
+const pointerComponentsPrefix = "#/" + PathComponents + "/"
+
+// ComponentKinds are the `components` keys of OpenAPI 3.0.
+var ComponentKinds = []string{
+	PathSchemas, "responses", PathParameters, "examples", "requestBodies",
+	"headers", PathSecuritySchemes, "links", "callbacks"}
+
+// ComponentKey identifies a component by kind, e.g. `schemas`, and name.
+type ComponentKey struct {
+	Kind string
+	Name string
+}
+
+// Pointer returns the local JSON pointer reference of the component.
+func (key ComponentKey) Pointer() string {
+	return pointerComponentsPrefix + key.Kind + "/" + jsonpointer.PropertyNameEscape(key.Name)
+}
+
+// componentRefKey returns the component referenced by a local reference
+// such as `#/components/schemas/Pet`.
+func componentRefKey(ref string) (ComponentKey, bool) {
+	if !strings.HasPrefix(ref, pointerComponentsPrefix) {
+		return ComponentKey{}, false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(ref, pointerComponentsPrefix), "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ComponentKey{}, false
+	}
+	return ComponentKey{Kind: parts[0], Name: jsonpointer.PropertyNameUnescape(parts[1])}, true
+}
+
+// componentRefs adds the components referenced by a decoded JSON value to
+// `keys`. References are `$ref`s, discriminator mappings and the scheme
+// names of security requirements.
+func componentRefs(val any, valPath []string, keys map[ComponentKey]bool) {
+	switch v := val.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			if key, ok := componentRefKey(ref); ok {
+				keys[key] = true
+			}
+		}
+		for k, item := range v {
+			if isBundleValueKey(valPath, k) {
+				continue
+			}
+			n := len(valPath)
+			switch {
+			case k == "mapping" && n > 0 && valPath[n-1] == "discriminator":
+				mapping, _ := item.(map[string]any)
+				for _, target := range mapping {
+					if s, ok := target.(string); ok {
+						if key, ok := componentRefKey(s); ok {
+							keys[key] = true
+						} else if !strings.Contains(s, "/") {
+							keys[ComponentKey{Kind: PathSchemas, Name: s}] = true
+						}
+					}
+				}
+				continue
+			case k == "security" && (n == 0 || valPath[n-1] != "properties"):
+				securityRequirementRefs(item, keys)
+			}
+			componentRefs(item, append(valPath, k), keys)
+		}
+	case []any:
+		for _, item := range v {
+			componentRefs(item, valPath, keys)
+		}
+	}
+}
+
+func securityRequirementRefs(val any, keys map[ComponentKey]bool) {
+	reqs, ok := val.([]any)
+	if !ok {
+		return
+	}
+	for _, req := range reqs {
+		reqMap, ok := req.(map[string]any)
+		if !ok {
+			continue
+		}
+		for name := range reqMap {
+			keys[ComponentKey{Kind: PathSecuritySchemes, Name: name}] = true
+		}
+	}
+}
+
+// componentsReachable returns the components of `comps`, the decoded
+// `components` object, which are referenced by the root values directly or
+// through other components. Referenced components which do not exist are
+// included.
+func componentsReachable(comps map[string]any, roots ...any) map[ComponentKey]bool {
+	reachable := map[ComponentKey]bool{}
+	for _, root := range roots {
+		componentRefs(root, []string{}, reachable)
+	}
+	queue := []ComponentKey{}
+	for key := range reachable {
+		queue = append(queue, key)
+	}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		items, _ := comps[key.Kind].(map[string]any)
+		item, ok := items[key.Name]
+		if !ok {
+			continue
+		}
+		refs := map[ComponentKey]bool{}
+		componentRefs(item, []string{PathComponents, key.Kind, key.Name}, refs)
+		for ref := range refs {
+			if !reachable[ref] {
+				reachable[ref] = true
+				queue = append(queue, ref)
+			}
+		}
+	}
+	return reachable
+}
+
+// specJSONMap returns the spec decoded as a JSON object.
+func specJSONMap(spec *Spec) (map[string]any, error) {
+	if spec == nil {
+		return nil, ErrSpecNotSet
+	}
+	bytes, err := spec.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]any{}
+	return doc, json.Unmarshal(bytes, &doc)
+}
+
+// ComponentsUnused returns the components which are not reachable from
+// paths, webhooks or global security requirements, directly or through
+// other components, sorted by pointer. Components which only reference each
+// other are unused.
+func (sm *SpecMore) ComponentsUnused() ([]ComponentKey, error) {
+	doc, err := specJSONMap(sm.Spec)
+	if err != nil {
+		return nil, err
+	}
+	comps, _ := doc[PathComponents].(map[string]any)
+	reachable := componentsReachable(comps,
+		map[string]any{"paths": doc["paths"], "webhooks": doc["webhooks"], "security": doc["security"]})
+	unused := []ComponentKey{}
+	for _, kind := range ComponentKinds {
+		items, _ := comps[kind].(map[string]any)
+		for _, name := range sortedMapKeys(items) {
+			key := ComponentKey{Kind: kind, Name: name}
+			if !reachable[key] {
+				unused = append(unused, key)
+			}
+		}
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		return unused[i].Pointer() < unused[j].Pointer()
+	})
+	return unused, nil
+}
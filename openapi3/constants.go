@@ -8,12 +8,16 @@ const (
 	TypeArray      = "array"
 	TypeBoolean    = "boolean"
 	TypeInteger    = "integer"
+	TypeNumber     = "number"
 	TypeObject     = "object"
 	TypeString     = "string"
 	FormatDate     = "date"
 	FormatDateTime = "date-time"
+	FormatEmail    = "email"
 	FormatInt32    = "int32"
 	FormatInt64    = "int64"
+	FormatURI      = "uri"
+	FormatUUID     = "uuid"
 
 	PropertyOperationID = "operationId"
 	PropertySummary     = "summary"
@@ -0,0 +1,344 @@
+package goopenapi3
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	EmbeddedFlatten = "flatten" // embedded struct fields are promoted into the parent schema
+	EmbeddedAllOf   = "allOf"   // embedded structs are components combined with `allOf`
+)
+
+var (
+	typeTime          = reflect.TypeOf(time.Time{})
+	typeRawMessage    = reflect.TypeOf(json.RawMessage{})
+	typeTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	rxNameInvalid     = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+// Options configures a `Reflector`.
+type Options struct {
+	// Embedded is `EmbeddedFlatten` or `EmbeddedAllOf`. Defaults to
+	// `EmbeddedFlatten` which matches `encoding/json` output.
+	Embedded string
+	// SchemaName returns the component name for a named type. Defaults to
+	// the type name, e.g. `User` or `Page_User` for `Page[User]`.
+	SchemaName func(t reflect.Type) string
+}
+
+// Reflector generates `oas3.Schema` components from Go types. Named struct
+// types are added to `Schemas` and referenced with
+// `#/components/schemas/{name}`, which supports recursive types.
+type Reflector struct {
+	opts    Options
+	Schemas oas3.Schemas
+	types   map[string]reflect.Type
+}
+
+func NewReflector(opts *Options) *Reflector {
+	r := &Reflector{
+		Schemas: oas3.Schemas{},
+		types:   map[string]reflect.Type{}}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.Embedded == "" {
+		r.opts.Embedded = EmbeddedFlatten
+	}
+	if r.opts.SchemaName == nil {
+		r.opts.SchemaName = SchemaName
+	}
+	return r
+}
+
+// SchemaName returns the default component name for a type. Generic type
+// arguments are appended with underscores and package paths are removed.
+func SchemaName(t reflect.Type) string {
+	name := t.Name()
+	if idx := strings.Index(name, "["); idx > -1 {
+		args := strings.Split(strings.TrimSuffix(name[idx+1:], "]"), ",")
+		name = name[:idx]
+		for _, arg := range args {
+			if i := strings.LastIndex(arg, "."); i > -1 {
+				arg = arg[i+1:]
+			}
+			name += "_" + arg
+		}
+	}
+	return strings.Trim(rxNameInvalid.ReplaceAllString(name, "_"), "_")
+}
+
+// Add adds component schemas for the types of the supplied values, e.g.
+// `r.Add(User{}, Order{})`. Values must be named struct types.
+func (r *Reflector) Add(values ...any) error {
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct || t.Name() == "" {
+			return fmt.Errorf("value is not a named struct [%T]", v)
+		}
+		if _, err := r.SchemaRef(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SchemaRef returns the schema for a type. Named struct types, other than
+// `time.Time`, are added as components and a reference is returned.
+func (r *Reflector) SchemaRef(t reflect.Type) (*oas3.SchemaRef, error) {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+	schRef, err := r.schemaRef(t)
+	if err != nil || !nullable {
+		return schRef, err
+	}
+	if schRef.Ref != "" {
+		// Siblings of `$ref` are ignored in OpenAPI 3.0 so `allOf` is used.
+		sch := oas3.NewSchema()
+		sch.AllOf = oas3.SchemaRefs{schRef}
+		sch.Nullable = true
+		return oas3.NewSchemaRef("", sch), nil
+	}
+	schRef.Value.Nullable = true
+	return schRef, nil
+}
+
+func (r *Reflector) schemaRef(t reflect.Type) (*oas3.SchemaRef, error) {
+	switch {
+	case t == typeTime:
+		return oas3.NewSchemaRef("", oas3.NewDateTimeSchema()), nil
+	case t == typeRawMessage:
+		return oas3.NewSchemaRef("", oas3.NewSchema()), nil
+	case t.Implements(typeTextMarshaler) || reflect.PointerTo(t).Implements(typeTextMarshaler):
+		// `encoding/json` encodes `encoding.TextMarshaler` values as strings.
+		return oas3.NewSchemaRef("", oas3.NewStringSchema()), nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return oas3.NewSchemaRef("", oas3.NewBoolSchema()), nil
+	case reflect.Int, reflect.Int64:
+		return oas3.NewSchemaRef("", oas3.NewInt64Schema()), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return oas3.NewSchemaRef("", oas3.NewInt32Schema()), nil
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return oas3.NewSchemaRef("", oas3.NewInt64Schema().WithMin(0)), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return oas3.NewSchemaRef("", oas3.NewInt32Schema().WithMin(0)), nil
+	case reflect.Float32:
+		return oas3.NewSchemaRef("", oas3.NewFloat64Schema().WithFormat("float")), nil
+	case reflect.Float64:
+		return oas3.NewSchemaRef("", oas3.NewFloat64Schema().WithFormat("double")), nil
+	case reflect.String:
+		return oas3.NewSchemaRef("", oas3.NewStringSchema()), nil
+	case reflect.Interface:
+		return oas3.NewSchemaRef("", oas3.NewSchema()), nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			// `encoding/json` encodes `[]byte` as base64.
+			return oas3.NewSchemaRef("", oas3.NewBytesSchema()), nil
+		}
+		items, err := r.SchemaRef(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		sch := oas3.NewArraySchema()
+		sch.Items = items
+		if t.Kind() == reflect.Array {
+			sch = sch.WithMinItems(int64(t.Len())).WithMaxItems(int64(t.Len()))
+		}
+		return oas3.NewSchemaRef("", sch), nil
+	case reflect.Map:
+		if k := t.Key().Kind(); k != reflect.String && (k < reflect.Int || k > reflect.Uint64) {
+			return nil, fmt.Errorf("unsupported map key type [%s]", t.Key())
+		}
+		vals, err := r.SchemaRef(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		has := true
+		sch := oas3.NewObjectSchema()
+		sch.AdditionalProperties = oas3.AdditionalProperties{Has: &has, Schema: vals}
+		return oas3.NewSchemaRef("", sch), nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			sch, err := r.structSchema(t)
+			if err != nil {
+				return nil, err
+			}
+			return oas3.NewSchemaRef("", sch), nil
+		}
+		return r.componentRef(t)
+	}
+	return nil, fmt.Errorf("unsupported type [%s]", t)
+}
+
+// componentRef adds a named struct type as a component and returns a
+// reference to it.
+func (r *Reflector) componentRef(t reflect.Type) (*oas3.SchemaRef, error) {
+	name := r.opts.SchemaName(t)
+	ref := openapi3.SchemaPointerExpand("", name)
+	if existing, ok := r.types[name]; ok {
+		if existing != t {
+			return nil, fmt.Errorf("schema name collision [%s] for types [%s] and [%s]", name, existing, t)
+		}
+		return oas3.NewSchemaRef(ref, nil), nil
+	}
+	r.types[name] = t
+	// A placeholder is set so recursive types resolve to the reference.
+	r.Schemas[name] = oas3.NewSchemaRef("", oas3.NewObjectSchema())
+	sch, err := r.structSchema(t)
+	if err != nil {
+		delete(r.types, name)
+		delete(r.Schemas, name)
+		return nil, err
+	}
+	r.Schemas[name] = oas3.NewSchemaRef("", sch)
+	return oas3.NewSchemaRef(ref, nil), nil
+}
+
+// structSchema returns the object schema for a struct, following the
+// `encoding/json` rules for field names, `omitempty` and embedded structs.
+func (r *Reflector) structSchema(t reflect.Type) (*oas3.Schema, error) {
+	sch := oas3.NewObjectSchema()
+	allOf := oas3.SchemaRefs{}
+	required := map[string]bool{}
+	embedded := []embeddedSchema{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		jsonName, jsonOpts := parseJSONTag(f.Tag.Get("json"))
+		if jsonName == "-" && len(jsonOpts) == 0 {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && jsonName == "" && ft.Kind() == reflect.Struct && ft != typeTime {
+			if r.opts.Embedded == EmbeddedAllOf && ft.Name() != "" {
+				ref, err := r.componentRef(ft)
+				if err != nil {
+					return nil, err
+				}
+				allOf = append(allOf, ref)
+				continue
+			}
+			embSch, err := r.structSchema(ft)
+			if err != nil {
+				return nil, err
+			}
+			embedded = append(embedded, embeddedSchema{
+				schema:   embSch,
+				optional: f.Type.Kind() == reflect.Pointer})
+			continue
+		} else if !f.IsExported() {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = f.Name
+		}
+		propRef, err := r.SchemaRef(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field [%s.%s]: %w", t.Name(), f.Name, err)
+		}
+		if jsonOpts["string"] && propRef.Ref == "" && propRef.Value.Type != openapi3.TypeObject &&
+			propRef.Value.Type != openapi3.TypeArray {
+			// The `string` option encodes scalars as JSON strings.
+			propRef.Value.Type = openapi3.TypeString
+		}
+		req := !jsonOpts["omitempty"]
+		if tag, ok := f.Tag.Lookup(TagName); ok {
+			propRef, req, err = applyTag(propRef, req, tag, r.Schemas)
+			if err != nil {
+				return nil, fmt.Errorf("field [%s.%s]: %w", t.Name(), f.Name, err)
+			}
+		}
+		sch.Properties[jsonName] = propRef
+		required[jsonName] = req
+	}
+	// Promoted fields do not override fields declared on the struct.
+	for _, emb := range embedded {
+		for propName, propRef := range emb.schema.Properties {
+			if _, ok := sch.Properties[propName]; !ok {
+				sch.Properties[propName] = propRef
+				required[propName] = !emb.optional && slices.Contains(emb.schema.Required, propName)
+			}
+		}
+	}
+	for propName, req := range required {
+		if req {
+			sch.Required = append(sch.Required, propName)
+		}
+	}
+	sort.Strings(sch.Required)
+	if len(allOf) == 0 {
+		return sch, nil
+	}
+	if len(sch.Properties) > 0 {
+		allOf = append(allOf, oas3.NewSchemaRef("", sch))
+	}
+	return &oas3.Schema{AllOf: allOf}, nil
+}
+
+// embeddedSchema is a flattened embedded struct. Fields of embedded pointers
+// are omitted by `encoding/json` when the pointer is nil so they are optional.
+type embeddedSchema struct {
+	schema   *oas3.Schema
+	optional bool
+}
+
+func parseJSONTag(tag string) (string, map[string]bool) {
+	parts := strings.Split(tag, ",")
+	opts := map[string]bool{}
+	for _, opt := range parts[1:] {
+		opts[strings.TrimSpace(opt)] = true
+	}
+	return strings.TrimSpace(parts[0]), opts
+}
+
+// AddSchemas adds component schemas for the types of the supplied values to
+// a spec, e.g. `AddSchemas(spec, nil, User{}, Order{})`.
+func AddSchemas(spec *openapi3.Spec, opts *Options, values ...any) error {
+	if spec == nil {
+		return openapi3.ErrSpecNotSet
+	}
+	r := NewReflector(opts)
+	if err := r.Add(values...); err != nil {
+		return err
+	}
+	if spec.Components == nil {
+		spec.Components = &oas3.Components{}
+	}
+	sm := openapi3.SpecMore{Spec: spec}
+	for _, name := range sortedKeys(r.Schemas) {
+		if err := sm.SchemaRefSet(name, r.Schemas[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys(schemas oas3.Schemas) []string {
+	keys := []string{}
+	for k := range schemas {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
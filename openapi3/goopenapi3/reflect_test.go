@@ -0,0 +1,108 @@
+package goopenapi3
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	ID        string    `json:"id" spectrum:"format=uuid,description=Unique ID, assigned by the server"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type testUser struct {
+	testBase
+	Name    string            `json:"name" spectrum:"min=1,max=64"`
+	Email   *string           `json:"email,omitempty"`
+	Age     int               `json:"age,omitempty" spectrum:"min=0,max=150"`
+	Status  string            `json:"status" spectrum:"enum=active|inactive"`
+	Manager *testUser         `json:"manager,omitempty"`
+	Tags    []string          `json:"tags,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Secret  string            `json:"-"`
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+}
+
+func TestReflector(t *testing.T) {
+	r := NewReflector(nil)
+	if err := r.Add(testUser{}); err != nil {
+		t.Fatalf("goopenapi3.Reflector.Add() error [%v]", err)
+	}
+	sch := r.Schemas["testUser"].Value
+	if sch == nil || len(sch.Properties) != 9 {
+		t.Fatalf("goopenapi3.Reflector.Add() property count mismatch: want [9], got [%d]", len(sch.Properties))
+	}
+	if got := strings.Join(sch.Required, ","); got != "createdAt,id,name,status" {
+		t.Errorf("goopenapi3.Reflector.Add() required mismatch: want [createdAt,id,name,status], got [%s]", got)
+	}
+	if id := sch.Properties["id"].Value; id.Format != "uuid" || id.Description != "Unique ID, assigned by the server" {
+		t.Errorf("goopenapi3.Reflector.Add() tag mismatch for [id]: got [%s] [%s]", id.Format, id.Description)
+	}
+	if got := sch.Properties["createdAt"].Value.Format; got != "date-time" {
+		t.Errorf("goopenapi3.Reflector.Add() time.Time format mismatch: want [date-time], got [%s]", got)
+	}
+	if !sch.Properties["email"].Value.Nullable {
+		t.Errorf("goopenapi3.Reflector.Add() pointer not nullable for [email]")
+	}
+	if name := sch.Properties["name"].Value; name.MinLength != 1 || name.MaxLength == nil || *name.MaxLength != 64 {
+		t.Errorf("goopenapi3.Reflector.Add() min/max length mismatch for [name]")
+	}
+	if age := sch.Properties["age"].Value; age.Min == nil || *age.Min != 0 || age.Max == nil || *age.Max != 150 {
+		t.Errorf("goopenapi3.Reflector.Add() minimum/maximum mismatch for [age]")
+	}
+	if got := len(sch.Properties["status"].Value.Enum); got != 2 {
+		t.Errorf("goopenapi3.Reflector.Add() enum mismatch for [status]: want [2], got [%d]", got)
+	}
+	if mgr := sch.Properties["manager"].Value; !mgr.Nullable || mgr.AllOf[0].Ref != "#/components/schemas/testUser" {
+		t.Errorf("goopenapi3.Reflector.Add() recursive nullable ref mismatch for [manager]")
+	}
+
+	r = NewReflector(&Options{Embedded: EmbeddedAllOf})
+	if err := r.Add(&testUser{}, Page[testUser]{}); err != nil {
+		t.Fatalf("goopenapi3.Reflector.Add() error [%v]", err)
+	}
+	sch = r.Schemas["testUser"].Value
+	if len(sch.AllOf) != 2 || sch.AllOf[0].Ref != "#/components/schemas/testBase" || r.Schemas["testBase"] == nil {
+		t.Errorf("goopenapi3.Reflector.Add() allOf embedding mismatch")
+	}
+	if page, ok := r.Schemas["Page_testUser"]; !ok || page.Value.Properties["items"].Value.Items.Ref != "#/components/schemas/testUser" {
+		t.Errorf("goopenapi3.Reflector.Add() generic type mismatch: want [Page_testUser]")
+	}
+	if err := r.Add("string"); err == nil {
+		t.Errorf("goopenapi3.Reflector.Add() want error for non-struct value")
+	}
+}
+
+type testAddress struct {
+	City string `json:"city"`
+}
+
+type testContact struct {
+	Home *testAddress `json:"home,omitempty" spectrum:"min=1,example={\"city\":\"Oslo\"}"`
+	Work testAddress  `json:"work" spectrum:"max=2"`
+}
+
+func TestReflectorTagRef(t *testing.T) {
+	r := NewReflector(nil)
+	if err := r.Add(testContact{}); err != nil {
+		t.Fatalf("goopenapi3.Reflector.Add() error [%v]", err)
+	}
+	sch := r.Schemas["testContact"].Value
+	home := sch.Properties["home"].Value
+	if !home.Nullable || len(home.AllOf) != 1 || home.AllOf[0].Ref != "#/components/schemas/testAddress" {
+		t.Fatalf("goopenapi3.Reflector.Add() nullable ref mismatch for [home]")
+	}
+	if home.MinProps != 1 {
+		t.Errorf("goopenapi3.Reflector.Add() minProperties mismatch for [home]: want [1], got [%d]", home.MinProps)
+	}
+	if ex, ok := home.Example.(map[string]any); !ok || ex["city"] != "Oslo" {
+		t.Errorf("goopenapi3.Reflector.Add() example mismatch for [home]: want [map[city:Oslo]], got [%v]", home.Example)
+	}
+	if work := sch.Properties["work"].Value; work.MaxProps == nil || *work.MaxProps != 2 {
+		t.Errorf("goopenapi3.Reflector.Add() maxProperties mismatch for [work]")
+	}
+}
@@ -0,0 +1,199 @@
+package goopenapi3
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+// TagName is the struct tag used for schema properties, e.g.
+// `spectrum:"description=User ID,format=uuid"`.
+const TagName = "spectrum"
+
+const (
+	TagDescription = "description" // description, may contain commas
+	TagFormat      = "format"      // format, e.g. `uuid`
+	TagEnum        = "enum"        // enum values separated by `|`
+	TagMin         = "min"         // minimum, minLength, minItems or minProperties
+	TagMax         = "max"         // maximum, maxLength, maxItems or maxProperties
+	TagPattern     = "pattern"     // pattern
+	TagExample     = "example"     // example, converted to the schema type
+	TagDeprecated  = "deprecated"  // flag setting deprecated
+	TagRequired    = "required"    // flag making the property required
+	TagOptional    = "optional"    // flag making the property optional
+	TagNullable    = "nullable"    // flag setting nullable
+)
+
+var tagKeys = map[string]bool{
+	TagDescription: true, TagFormat: true, TagEnum: true, TagMin: true, TagMax: true,
+	TagPattern: true, TagExample: true, TagDeprecated: true, TagRequired: true,
+	TagOptional: true, TagNullable: true}
+
+// ParseTag parses a `spectrum` struct tag into key values. Flags have an empty
+// value. A segment that does not start with a known key is appended to the
+// previous value so descriptions can contain commas.
+func ParseTag(tag string) (map[string]string, error) {
+	kvs := map[string]string{}
+	last := ""
+	for _, part := range strings.Split(tag, ",") {
+		key, val, hasVal := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		if !tagKeys[key] {
+			if last == "" {
+				if strings.TrimSpace(part) == "" {
+					continue
+				}
+				return kvs, fmt.Errorf("unknown spectrum tag key [%s]", key)
+			}
+			kvs[last] += "," + part
+			continue
+		}
+		if hasVal {
+			kvs[key] = strings.TrimSpace(val)
+		} else {
+			kvs[key] = ""
+		}
+		last = key
+	}
+	return kvs, nil
+}
+
+// applyTag applies a `spectrum` struct tag to a property schema. References
+// are wrapped in `allOf` because `$ref` siblings are ignored in OpenAPI 3.0.
+// The type of a wrapped reference is looked up in `schemas`.
+func applyTag(schRef *oas3.SchemaRef, required bool, tag string, schemas oas3.Schemas) (*oas3.SchemaRef, bool, error) {
+	kvs, err := ParseTag(tag)
+	if err != nil || len(kvs) == 0 {
+		return schRef, required, err
+	}
+	if schRef.Ref != "" {
+		wrap := oas3.NewSchema()
+		wrap.AllOf = oas3.SchemaRefs{schRef}
+		schRef = oas3.NewSchemaRef("", wrap)
+	}
+	sch := schRef.Value
+	schType := schemaType(schRef, schemas, 0)
+	for key, val := range kvs {
+		switch key {
+		case TagDescription:
+			sch.Description = val
+		case TagFormat:
+			sch.Format = val
+		case TagPattern:
+			sch.Pattern = val
+		case TagDeprecated:
+			sch.Deprecated = true
+		case TagNullable:
+			sch.Nullable = true
+		case TagRequired:
+			required = true
+		case TagOptional:
+			required = false
+		case TagEnum:
+			sch.Enum = []any{}
+			for _, s := range strings.Split(val, "|") {
+				v, err := tagValue(schType, s)
+				if err != nil {
+					return schRef, required, err
+				}
+				sch.Enum = append(sch.Enum, v)
+			}
+		case TagExample:
+			v, err := tagValue(schType, val)
+			if err != nil {
+				return schRef, required, err
+			}
+			sch.Example = v
+		case TagMin, TagMax:
+			if err := applyTagLimit(sch, schType, key, val); err != nil {
+				return schRef, required, err
+			}
+		}
+	}
+	return schRef, required, nil
+}
+
+// schemaType returns the type of a schema, following references and `allOf`
+// wrappers, which have no type of their own.
+func schemaType(schRef *oas3.SchemaRef, schemas oas3.Schemas, depth int) string {
+	if schRef == nil || depth > 10 {
+		return ""
+	} else if schRef.Ref != "" {
+		name := strings.TrimPrefix(schRef.Ref, openapi3.PointerComponentsSchemas+"/")
+		return schemaType(schemas[name], schemas, depth+1)
+	} else if schRef.Value == nil {
+		return ""
+	} else if schRef.Value.Type != "" {
+		return schRef.Value.Type
+	}
+	for _, item := range schRef.Value.AllOf {
+		if typ := schemaType(item, schemas, depth+1); typ != "" {
+			return typ
+		}
+	}
+	return ""
+}
+
+// applyTagLimit sets the bound matching the schema type: `minimum`/`maximum`
+// for numbers, `minLength`/`maxLength` for strings, `minItems`/`maxItems` for
+// arrays and `minProperties`/`maxProperties` for objects.
+func applyTagLimit(sch *oas3.Schema, schType, key, val string) error {
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return fmt.Errorf("invalid spectrum tag [%s=%s]: %w", key, val, err)
+	}
+	if schType == openapi3.TypeInteger || schType == openapi3.TypeNumber {
+		if key == TagMin {
+			sch.Min = &f
+		} else {
+			sch.Max = &f
+		}
+		return nil
+	}
+	if f < 0 {
+		return fmt.Errorf("invalid spectrum tag [%s=%s]: negative length", key, val)
+	}
+	u := uint64(f)
+	switch {
+	case schType == openapi3.TypeString && key == TagMin:
+		sch.MinLength = u
+	case schType == openapi3.TypeString:
+		sch.MaxLength = &u
+	case schType == openapi3.TypeArray && key == TagMin:
+		sch.MinItems = u
+	case schType == openapi3.TypeArray:
+		sch.MaxItems = &u
+	case schType == openapi3.TypeObject && key == TagMin:
+		sch.MinProps = u
+	case schType == openapi3.TypeObject:
+		sch.MaxProps = &u
+	default:
+		return fmt.Errorf("spectrum tag [%s] not supported for type [%s]", key, schType)
+	}
+	return nil
+}
+
+// tagValue converts a tag string to a value of the schema type. Object and
+// array values are JSON.
+func tagValue(schemaType, s string) (any, error) {
+	s = strings.TrimSpace(s)
+	switch schemaType {
+	case openapi3.TypeObject, openapi3.TypeArray:
+		var v any
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, fmt.Errorf("invalid spectrum tag value [%s] for type [%s]: %w", s, schemaType, err)
+		}
+		return v, nil
+	case openapi3.TypeInteger:
+		return strconv.ParseInt(s, 10, 64)
+	case openapi3.TypeNumber:
+		return strconv.ParseFloat(s, 64)
+	case openapi3.TypeBoolean:
+		return strconv.ParseBool(s)
+	}
+	return s, nil
+}
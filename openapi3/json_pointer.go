@@ -17,6 +17,8 @@ const (
 	PathPath       = "path"
 	PathSchemas    = "schemas"
 
+	PathSecuritySchemes = "securitySchemes"
+
 	PathComponentsParameters = "#/components/parameters"
 )
 
@@ -4,13 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"reflect"
 	"regexp"
 	"sort"
 	"strings"
 
 	oas3 "github.com/getkin/kin-openapi/openapi3"
 	"github.com/grokify/gocharts/v2/data/table"
+	"github.com/grokify/mogo/encoding/jsonpointer"
 	"github.com/grokify/mogo/errors/errorsutil"
 	"github.com/grokify/mogo/os/osutil"
 )
@@ -18,67 +18,110 @@ import (
 var jsonFileRx = regexp.MustCompile(`(?i)\.(json|yaml|yml)\s*$`)
 
 func MergeDirectory(dir string, mergeOpts *MergeOptions) (*Spec, int, error) {
-	var filenames []string
-	var err error
+	spec, num, _, err := MergeDirectoryMore(dir, mergeOpts)
+	return spec, num, err
+}
+
+// MergeDirectoryMore merges the spec files in `dir` matching
+// `MergeOptions.FileRx`, or JSON and YAML files by default, and returns the
+// number of files and a report of collisions.
+func MergeDirectoryMore(dir string, mergeOpts *MergeOptions) (*Spec, int, *MergeReport, error) {
+	fileRx := jsonFileRx
 	if mergeOpts != nil && mergeOpts.FileRx != nil {
-		entries, err := osutil.ReadDirMore(dir, mergeOpts.FileRx, false, true, false)
-		if err != nil {
-			filenames = entries.Names(dir)
-		}
-	} else {
-		entries, err := osutil.ReadDirMore(dir, jsonFileRx, false, true, false)
-		if err != nil {
-			filenames = entries.Names(dir)
-		}
+		fileRx = mergeOpts.FileRx
 	}
-
+	entries, err := osutil.ReadDirMore(dir, fileRx, false, true, false)
 	if err != nil {
-		return nil, len(filenames), err
+		return nil, 0, nil, err
 	}
-
-	spec, err := MergeFiles(filenames, mergeOpts)
-	return spec, len(filenames), err
+	filenames := entries.Names(dir)
+	spec, report, err := MergeFilesMore(filenames, mergeOpts)
+	return spec, len(filenames), report, err
 }
 
 func MergeFiles(filepaths []string, mergeOpts *MergeOptions) (*Spec, error) {
+	spec, _, err := MergeFilesMore(filepaths, mergeOpts)
+	return spec, err
+}
+
+// MergeFilesMore merges spec files in sorted order and returns a report of
+// collisions. With `MergeOptions.SourceFile`, paths, operations and
+// components are annotated with the file they came from.
+func MergeFilesMore(filepaths []string, mergeOpts *MergeOptions) (*Spec, *MergeReport, error) {
 	sort.Strings(filepaths)
-	validateEach := false
-	validateFinal := true
+	mo := MergeOptions{ValidateFinal: true}
 	if mergeOpts != nil {
-		validateEach = mergeOpts.ValidateEach
-		validateFinal = mergeOpts.ValidateFinal
+		mo = *mergeOpts
 	}
+	report := &MergeReport{}
+	mo.report = report
+	sources := map[*map[string]any]mergeSource{}
 	var specMaster *Spec
 	for i, fpath := range filepaths {
-		thisSpec, err := ReadFile(fpath, validateEach)
+		thisSpec, err := ReadFile(fpath, mo.ValidateEach)
 		if err != nil {
-			return specMaster, errorsutil.Wrap(err, fmt.Sprintf("ReadSpecError [%v] ValidateEach [%v]", fpath, validateEach))
+			return specMaster, report, errorsutil.Wrap(err, fmt.Sprintf("ReadSpecError [%v] ValidateEach [%v]", fpath, mo.ValidateEach))
+		}
+		if mo.SourceFile {
+			src := mergeSource{file: fpath}
+			if mo.SourceFileHash {
+				bytes, err := os.ReadFile(fpath)
+				if err != nil {
+					return specMaster, report, err
+				}
+				src.hash = GitBlobHash(bytes)
+			}
+			for _, ext := range specExtensionMaps(thisSpec) {
+				sources[ext] = src
+			}
 		}
 		if i == 0 {
 			specMaster = thisSpec
 		} else {
-			specMaster, err = Merge(specMaster, thisSpec, fpath, mergeOpts)
+			specMaster, err = Merge(specMaster, thisSpec, fpath, &mo)
 			if err != nil {
-				return nil, errorsutil.Wrap(err, fmt.Sprintf("Merging [%v]", fpath))
+				return nil, report, errorsutil.Wrap(err, fmt.Sprintf("Merging [%v]", fpath))
 			}
 		}
 	}
+	if mo.SourceFile {
+		stampSources(specMaster, sources, mo.SourceFileHash)
+	}
 
-	if validateFinal {
+	if mo.ValidateFinal {
 		bytes, err := specMaster.MarshalJSON()
 		if err != nil {
-			return specMaster, err
+			return specMaster, report, err
 		}
 		newSpec, err := oas3.NewLoader().LoadFromData(bytes)
 		if err != nil {
-			return newSpec, errorsutil.Wrap(err, "Loader.LoadSwaggerFromData (MergeFiles().ValidateFinal)")
+			return newSpec, report, errorsutil.Wrap(err, "Loader.LoadSwaggerFromData (MergeFiles().ValidateFinal)")
 		}
-		return newSpec, nil
+		return newSpec, report, nil
 	}
-	return specMaster, nil
+	return specMaster, report, nil
 }
 
 func Merge(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
+	spec, _, err := MergeMore(specMaster, specExtra, specExtraNote, mergeOpts)
+	return spec, err
+}
+
+// MergeMore merges `specExtra` into `specMaster` and returns a report of
+// every collision and how it was resolved.
+func MergeMore(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, *MergeReport, error) {
+	mo := MergeOptions{}
+	if mergeOpts != nil {
+		mo = *mergeOpts
+	}
+	if mo.report == nil {
+		mo.report = &MergeReport{}
+	}
+	spec, err := merge(specMaster, specExtra, specExtraNote, &mo)
+	return spec, mo.report, err
+}
+
+func merge(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
 	specMaster = MergeTags(specMaster, specExtra)
 	specMaster, err := MergeParameters(specMaster, specExtra, specExtraNote, mergeOpts)
 	if err != nil {
@@ -88,7 +131,7 @@ func Merge(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOp
 	if err != nil {
 		return specMaster, err
 	}
-	specMaster, err = MergePaths(specMaster, specExtra)
+	specMaster, _, err = MergePathsMore(specMaster, specExtra, specExtraNote, mergeOpts)
 	if err != nil {
 		return specMaster, err
 	}
@@ -96,7 +139,20 @@ func Merge(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOp
 	if err != nil {
 		return specMaster, err
 	}
-	return MergeRequestBodies(specMaster, specExtra, specExtraNote)
+	specMaster, err = mergeRequestBodies(specMaster, specExtra, specExtraNote, mergeOpts)
+	if err != nil {
+		return specMaster, err
+	}
+	for _, mergeFunc := range []func(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error){
+		MergeSecuritySchemes, MergeHeaders, MergeExamples, MergeLinks, MergeCallbacks, MergeExternalDocs, MergeExtensions,
+	} {
+		specMaster, err = mergeFunc(specMaster, specExtra, specExtraNote, mergeOpts)
+		if err != nil {
+			return specMaster, err
+		}
+	}
+	specMaster = MergeServers(specMaster, specExtra)
+	return MergeSecurity(specMaster, specExtra), nil
 }
 
 func MergeTags(specMaster, specExtra *Spec) *Spec {
@@ -114,7 +170,7 @@ func MergeTags(specMaster, specExtra *Spec) *Spec {
 }
 
 // MergeWithTables performs a spec merge and returns comparison
-// tables. This is useful to combine with github.com/grokify/gocharts/v2/data/table
+// tables, followed by a table of collisions. This is useful to combine with github.com/grokify/gocharts/v2/data/table
 // WriteXLSX() to write out comparison tables for debugging.
 func MergeWithTables(spec1, spec2 *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, []*table.Table, error) {
 	tbls := []*table.Table{}
@@ -132,9 +188,9 @@ func MergeWithTables(spec1, spec2 *Spec, specExtraNote string, mergeOpts *MergeO
 	}
 	tbls = append(tbls, tbls2)
 	tbls[1].Name = "Spec2"
-	specf, err := Merge(spec1, spec2, specExtraNote, mergeOpts)
+	specf, report, err := MergeMore(spec1, spec2, specExtraNote, mergeOpts)
 	if err != nil {
-		return specf, tbls, err
+		return specf, append(tbls, report.Table()), err
 	}
 	smf := SpecMore{Spec: specf}
 	tblsf, err := smf.OperationsTable(mergeOpts.TableColumns, mergeOpts.TableOpFilterFunc, mergeOpts.TableAddlColFormatFuncs)
@@ -144,33 +200,179 @@ func MergeWithTables(spec1, spec2 *Spec, specExtraNote string, mergeOpts *MergeO
 	tbls = append(tbls, tblsf)
 
 	tbls[2].Name = "SpecFinal"
+	tbls = append(tbls, report.Table())
 	return specf, tbls, nil
 }
 
 func MergePaths(specMaster, specExtra *Spec) (*Spec, error) {
+	specMaster, _, err := MergePathsMore(specMaster, specExtra, "", nil)
+	return specMaster, err
+}
+
+// PathCollision is a path of an extra spec which differs from a path of the
+// master spec only by path variable names, e.g. `/users/{userId}` and
+// `/users/{id}`.
+type PathCollision struct {
+	Path          string
+	PathExtra     string
+	SpecExtraNote string
+}
+
+// MergePathsMore merges the paths of `specExtra` into `specMaster` using the
+// operation collision strategy of `mergeOpts`. Paths which differ from a
+// master path only by variable names are merged into the master path, with
+// path parameters renamed, and returned instead of creating duplicate paths.
+func MergePathsMore(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, []PathCollision, error) {
 	// getkin v0.121.0 to v0.122.0 - new version
+	collisions := []PathCollision{}
 	if specExtra == nil {
-		return specMaster, errors.New("spec extra cannot be nil")
+		return specMaster, collisions, errors.New("spec extra cannot be nil")
+	} else if specExtra.Paths == nil {
+		return specMaster, collisions, nil
+	} else if specMaster.Paths == nil {
+		specMaster.Paths = oas3.NewPaths()
 	}
 	addPathMap := specExtra.Paths.Map()
-	for addPathKey, addPathItem := range addPathMap {
+	for _, addPathKey := range sortedMapKeys(addPathMap) {
+		addPathItem := addPathMap[addPathKey]
 		if addPathItem == nil {
 			continue
 		}
-		srcPathItem := specMaster.Paths.Find(addPathKey)
+		srcPathKey := addPathKey
+		srcPathItem := specMaster.Paths.Value(addPathKey)
 		if srcPathItem == nil {
-			specMaster.Paths.Set(addPathKey, addPathItem)
-			continue
+			for _, masterPathKey := range sortedMapKeys(specMaster.Paths.Map()) {
+				if PathMatchGeneric(masterPathKey, addPathKey) {
+					srcPathKey = masterPathKey
+					srcPathItem = specMaster.Paths.Value(masterPathKey)
+					break
+				}
+			}
+			if srcPathItem == nil {
+				specMaster.Paths.Set(addPathKey, addPathItem)
+				continue
+			}
+			collisions = append(collisions, PathCollision{
+				Path:          srcPathKey,
+				PathExtra:     addPathKey,
+				SpecExtraNote: specExtraNote})
+			if mergeOpts != nil {
+				mergeOpts.report.add(MergeCollision{
+					Kind:          "paths",
+					Name:          srcPathKey,
+					NameExtra:     addPathKey,
+					SpecExtraNote: specExtraNote,
+					Resolution:    MergeResolutionMerge})
+			}
+			if err := pathItemRenamePathVars(specExtra, addPathItem, addPathKey, srcPathKey); err != nil {
+				return specMaster, collisions, err
+			}
+			addPathItem = pathItemParametersToOperations(addPathItem)
 		}
 		srcPathItemMore := PathItemMore{PathItem: srcPathItem}
-		err := srcPathItemMore.AddPathItemOperations(addPathItem, false)
+		err := srcPathItemMore.MergePathItemOperations(srcPathKey, addPathItem, specExtraNote, mergeOpts)
 		if err != nil {
-			return specMaster, err
+			return specMaster, collisions, err
 		}
-		specMaster.Paths.Set(addPathKey, srcPathItemMore.PathItem)
+		specMaster.Paths.Set(srcPathKey, srcPathItemMore.PathItem)
 	}
 
-	return specMaster, nil
+	return specMaster, collisions, nil
+}
+
+// pathItemRenamePathVars renames path parameters of `pathItem` from the
+// variable names of `pathFrom` to those of `pathTo` by position. Parameter
+// references to a renamed path parameter are replaced with an inline copy
+// of the parameter from the components of `spec`.
+func pathItemRenamePathVars(spec *Spec, pathItem *oas3.PathItem, pathFrom, pathTo string) error {
+	varsFrom := rxPathVarToGeneric.FindAllString(pathFrom, -1)
+	varsTo := rxPathVarToGeneric.FindAllString(pathTo, -1)
+	renames := map[string]string{}
+	for i, v := range varsFrom {
+		if i < len(varsTo) {
+			renames[strings.Trim(v, "{}")] = strings.Trim(varsTo[i], "{}")
+		}
+	}
+	rename := func(params oas3.Parameters) error {
+		for i, paramRef := range params {
+			if paramRef == nil {
+				continue
+			}
+			param := paramRef.Value
+			if paramRef.Ref != "" && param == nil {
+				param = parameterComponent(spec, paramRef.Ref)
+				if param == nil {
+					return fmt.Errorf("path parameter reference not found [%s]", paramRef.Ref)
+				}
+			}
+			if param == nil || param.In != oas3.ParameterInPath {
+				continue
+			}
+			name, ok := renames[param.Name]
+			if !ok || name == param.Name {
+				continue
+			} else if paramRef.Ref != "" {
+				paramCopy := *param
+				params[i] = &oas3.ParameterRef{Value: &paramCopy}
+				param = &paramCopy
+			}
+			param.Name = name
+		}
+		return nil
+	}
+	if err := rename(pathItem.Parameters); err != nil {
+		return err
+	}
+	for _, op := range pathItem.Operations() {
+		if op != nil {
+			if err := rename(op.Parameters); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pathItemParametersToOperations returns a copy of `pathItem` with its
+// path-level parameters added to each operation which does not define them,
+// so they are kept when the operations are merged into another path item.
+func pathItemParametersToOperations(pathItem *oas3.PathItem) *oas3.PathItem {
+	if len(pathItem.Parameters) == 0 {
+		return pathItem
+	}
+	out := *pathItem
+	out.Parameters = nil
+	for method, op := range pathItem.Operations() {
+		if op == nil {
+			continue
+		}
+		opCopy := *op
+		opCopy.Parameters = append(oas3.Parameters{}, op.Parameters...)
+		for _, paramRef := range pathItem.Parameters {
+			if paramRef == nil || paramRef.Value == nil ||
+				opCopy.Parameters.GetByInAndName(paramRef.Value.In, paramRef.Value.Name) == nil {
+				opCopy.Parameters = append(opCopy.Parameters, paramRef)
+			}
+		}
+		out.SetOperation(method, &opCopy)
+	}
+	return &out
+}
+
+// parameterComponent returns the component parameter of a local reference.
+func parameterComponent(spec *Spec, ref string) *oas3.Parameter {
+	prefix := PathComponentsParameters + "/"
+	if spec == nil || spec.Components == nil || !strings.HasPrefix(ref, prefix) {
+		return nil
+	}
+	name := jsonpointer.PropertyNameUnescape(strings.TrimPrefix(ref, prefix))
+	if paramRef, ok := spec.Components.Parameters[name]; ok && paramRef != nil {
+		if paramRef.Value == nil && paramRef.Ref != "" {
+			return parameterComponent(spec, paramRef.Ref)
+		}
+		return paramRef.Value
+	}
+	return nil
 }
 
 /*
@@ -253,66 +455,31 @@ func MergePathsOld(specMaster, specExtra *Spec) (*Spec, error) {
 */
 
 func MergeParameters(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
-	if specMaster.Components.Parameters == nil {
-		specMaster.Components.Parameters = map[string]*oas3.ParameterRef{}
-	}
-	for pName, pExtra := range specExtra.Components.Parameters {
-		if pExtra == nil {
-			continue
-		} else if pMaster, ok := specMaster.Components.Parameters[pName]; ok {
-			if pMaster == nil {
-				specMaster.Components.Parameters[pName] = pExtra
-			} else {
-				if mergeOpts == nil {
-					mergeOpts = &MergeOptions{}
-				}
-				if mergeOpts.CollisionCheckResult == CollisionCheckSkip {
-					continue
-				} else if reflect.DeepEqual(pExtra, pMaster) {
-					continue
-				} else if mergeOpts.CollisionCheckResult == CollisionCheckOverwrite {
-					specExtra.Components.Parameters[pName] = pExtra
-				} else {
-					return nil, fmt.Errorf("E_SCHEMA_COLLISION [%v] EXTRA_COMPONENTS_PARAMETER [%s]", specExtraNote, pName)
-				}
-			}
-		} else {
-			specMaster.Components.Parameters[pName] = pExtra
-		}
+	if !mergeComponentsInit(specMaster, specExtra) {
+		return specMaster, nil
 	}
-	return specMaster, nil
+	var err error
+	specMaster.Components.Parameters, err = mergeMap(specMaster.Components.Parameters, specExtra.Components.Parameters,
+		mergeOptsFunc(mergeOpts, func(mo *MergeOptions) CollisionFunc { return mo.ParameterFunc }), "parameters", specExtraNote, mergeOpts)
+	return specMaster, err
 }
 
 func MergeResponses(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
-	if specMaster.Components.Responses == nil {
-		specMaster.Components.Responses = map[string]*oas3.ResponseRef{}
-	}
-	for rName, rExtra := range specExtra.Components.Responses {
-		if rExtra == nil {
-			continue
-		} else if rMaster, ok := specMaster.Components.Responses[rName]; ok {
-			if rMaster == nil {
-				specMaster.Components.Responses[rName] = rExtra
-			} else {
-				if mergeOpts == nil {
-					mergeOpts = &MergeOptions{}
-				}
-				if mergeOpts.CollisionCheckResult == CollisionCheckSkip {
-					continue
-				} else if reflect.DeepEqual(rExtra, rMaster) {
-					continue
-				} else {
-					return nil, fmt.Errorf("E_SCHEMA_COLLISION [%v] EXTRA_COMPONENTS_RESPONSE [%s]", specExtraNote, rName)
-				}
-			}
-		} else {
-			specMaster.Components.Responses[rName] = rExtra
-		}
+	if !mergeComponentsInit(specMaster, specExtra) {
+		return specMaster, nil
 	}
-	return specMaster, nil
+	var err error
+	specMaster.Components.Responses, err = mergeMap(specMaster.Components.Responses, specExtra.Components.Responses,
+		mergeOptsFunc(mergeOpts, func(mo *MergeOptions) CollisionFunc { return mo.ResponseFunc }), "responses", specExtraNote, mergeOpts)
+	return specMaster, err
 }
 
 func MergeSchemas(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
+	if !mergeComponentsInit(specMaster, specExtra) {
+		return specMaster, nil
+	} else if specMaster.Components.Schemas == nil {
+		specMaster.Components.Schemas = oas3.Schemas{}
+	}
 	for schemaName, schemaExtra := range specExtra.Components.Schemas {
 		if schemaExtra == nil {
 			continue
@@ -324,6 +491,14 @@ func MergeSchemas(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *
 					mergeOpts = &MergeOptions{}
 				}
 				checkCollisionResult := mergeOpts.CheckSchemaCollision(schemaName, schemaMaster, schemaExtra, specExtraNote)
+				resolution := MergeResolutionSame
+				if checkCollisionResult != CollisionCheckSame {
+					resolution = MergeResolutionKeepFirst
+					if mergeOpts.CollisionCheckResult == CollisionCheckOverwrite || mergeOpts.CollisionCheckResult == CollisionCheckError {
+						resolution = collisionResolution(mergeOpts.CollisionCheckResult)
+					}
+				}
+				mergeOpts.recordCollision(PathSchemas, schemaName, specExtraNote, resolution)
 				if checkCollisionResult != CollisionCheckSame &&
 					mergeOpts.CollisionCheckResult != CollisionCheckSkip {
 					if mergeOpts.CollisionCheckResult == CollisionCheckOverwrite {
@@ -347,26 +522,17 @@ func MergeSchemas(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *
 }
 
 func MergeRequestBodies(specMaster, specExtra *Spec, specExtraNote string) (*Spec, error) {
-	for rbName, rbExtra := range specExtra.Components.RequestBodies {
-		if rbExtra == nil {
-			continue
-		} else if rbMaster, ok := specMaster.Components.RequestBodies[rbName]; ok {
-			if rbMaster == nil {
-				if specMaster.Components.RequestBodies == nil {
-					specMaster.Components.RequestBodies = map[string]*oas3.RequestBodyRef{}
-				}
-				specMaster.Components.RequestBodies[rbName] = rbExtra
-			} else if !reflect.DeepEqual(rbMaster, rbExtra) {
-				return nil, fmt.Errorf("E_SCHEMA_COLLISION [%v] EXTRA_SPEC [%s]", rbName, specExtraNote)
-			}
-		} else {
-			if specMaster.Components.RequestBodies == nil {
-				specMaster.Components.RequestBodies = map[string]*oas3.RequestBodyRef{}
-			}
-			specMaster.Components.RequestBodies[rbName] = rbExtra
-		}
+	return mergeRequestBodies(specMaster, specExtra, specExtraNote, nil)
+}
+
+func mergeRequestBodies(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
+	if !mergeComponentsInit(specMaster, specExtra) {
+		return specMaster, nil
 	}
-	return specMaster, nil
+	var err error
+	specMaster.Components.RequestBodies, err = mergeMap(specMaster.Components.RequestBodies, specExtra.Components.RequestBodies,
+		mergeOptsFunc(mergeOpts, func(mo *MergeOptions) CollisionFunc { return mo.RequestBodyFunc }), "requestBodies", specExtraNote, mergeOpts)
+	return specMaster, err
 }
 
 func WriteFileDirMerge(outfile, inputDir string, perm os.FileMode, mergeOpts *MergeOptions) (int, error) {
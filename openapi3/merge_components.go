@@ -0,0 +1,267 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+)
+
+// XTagGroupsRedocly is the Redocly tag groups extension. Tag groups in
+// `x-tagGroups` and `x-tag-groups` are merged by group name.
+const XTagGroupsRedocly = "x-tagGroups"
+
+// mergeMap merges items of `extra` into `master` using the collision
+// function `fn`. `kind` is used in errors.
+func mergeMap[M ~map[string]V, V comparable](master, extra M, fn CollisionFunc, kind, specExtraNote string, mergeOpts *MergeOptions) (M, error) {
+	var zero V
+	for name, itemExtra := range extra {
+		if itemExtra == zero {
+			continue
+		}
+		if master == nil {
+			master = M{}
+		}
+		itemMaster, ok := master[name]
+		if !ok || itemMaster == zero {
+			master[name] = itemExtra
+			continue
+		}
+		res := mergeOpts.CheckCollision(fn, name, itemMaster, itemExtra, specExtraNote)
+		mergeOpts.recordCollision(kind, name, specExtraNote, collisionResolution(res))
+		switch res {
+		case CollisionCheckOverwrite:
+			master[name] = itemExtra
+		case CollisionCheckError:
+			return master, fmt.Errorf("E_COMPONENT_COLLISION [%s] NAME [%s] EXTRA_SPEC [%s]", kind, name, specExtraNote)
+		}
+	}
+	return master, nil
+}
+
+func mergeComponentsInit(specMaster, specExtra *Spec) bool {
+	if specExtra == nil || specExtra.Components == nil {
+		return false
+	} else if specMaster.Components == nil {
+		specMaster.Components = &oas3.Components{}
+	}
+	return true
+}
+
+func MergeSecuritySchemes(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
+	if !mergeComponentsInit(specMaster, specExtra) {
+		return specMaster, nil
+	}
+	var err error
+	specMaster.Components.SecuritySchemes, err = mergeMap(specMaster.Components.SecuritySchemes, specExtra.Components.SecuritySchemes,
+		mergeOptsFunc(mergeOpts, func(mo *MergeOptions) CollisionFunc { return mo.SecuritySchemeFunc }), "securitySchemes", specExtraNote, mergeOpts)
+	return specMaster, err
+}
+
+func MergeHeaders(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
+	if !mergeComponentsInit(specMaster, specExtra) {
+		return specMaster, nil
+	}
+	var err error
+	specMaster.Components.Headers, err = mergeMap(specMaster.Components.Headers, specExtra.Components.Headers,
+		mergeOptsFunc(mergeOpts, func(mo *MergeOptions) CollisionFunc { return mo.HeaderFunc }), "headers", specExtraNote, mergeOpts)
+	return specMaster, err
+}
+
+func MergeExamples(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
+	if !mergeComponentsInit(specMaster, specExtra) {
+		return specMaster, nil
+	}
+	var err error
+	specMaster.Components.Examples, err = mergeMap(specMaster.Components.Examples, specExtra.Components.Examples,
+		mergeOptsFunc(mergeOpts, func(mo *MergeOptions) CollisionFunc { return mo.ExampleFunc }), "examples", specExtraNote, mergeOpts)
+	return specMaster, err
+}
+
+func MergeLinks(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
+	if !mergeComponentsInit(specMaster, specExtra) {
+		return specMaster, nil
+	}
+	var err error
+	specMaster.Components.Links, err = mergeMap(specMaster.Components.Links, specExtra.Components.Links,
+		mergeOptsFunc(mergeOpts, func(mo *MergeOptions) CollisionFunc { return mo.LinkFunc }), "links", specExtraNote, mergeOpts)
+	return specMaster, err
+}
+
+func MergeCallbacks(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
+	if !mergeComponentsInit(specMaster, specExtra) {
+		return specMaster, nil
+	}
+	var err error
+	specMaster.Components.Callbacks, err = mergeMap(specMaster.Components.Callbacks, specExtra.Components.Callbacks,
+		mergeOptsFunc(mergeOpts, func(mo *MergeOptions) CollisionFunc { return mo.CallbackFunc }), "callbacks", specExtraNote, mergeOpts)
+	return specMaster, err
+}
+
+// MergeServers appends servers of `specExtra` which are not in `specMaster`.
+func MergeServers(specMaster, specExtra *Spec) *Spec {
+	for _, srvExtra := range specExtra.Servers {
+		if srvExtra == nil {
+			continue
+		}
+		found := false
+		for _, srvMaster := range specMaster.Servers {
+			if reflect.DeepEqual(srvMaster, srvExtra) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			specMaster.Servers = append(specMaster.Servers, srvExtra)
+		}
+	}
+	return specMaster
+}
+
+// MergeSecurity appends top-level security requirements of `specExtra`
+// which are not in `specMaster`.
+func MergeSecurity(specMaster, specExtra *Spec) *Spec {
+	for _, secExtra := range specExtra.Security {
+		found := false
+		for _, secMaster := range specMaster.Security {
+			if reflect.DeepEqual(secMaster, secExtra) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			specMaster.Security = append(specMaster.Security, secExtra)
+		}
+	}
+	return specMaster
+}
+
+func MergeExternalDocs(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
+	if specExtra.ExternalDocs == nil {
+		return specMaster, nil
+	} else if specMaster.ExternalDocs == nil {
+		specMaster.ExternalDocs = specExtra.ExternalDocs
+		return specMaster, nil
+	}
+	res := mergeOpts.CheckCollision(mergeOptsFunc(mergeOpts, func(mo *MergeOptions) CollisionFunc { return mo.ExternalDocsFunc }),
+		"externalDocs", specMaster.ExternalDocs, specExtra.ExternalDocs, specExtraNote)
+	mergeOpts.recordCollision("externalDocs", "externalDocs", specExtraNote, collisionResolution(res))
+	switch res {
+	case CollisionCheckOverwrite:
+		specMaster.ExternalDocs = specExtra.ExternalDocs
+	case CollisionCheckError:
+		return specMaster, fmt.Errorf("E_EXTERNAL_DOCS_COLLISION EXTRA_SPEC [%s]", specExtraNote)
+	}
+	return specMaster, nil
+}
+
+// MergeExtensions merges spec and components extensions. Tag groups are
+// merged by group name and other extensions use `MergeOptions.ExtensionFunc`.
+func MergeExtensions(specMaster, specExtra *Spec, specExtraNote string, mergeOpts *MergeOptions) (*Spec, error) {
+	var err error
+	specMaster.Extensions, err = mergeExtensions(specMaster.Extensions, specExtra.Extensions, specExtraNote, mergeOpts)
+	if err != nil {
+		return specMaster, err
+	}
+	if mergeComponentsInit(specMaster, specExtra) {
+		specMaster.Components.Extensions, err = mergeExtensions(specMaster.Components.Extensions, specExtra.Components.Extensions, specExtraNote, mergeOpts)
+	}
+	return specMaster, err
+}
+
+func mergeExtensions(master, extra map[string]any, specExtraNote string, mergeOpts *MergeOptions) (map[string]any, error) {
+	for key, valExtra := range extra {
+		if master == nil {
+			master = map[string]any{}
+		}
+		valMaster, ok := master[key]
+		if !ok {
+			master[key] = valExtra
+			continue
+		}
+		if key == XTagGroupsRedocly || key == XTagGroups {
+			merged, err := mergeTagGroups(valMaster, valExtra)
+			if err != nil {
+				return master, fmt.Errorf("E_EXTENSION_MERGE [%s] EXTRA_SPEC [%s]: %w", key, specExtraNote, err)
+			}
+			master[key] = merged
+			mergeOpts.recordCollision("extensions", key, specExtraNote, MergeResolutionMerge)
+			continue
+		}
+		res := mergeOpts.CheckCollision(mergeOptsFunc(mergeOpts, func(mo *MergeOptions) CollisionFunc { return mo.ExtensionFunc }),
+			key, valMaster, valExtra, specExtraNote)
+		mergeOpts.recordCollision("extensions", key, specExtraNote, collisionResolution(res))
+		switch res {
+		case CollisionCheckOverwrite:
+			master[key] = valExtra
+		case CollisionCheckError:
+			return master, fmt.Errorf("E_EXTENSION_COLLISION [%s] EXTRA_SPEC [%s]", key, specExtraNote)
+		}
+	}
+	return master, nil
+}
+
+// mergeTagGroups merges two tag group lists, appending tags to groups with
+// the same name and adding new groups.
+func mergeTagGroups(valMaster, valExtra any) ([]map[string]any, error) {
+	groups, err := tagGroupsList(valMaster)
+	if err != nil {
+		return nil, err
+	}
+	groupsExtra, err := tagGroupsList(valExtra)
+	if err != nil {
+		return nil, err
+	}
+	for _, groupExtra := range groupsExtra {
+		var group map[string]any
+		for _, g := range groups {
+			if g["name"] == groupExtra["name"] {
+				group = g
+				break
+			}
+		}
+		if group == nil {
+			groups = append(groups, groupExtra)
+			continue
+		}
+		tags, _ := group["tags"].([]any)
+		tagsExtra, _ := groupExtra["tags"].([]any)
+		for _, tag := range tagsExtra {
+			found := false
+			for _, t := range tags {
+				if t == tag {
+					found = true
+					break
+				}
+			}
+			if !found {
+				tags = append(tags, tag)
+			}
+		}
+		group["tags"] = tags
+	}
+	return groups, nil
+}
+
+func tagGroupsList(val any) ([]map[string]any, error) {
+	var bytes []byte
+	if raw, ok := val.(json.RawMessage); ok {
+		bytes = raw
+	} else {
+		var err error
+		if bytes, err = json.Marshal(val); err != nil {
+			return nil, err
+		}
+	}
+	groups := []map[string]any{}
+	return groups, json.Unmarshal(bytes, &groups)
+}
+
+// mergeOptsFunc returns a collision function from options which may be nil.
+func mergeOptsFunc(mergeOpts *MergeOptions, fn func(mo *MergeOptions) CollisionFunc) CollisionFunc {
+	if mergeOpts == nil {
+		return nil
+	}
+	return fn(mergeOpts)
+}
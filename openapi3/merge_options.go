@@ -17,15 +17,65 @@ const (
 	CollisionCheckSkip
 )
 
+// CollisionFunc checks an item present in both specs by name. The result
+// is `CollisionCheckSame` or `CollisionCheckSkip` to keep the item of the
+// first spec, `CollisionCheckOverwrite` to use the item of the second spec
+// or `CollisionCheckError` to fail the merge.
+type CollisionFunc func(name string, item1, item2 interface{}, hint2 string) CollisionCheckResult
+
+// OperationCollision is the strategy for an operation present in both specs
+// with the same path and method.
+type OperationCollision int
+
+const (
+	// OperationCollisionError fails the merge when operations are not equal.
+	OperationCollisionError OperationCollision = iota
+	// OperationCollisionKeepFirst keeps the operation of the first spec.
+	OperationCollisionKeepFirst
+	// OperationCollisionOverwrite uses the operation of the second spec.
+	OperationCollisionOverwrite
+	// OperationCollisionDeepMerge keeps the operation of the first spec and
+	// adds parameters, by `in` and `name`, and responses, by status code,
+	// of the second spec which it does not have.
+	OperationCollisionDeepMerge
+)
+
+// OperationFunc resolves an operation present in both specs by returning
+// the operation to use.
+type OperationFunc func(path, method string, op1, op2 *oas3.Operation, hint2 string) (*oas3.Operation, error)
+
 type MergeOptions struct {
-	FileRx                  *regexp.Regexp
-	SchemaFunc              func(schemaName string, sch1, sch2 interface{}, hint2 string) CollisionCheckResult
+	FileRx     *regexp.Regexp
+	SchemaFunc func(schemaName string, sch1, sch2 interface{}, hint2 string) CollisionCheckResult
+	// Collision functions for other component types and spec properties.
+	// When nil, equal items are the same and `CollisionCheckResult` is used
+	// for others, with an error when it is not set.
+	ParameterFunc      CollisionFunc
+	ResponseFunc       CollisionFunc
+	RequestBodyFunc    CollisionFunc
+	SecuritySchemeFunc CollisionFunc
+	HeaderFunc         CollisionFunc
+	ExampleFunc        CollisionFunc
+	LinkFunc           CollisionFunc
+	CallbackFunc       CollisionFunc
+	ExternalDocsFunc   CollisionFunc
+	ExtensionFunc      CollisionFunc
+	// OperationCollision is used for operations which are not equal unless
+	// `OperationFunc` is set.
+	OperationCollision OperationCollision
+	OperationFunc      OperationFunc
+	// SourceFile sets `x-source-file` on paths, operations and components
+	// merged by `MergeFiles()` and `MergeDirectory()`. SourceFileHash also
+	// sets `x-source-hash` to the git blob hash of the file.
+	SourceFile              bool
+	SourceFileHash          bool
 	CollisionCheckResult    CollisionCheckResult
 	ValidateEach            bool
 	ValidateFinal           bool
 	TableColumns            *tabulator.ColumnSet
 	TableOpFilterFunc       func(path, method string, op *oas3.Operation) bool
 	TableAddlColFormatFuncs *OperationMoreStringFuncMap
+	report                  *MergeReport
 }
 
 func NewMergeOptionsSkip() *MergeOptions {
@@ -43,6 +93,29 @@ func (mo *MergeOptions) CheckSchemaCollision(schemaName string, sch1, sch2 inter
 	return mo.SchemaFunc(schemaName, sch1, sch2, hint2)
 }
 
+// CheckCollision checks an item present in both specs using `fn`. A
+// `CollisionCheckResult` of `CollisionCheckSkip` or `CollisionCheckOverwrite`
+// applies to all items which are not the same.
+func (mo *MergeOptions) CheckCollision(fn CollisionFunc, name string, item1, item2 interface{}, hint2 string) CollisionCheckResult {
+	if mo == nil {
+		mo = &MergeOptions{}
+	}
+	var res CollisionCheckResult
+	if fn != nil {
+		res = fn(name, item1, item2, hint2)
+	} else if reflect.DeepEqual(item1, item2) {
+		res = CollisionCheckSame
+	} else {
+		res = CollisionCheckError
+	}
+	if res == CollisionCheckSame {
+		return res
+	} else if mo.CollisionCheckResult == CollisionCheckSkip || mo.CollisionCheckResult == CollisionCheckOverwrite {
+		return mo.CollisionCheckResult
+	}
+	return res
+}
+
 func SchemaCheckCollisionDefault(schemaName string, item1, item2 interface{}, item2Note string) CollisionCheckResult {
 	if reflect.DeepEqual(item1, item2) {
 		return CollisionCheckSame
@@ -0,0 +1,213 @@
+package openapi3
+
+import (
+	"crypto/sha1" // #nosec G505
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/grokify/gocharts/v2/data/table"
+)
+
+const (
+	// XSourceFile is the file a merged path, operation or component came
+	// from, set when `MergeOptions.SourceFile` is true.
+	XSourceFile = "x-source-file"
+	// XSourceHash is the git blob hash of the file in `x-source-file`, set
+	// when `MergeOptions.SourceFileHash` is true.
+	XSourceHash = "x-source-hash"
+)
+
+const (
+	MergeResolutionSame      = "same"
+	MergeResolutionKeepFirst = "keepFirst"
+	MergeResolutionOverwrite = "overwrite"
+	MergeResolutionMerge     = "merge"
+	MergeResolutionCallback  = "callback"
+	MergeResolutionError     = "error"
+)
+
+// MergeCollision is an item present in both specs of a merge.
+type MergeCollision struct {
+	// Kind is a component kind such as `schemas`, `operations`, `paths`,
+	// `externalDocs` or `extensions`.
+	Kind string
+	// Name is the component name, extension key, operation as `METHOD path`
+	// or path of the first spec.
+	Name string
+	// NameExtra is the path of the second spec for `paths` collisions.
+	NameExtra     string
+	SpecExtraNote string
+	// Resolution is one of the `MergeResolution` constants.
+	Resolution string
+}
+
+func (c MergeCollision) String() string {
+	if c.NameExtra != "" {
+		return fmt.Sprintf("%s [%s] [%s] EXTRA_SPEC [%s]: %s", c.Kind, c.Name, c.NameExtra, c.SpecExtraNote, c.Resolution)
+	}
+	return fmt.Sprintf("%s [%s] EXTRA_SPEC [%s]: %s", c.Kind, c.Name, c.SpecExtraNote, c.Resolution)
+}
+
+// MergeReport lists the collisions of one or more merges.
+type MergeReport struct {
+	Collisions []MergeCollision
+}
+
+func (r *MergeReport) add(c MergeCollision) {
+	if r != nil {
+		r.Collisions = append(r.Collisions, c)
+	}
+}
+
+// Skipped returns collisions where the item of the second spec was not used.
+func (r *MergeReport) Skipped() []MergeCollision {
+	var skipped []MergeCollision
+	for _, c := range r.Collisions {
+		if c.Resolution == MergeResolutionSame || c.Resolution == MergeResolutionKeepFirst {
+			skipped = append(skipped, c)
+		}
+	}
+	return skipped
+}
+
+// Table returns the collisions as a table for use with `MergeWithTables()`.
+func (r *MergeReport) Table() *table.Table {
+	tbl := table.NewTable("Collisions")
+	tbl.Columns = []string{"Kind", "Name", "Name Extra", "Extra Spec", "Resolution"}
+	for _, c := range r.Collisions {
+		tbl.Rows = append(tbl.Rows, []string{c.Kind, c.Name, c.NameExtra, c.SpecExtraNote, c.Resolution})
+	}
+	return &tbl
+}
+
+func (mo *MergeOptions) recordCollision(kind, name, specExtraNote, resolution string) {
+	if mo != nil {
+		mo.report.add(MergeCollision{
+			Kind:          kind,
+			Name:          name,
+			SpecExtraNote: specExtraNote,
+			Resolution:    resolution})
+	}
+}
+
+func collisionResolution(res CollisionCheckResult) string {
+	switch res {
+	case CollisionCheckSame:
+		return MergeResolutionSame
+	case CollisionCheckSkip:
+		return MergeResolutionKeepFirst
+	case CollisionCheckOverwrite:
+		return MergeResolutionOverwrite
+	default:
+		return MergeResolutionError
+	}
+}
+
+// GitBlobHash returns the git blob hash of `data`, as with
+// `git hash-object`.
+func GitBlobHash(data []byte) string {
+	h := sha1.New() // #nosec G401
+	h.Write([]byte("blob " + strconv.Itoa(len(data)) + "\x00"))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// mergeSource is the file of a spec being merged.
+type mergeSource struct {
+	file string
+	hash string
+}
+
+// specExtensionMaps returns pointers to the extension maps of the paths,
+// operations and components of a spec, which identify the items across
+// merges as items are merged by pointer.
+func specExtensionMaps(spec *Spec) []*map[string]any {
+	var exts []*map[string]any
+	if spec == nil {
+		return exts
+	}
+	if spec.Paths != nil {
+		for _, pathItem := range spec.Paths.Map() {
+			if pathItem == nil || pathItem.Ref != "" {
+				continue
+			}
+			exts = append(exts, &pathItem.Extensions)
+			for _, op := range pathItem.Operations() {
+				if op != nil {
+					exts = append(exts, &op.Extensions)
+				}
+			}
+		}
+	}
+	comps := spec.Components
+	if comps == nil {
+		return exts
+	}
+	for _, ref := range comps.Schemas {
+		if ref != nil && ref.Ref == "" && ref.Value != nil {
+			exts = append(exts, &ref.Value.Extensions)
+		}
+	}
+	for _, ref := range comps.Parameters {
+		if ref != nil && ref.Ref == "" && ref.Value != nil {
+			exts = append(exts, &ref.Value.Extensions)
+		}
+	}
+	for _, ref := range comps.Headers {
+		if ref != nil && ref.Ref == "" && ref.Value != nil {
+			exts = append(exts, &ref.Value.Extensions)
+		}
+	}
+	for _, ref := range comps.RequestBodies {
+		if ref != nil && ref.Ref == "" && ref.Value != nil {
+			exts = append(exts, &ref.Value.Extensions)
+		}
+	}
+	for _, ref := range comps.Responses {
+		if ref != nil && ref.Ref == "" && ref.Value != nil {
+			exts = append(exts, &ref.Value.Extensions)
+		}
+	}
+	for _, ref := range comps.SecuritySchemes {
+		if ref != nil && ref.Ref == "" && ref.Value != nil {
+			exts = append(exts, &ref.Value.Extensions)
+		}
+	}
+	for _, ref := range comps.Examples {
+		if ref != nil && ref.Ref == "" && ref.Value != nil {
+			exts = append(exts, &ref.Value.Extensions)
+		}
+	}
+	for _, ref := range comps.Links {
+		if ref != nil && ref.Ref == "" && ref.Value != nil {
+			exts = append(exts, &ref.Value.Extensions)
+		}
+	}
+	for _, ref := range comps.Callbacks {
+		if ref != nil && ref.Ref == "" && ref.Value != nil {
+			exts = append(exts, &ref.Value.Extensions)
+		}
+	}
+	return exts
+}
+
+// stampSources sets `x-source-file` and optionally `x-source-hash` on items
+// of `spec` found in `sources`. Items which already have `x-source-file`
+// are not changed.
+func stampSources(spec *Spec, sources map[*map[string]any]mergeSource, withHash bool) {
+	for _, ext := range specExtensionMaps(spec) {
+		src, ok := sources[ext]
+		if !ok {
+			continue
+		} else if _, ok := (*ext)[XSourceFile]; ok {
+			continue
+		} else if *ext == nil {
+			*ext = map[string]any{}
+		}
+		(*ext)[XSourceFile] = src.file
+		if withHash && src.hash != "" {
+			(*ext)[XSourceHash] = src.hash
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package openapi3
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+)
+
+const mergeTestSpec1 = `{
+  "openapi": "3.0.3",
+  "info": {"title": "One", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}],
+  "security": [{"bearerAuth": []}],
+  "paths": {},
+  "x-tagGroups": [{"name": "Core", "tags": ["Users"]}],
+  "components": {
+    "securitySchemes": {"bearerAuth": {"type": "http", "scheme": "bearer"}},
+    "headers": {"RateLimit": {"schema": {"type": "integer"}}}
+  }
+}`
+
+const mergeTestSpec2 = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Two", "version": "1.0.0"},
+  "servers": [{"url": "https://api.example.com"}, {"url": "https://sandbox.example.com"}],
+  "security": [{"bearerAuth": []}, {"apiKey": []}],
+  "externalDocs": {"url": "https://docs.example.com"},
+  "paths": {},
+  "x-tagGroups": [{"name": "Core", "tags": ["Users", "Teams"]}, {"name": "Billing", "tags": ["Invoices"]}],
+  "components": {
+    "securitySchemes": {
+      "bearerAuth": {"type": "http", "scheme": "bearer"},
+      "apiKey": {"type": "apiKey", "in": "header", "name": "X-API-Key"}
+    },
+    "headers": {"RateLimit": {"schema": {"type": "string"}}},
+    "examples": {"User": {"value": {"id": "1"}}},
+    "links": {"GetUser": {"operationId": "getUser"}}
+  }
+}`
+
+func TestMergeComponents(t *testing.T) {
+	tests := []struct {
+		opts          *MergeOptions
+		wantErr       bool
+		wantHeaderTyp string
+	}{
+		{nil, true, ""},
+		{&MergeOptions{CollisionCheckResult: CollisionCheckSkip}, false, TypeInteger},
+		{&MergeOptions{HeaderFunc: func(name string, item1, item2 interface{}, hint2 string) CollisionCheckResult {
+			return CollisionCheckOverwrite
+		}}, false, TypeString},
+	}
+	for _, tt := range tests {
+		spec1, err := Parse([]byte(mergeTestSpec1))
+		if err != nil {
+			t.Fatalf("openapi3.Parse() error [%v]", err)
+		}
+		spec2, err := Parse([]byte(mergeTestSpec2))
+		if err != nil {
+			t.Fatalf("openapi3.Parse() error [%v]", err)
+		}
+		spec, err := Merge(spec1, spec2, "spec2", tt.opts)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("openapi3.Merge() want error for header collision")
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("openapi3.Merge() error [%v]", err)
+		}
+		comps := spec.Components
+		if len(comps.SecuritySchemes) != 2 || len(comps.Examples) != 1 || len(comps.Links) != 1 {
+			t.Errorf("openapi3.Merge() components mismatch: securitySchemes [%d] examples [%d] links [%d]",
+				len(comps.SecuritySchemes), len(comps.Examples), len(comps.Links))
+		}
+		if got := comps.Headers["RateLimit"].Value.Schema.Value.Type; got != tt.wantHeaderTyp {
+			t.Errorf("openapi3.Merge() header collision mismatch: want [%s], got [%s]", tt.wantHeaderTyp, got)
+		}
+		if len(spec.Servers) != 2 || len(spec.Security) != 2 {
+			t.Errorf("openapi3.Merge() servers/security mismatch: want [2/2], got [%d/%d]", len(spec.Servers), len(spec.Security))
+		}
+		if spec.ExternalDocs == nil || spec.ExternalDocs.URL != "https://docs.example.com" {
+			t.Errorf("openapi3.Merge() externalDocs not merged")
+		}
+		groups, err := tagGroupsList(spec.Extensions[XTagGroupsRedocly])
+		if err != nil {
+			t.Fatalf("tagGroupsList() error [%v]", err)
+		}
+		if len(groups) != 2 || len(groups[0]["tags"].([]any)) != 2 {
+			t.Errorf("openapi3.Merge() tag groups mismatch: got [%v]", groups)
+		}
+	}
+}
+
+const mergePathsTestSpec1 = `{
+  "openapi": "3.0.3",
+  "info": {"title": "One", "version": "1.0.0"},
+  "paths": {
+    "/users/{id}": {
+      "get": {
+        "operationId": "getUser",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+const mergePathsTestSpec2 = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Two", "version": "1.0.0"},
+  "paths": {
+    "/users/{userId}": {
+      "get": {
+        "operationId": "getUserV2",
+        "parameters": [
+          {"name": "userId", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "expand", "in": "query", "schema": {"type": "boolean"}}
+        ],
+        "responses": {"200": {"description": "OK"}, "404": {"description": "Not Found"}}
+      },
+      "delete": {
+        "operationId": "deleteUser",
+        "parameters": [{"name": "userId", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"204": {"description": "No Content"}}
+      }
+    }
+  }
+}`
+
+func TestMergePathsMore(t *testing.T) {
+	tests := []struct {
+		opts          *MergeOptions
+		wantErr       bool
+		wantOpID      string
+		wantParams    int
+		wantResponses int
+	}{
+		{nil, true, "", 0, 0},
+		{&MergeOptions{OperationCollision: OperationCollisionKeepFirst}, false, "getUser", 1, 1},
+		{&MergeOptions{OperationCollision: OperationCollisionOverwrite}, false, "getUserV2", 2, 2},
+		{&MergeOptions{OperationCollision: OperationCollisionDeepMerge}, false, "getUser", 2, 2},
+		{&MergeOptions{OperationFunc: func(path, method string, op1, op2 *oas3.Operation, hint2 string) (*oas3.Operation, error) {
+			return nil, errors.New("collision")
+		}}, true, "", 0, 0},
+	}
+	for _, tt := range tests {
+		spec1, err := Parse([]byte(mergePathsTestSpec1))
+		if err != nil {
+			t.Fatalf("openapi3.Parse() error [%v]", err)
+		}
+		spec2, err := Parse([]byte(mergePathsTestSpec2))
+		if err != nil {
+			t.Fatalf("openapi3.Parse() error [%v]", err)
+		}
+		spec, collisions, err := MergePathsMore(spec1, spec2, "spec2", tt.opts)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("openapi3.MergePathsMore() want error for operation collision")
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("openapi3.MergePathsMore() error [%v]", err)
+		}
+		if spec.Paths.Len() != 1 || len(collisions) != 1 ||
+			collisions[0].Path != "/users/{id}" || collisions[0].PathExtra != "/users/{userId}" {
+			t.Errorf("openapi3.MergePathsMore() path collision mismatch: paths [%d] collisions [%v]", spec.Paths.Len(), collisions)
+		}
+		pathItem := spec.Paths.Value("/users/{id}")
+		if pathItem == nil || pathItem.Get == nil || pathItem.Delete == nil {
+			t.Fatalf("openapi3.MergePathsMore() operations not merged into [/users/{id}]")
+		}
+		if pathItem.Delete.Parameters.GetByInAndName(oas3.ParameterInPath, "id") == nil {
+			t.Errorf("openapi3.MergePathsMore() path parameter not renamed: want [id]")
+		}
+		op := pathItem.Get
+		if op.OperationID != tt.wantOpID || len(op.Parameters) != tt.wantParams || op.Responses.Len() != tt.wantResponses {
+			t.Errorf("openapi3.MergePathsMore() operation mismatch: want [%s/%d/%d], got [%s/%d/%d]",
+				tt.wantOpID, tt.wantParams, tt.wantResponses, op.OperationID, len(op.Parameters), op.Responses.Len())
+		}
+	}
+}
+
+const mergePathsTestSpec3 = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Three", "version": "1.0.0"},
+  "paths": {
+    "/users/{userId}": {
+      "parameters": [{"$ref": "#/components/parameters/UserId"}],
+      "put": {
+        "operationId": "updateUser",
+        "responses": {"204": {"description": "No Content"}}
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "UserId": {"name": "userId", "in": "path", "required": true, "schema": {"type": "string"}}
+    }
+  }
+}`
+
+func TestMergePathsMoreParameterRef(t *testing.T) {
+	spec1, err := Parse([]byte(mergePathsTestSpec1))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	spec3, err := Parse([]byte(mergePathsTestSpec3))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	spec, _, err := MergePathsMore(spec1, spec3, "spec3", nil)
+	if err != nil {
+		t.Fatalf("openapi3.MergePathsMore() error [%v]", err)
+	}
+	pathItem := spec.Paths.Value("/users/{id}")
+	if pathItem == nil || pathItem.Put == nil || len(pathItem.Put.Parameters) != 1 {
+		t.Fatalf("openapi3.MergePathsMore() operations not merged into [/users/{id}]")
+	}
+	if paramRef := pathItem.Put.Parameters[0]; paramRef.Ref != "" || paramRef.Value.Name != "id" {
+		t.Errorf("openapi3.MergePathsMore() path parameter reference mismatch: want inline [id], got [%s %s]", paramRef.Ref, paramRef.Value.Name)
+	}
+	if name := spec3.Components.Parameters["UserId"].Value.Name; name != "userId" {
+		t.Errorf("openapi3.MergePathsMore() component parameter mismatch: want [userId], got [%s]", name)
+	}
+}
+
+func TestMergeDirectoryMore(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.json": mergePathsTestSpec1,
+		"b.json": mergePathsTestSpec2}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatalf("os.WriteFile() error [%v]", err)
+		}
+	}
+	spec, num, report, err := MergeDirectoryMore(dir, &MergeOptions{
+		OperationCollision: OperationCollisionKeepFirst,
+		SourceFile:         true,
+		SourceFileHash:     true})
+	if err != nil {
+		t.Fatalf("openapi3.MergeDirectoryMore() error [%v]", err)
+	} else if num != 2 {
+		t.Errorf("openapi3.MergeDirectoryMore() file count mismatch: want [2], got [%d]", num)
+	}
+	fileA, fileB := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")
+	pathItem := spec.Paths.Value("/users/{id}")
+	if pathItem == nil {
+		t.Fatalf("openapi3.MergeDirectoryMore() missing path [/users/{id}]")
+	}
+	tests := []struct {
+		ext      map[string]any
+		wantFile string
+		wantHash string
+	}{
+		{pathItem.Extensions, fileA, GitBlobHash([]byte(mergePathsTestSpec1))},
+		{pathItem.Get.Extensions, fileA, GitBlobHash([]byte(mergePathsTestSpec1))},
+		{pathItem.Delete.Extensions, fileB, GitBlobHash([]byte(mergePathsTestSpec2))},
+	}
+	for _, tt := range tests {
+		if tt.ext[XSourceFile] != tt.wantFile || tt.ext[XSourceHash] != tt.wantHash {
+			t.Errorf("openapi3.MergeDirectoryMore() provenance mismatch: want [%s %s], got [%v %v]",
+				tt.wantFile, tt.wantHash, tt.ext[XSourceFile], tt.ext[XSourceHash])
+		}
+	}
+	want := []MergeCollision{
+		{Kind: "paths", Name: "/users/{id}", NameExtra: "/users/{userId}", SpecExtraNote: fileB, Resolution: MergeResolutionMerge},
+		{Kind: "operations", Name: "GET /users/{id}", SpecExtraNote: fileB, Resolution: MergeResolutionKeepFirst},
+	}
+	if len(report.Collisions) != len(want) {
+		t.Fatalf("openapi3.MergeDirectoryMore() report mismatch: want [%v], got [%v]", want, report.Collisions)
+	}
+	for i, c := range want {
+		if report.Collisions[i] != c {
+			t.Errorf("openapi3.MergeDirectoryMore() collision mismatch: want [%v], got [%v]", c, report.Collisions[i])
+		}
+	}
+}
+
+func TestGitBlobHash(t *testing.T) {
+	if got := GitBlobHash([]byte{}); got != "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391" {
+		t.Errorf("openapi3.GitBlobHash() mismatch: want [e69de29bb2d1d6434b8b29ae775ad8c2e48c5391], got [%s]", got)
+	}
+}
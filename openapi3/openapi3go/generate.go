@@ -0,0 +1,461 @@
+package openapi3go
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/mogo/encoding/jsonpointer"
+	"github.com/grokify/mogo/type/stringsutil"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const PackageNameDefault = "api"
+
+// Options configures Go code generation.
+type Options struct {
+	// PackageName is the package clause of the generated file. Defaults to `api`.
+	PackageName string
+}
+
+// Generate returns Go source for the spec's `components.schemas`. Objects
+// become structs with `json` tags, optional and nullable properties become
+// pointers, string enums become typed strings with constants and `oneOf` and
+// `anyOf` become wrapper structs with one pointer field per variant. Inline
+// schemas are named like `SchemasFlatten` names them, e.g. the `address`
+// property of `User` becomes `UserAddress`.
+func Generate(spec *openapi3.Spec, opts *Options) ([]byte, error) {
+	if spec == nil {
+		return nil, openapi3.ErrSpecNotSet
+	}
+	g := &generator{
+		decls:   map[string]string{},
+		schemas: map[string]string{},
+		enums:   map[string][]any{},
+		imports: map[string]bool{}}
+	if opts != nil {
+		g.opts = *opts
+	}
+	if g.opts.PackageName == "" {
+		g.opts.PackageName = PackageNameDefault
+	}
+	if spec.Components != nil {
+		names := []string{}
+		for name := range spec.Components.Schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := g.namedType(name, spec.Components.Schemas[name]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return g.source()
+}
+
+// WriteFile writes the Go source for the spec's `components.schemas`.
+func WriteFile(filename string, spec *openapi3.Spec, opts *Options, perm os.FileMode) error {
+	src, err := Generate(spec, opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, src, perm)
+}
+
+type generator struct {
+	opts    Options
+	order   []string
+	decls   map[string]string // Go type name to declaration
+	schemas map[string]string // Go type name to schema name, for collisions
+	enums   map[string][]any  // Go type name to enum values
+	imports map[string]bool
+	// decodeStrict is set when the `decodeStrict` helper used by unions
+	// without a discriminator is needed.
+	decodeStrict bool
+}
+
+func (g *generator) source() ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("// Code generated by spectrum openapi3go. DO NOT EDIT.\n\n")
+	sb.WriteString("package " + g.opts.PackageName + "\n\n")
+	if len(g.imports) > 0 {
+		imps := []string{}
+		for imp := range g.imports {
+			imps = append(imps, strconv.Quote(imp))
+		}
+		sort.Strings(imps)
+		sb.WriteString("import (\n" + strings.Join(imps, "\n") + "\n)\n\n")
+	}
+	// Enum constants are named once all type names are known so that they
+	// can be de-duplicated against types and other enums' constants.
+	idents := map[string]bool{}
+	for goName := range g.schemas {
+		idents[goName] = true
+	}
+	for _, name := range g.order {
+		sb.WriteString(g.decls[name])
+		if values, ok := g.enums[name]; ok {
+			sb.WriteString(enumDecl(name, values, idents))
+		}
+		sb.WriteString("\n")
+	}
+	if g.decodeStrict {
+		sb.WriteString("func decodeStrict(data []byte, v any) error {\n")
+		sb.WriteString("\tdec := json.NewDecoder(bytes.NewReader(data))\n\tdec.DisallowUnknownFields()\n\treturn dec.Decode(v)\n}\n")
+	}
+	src, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return []byte(sb.String()), fmt.Errorf("generated source invalid: %w", err)
+	}
+	return src, nil
+}
+
+// reserve registers a Go type name for a schema name and returns false if
+// it was already generated.
+func (g *generator) reserve(goName, schemaName string) (bool, error) {
+	if existing, ok := g.schemas[goName]; ok {
+		if existing != schemaName {
+			return false, fmt.Errorf("go type name collision [%s] for schemas [%s] and [%s]", goName, existing, schemaName)
+		}
+		return false, nil
+	}
+	g.schemas[goName] = schemaName
+	g.order = append(g.order, goName)
+	return true, nil
+}
+
+// namedType generates a type declaration for a named schema.
+func (g *generator) namedType(name string, schRef *oas3.SchemaRef) error {
+	goName := GoName(name)
+	if ok, err := g.reserve(goName, name); err != nil || !ok {
+		return err
+	}
+	var sch *oas3.Schema
+	if schRef != nil {
+		sch = schRef.Value
+	}
+	if sch == nil || schRef.Ref != "" {
+		typ, err := g.goType(name, "", schRef)
+		if err != nil {
+			return err
+		}
+		g.decls[goName] = fmt.Sprintf("type %s = %s\n", goName, typ)
+		return nil
+	}
+	doc := docComment("", goName, sch.Description, sch.Deprecated)
+	var decl string
+	var err error
+	switch {
+	case len(sch.OneOf) > 0:
+		decl, err = g.unionDecl(name, goName, sch.OneOf, sch.Discriminator)
+	case len(sch.AnyOf) > 0:
+		decl, err = g.unionDecl(name, goName, sch.AnyOf, sch.Discriminator)
+	case sch.Type == openapi3.TypeString && len(sch.Enum) > 0:
+		g.enums[goName] = sch.Enum
+	case isStruct(sch):
+		decl, err = g.structDecl(name, goName, sch)
+	default:
+		var typ string
+		typ, err = g.goTypeValue(name, "", sch)
+		decl = fmt.Sprintf("type %s %s\n", goName, typ)
+	}
+	if err != nil {
+		return err
+	}
+	g.decls[goName] = doc + decl
+	return nil
+}
+
+// isStruct returns true for object schemas that are not maps.
+func isStruct(sch *oas3.Schema) bool {
+	return len(sch.Properties) > 0 || len(sch.AllOf) > 0
+}
+
+// goType returns the Go type expression for a property schema. Inline
+// objects, enums and unions are declared as named types.
+func (g *generator) goType(parentName, propName string, schRef *oas3.SchemaRef) (string, error) {
+	if schRef == nil {
+		return "any", nil
+	}
+	if ref := strings.TrimSpace(schRef.Ref); ref != "" {
+		return GoName(refSchemaName(ref)), nil
+	}
+	if schRef.Value == nil {
+		return "any", nil
+	}
+	return g.goTypeValue(parentName, propName, schRef.Value)
+}
+
+func (g *generator) goTypeValue(parentName, propName string, sch *oas3.Schema) (string, error) {
+	if propName != "" && (len(sch.OneOf) > 0 || len(sch.AnyOf) > 0 || isStruct(sch) ||
+		(sch.Type == openapi3.TypeString && len(sch.Enum) > 0)) {
+		name := inlineName(parentName, propName)
+		if err := g.namedType(name, oas3.NewSchemaRef("", sch)); err != nil {
+			return "", err
+		}
+		return GoName(name), nil
+	}
+	switch sch.Type {
+	case openapi3.TypeArray:
+		itemsName := propName
+		if itemsName == "" {
+			// top-level arrays name inline item types, e.g. `PetsItem`.
+			itemsName = "item"
+		}
+		items, err := g.goType(parentName, itemsName, sch.Items)
+		return "[]" + items, err
+	case openapi3.TypeObject, "":
+		if aps := sch.AdditionalProperties.Schema; aps != nil {
+			vals, err := g.goType(parentName, propName, aps)
+			return "map[string]" + vals, err
+		} else if sch.Type == openapi3.TypeObject {
+			return "map[string]any", nil
+		}
+		return "any", nil
+	case openapi3.TypeBoolean:
+		return "bool", nil
+	case openapi3.TypeInteger:
+		if sch.Format == openapi3.FormatInt32 {
+			return "int32", nil
+		}
+		return "int64", nil
+	case openapi3.TypeNumber:
+		if sch.Format == "float" {
+			return "float32", nil
+		}
+		return "float64", nil
+	case openapi3.TypeString:
+		switch sch.Format {
+		case openapi3.FormatDateTime:
+			g.imports["time"] = true
+			return "time.Time", nil
+		case "byte":
+			return "[]byte", nil
+		}
+		return "string", nil
+	}
+	return "any", nil
+}
+
+// structDecl generates a struct. `allOf` references are embedded, which
+// `encoding/json` flattens, and inline `allOf` properties are merged.
+func (g *generator) structDecl(name, goName string, sch *oas3.Schema) (string, error) {
+	props := oas3.Schemas{}
+	required := map[string]bool{}
+	embeds := []string{}
+	addProps := func(s *oas3.Schema) {
+		for propName, propRef := range s.Properties {
+			props[propName] = propRef
+		}
+		for _, req := range s.Required {
+			required[req] = true
+		}
+	}
+	for _, item := range sch.AllOf {
+		if item == nil {
+			continue
+		} else if ref := strings.TrimSpace(item.Ref); ref != "" {
+			embeds = append(embeds, GoName(refSchemaName(ref)))
+		} else if item.Value != nil {
+			addProps(item.Value)
+		}
+	}
+	addProps(sch)
+
+	var sb strings.Builder
+	sb.WriteString("type " + goName + " struct {\n")
+	for _, embed := range embeds {
+		sb.WriteString("\t" + embed + "\n")
+	}
+	propNames := []string{}
+	for propName := range props {
+		propNames = append(propNames, propName)
+	}
+	sort.Strings(propNames)
+	fieldNames := map[string]bool{}
+	for _, embed := range embeds {
+		fieldNames[embed] = true
+	}
+	for _, propName := range propNames {
+		propRef := props[propName]
+		fieldName := GoName(propName)
+		for i := 2; fieldNames[fieldName]; i++ {
+			fieldName = GoName(propName) + strconv.Itoa(i)
+		}
+		fieldNames[fieldName] = true
+		typ, err := g.goType(name, propName, propRef)
+		if err != nil {
+			return "", err
+		}
+		nullable := propRef != nil && propRef.Value != nil && propRef.Value.Nullable
+		if (!required[propName] || nullable) && !strings.HasPrefix(typ, "[]") &&
+			!strings.HasPrefix(typ, "map[") && typ != "any" {
+			typ = "*" + typ
+		}
+		tag := propName
+		if !required[propName] {
+			tag += ",omitempty"
+		}
+		if propRef != nil && propRef.Ref == "" && propRef.Value != nil {
+			sb.WriteString(docComment("\t", fieldName, propRef.Value.Description, propRef.Value.Deprecated))
+		}
+		sb.WriteString(fmt.Sprintf("\t%s %s `json:%s`\n", fieldName, typ, strconv.Quote(tag)))
+	}
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
+
+// enumDecl generates a typed string with one constant per value. Constant
+// names in `idents` are suffixed and new names are added to it.
+func enumDecl(goName string, values []any, idents map[string]bool) string {
+	var sb strings.Builder
+	sb.WriteString("type " + goName + " string\n\nconst (\n")
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		constName := goName + GoName(s)
+		for i := 2; idents[constName]; i++ {
+			constName = goName + GoName(s) + strconv.Itoa(i)
+		}
+		idents[constName] = true
+		sb.WriteString(fmt.Sprintf("\t%s %s = %s\n", constName, goName, strconv.Quote(s)))
+	}
+	sb.WriteString(")\n")
+	return sb.String()
+}
+
+type unionVariant struct {
+	field  string
+	typ    string
+	values []string // discriminator values
+}
+
+// unionDecl generates a wrapper struct for `oneOf` and `anyOf` with one
+// pointer field per variant and JSON methods that encode the variant that is
+// set and decode by discriminator or by the first variant that matches.
+func (g *generator) unionDecl(name, goName string, refs oas3.SchemaRefs, disc *oas3.Discriminator) (string, error) {
+	variants := []unionVariant{}
+	fields := map[string]bool{}
+	for i, ref := range refs {
+		typ, err := g.goType(name, "Option"+strconv.Itoa(i+1), ref)
+		if err != nil {
+			return "", err
+		}
+		v := unionVariant{field: GoName(strings.TrimPrefix(typ, "[]")), typ: typ}
+		if strings.HasPrefix(typ, "[]") {
+			v.field += "List"
+		}
+		base := v.field
+		for j := 2; fields[v.field]; j++ {
+			v.field = base + strconv.Itoa(j)
+		}
+		fields[v.field] = true
+		if disc != nil && ref != nil && ref.Ref != "" {
+			for mapVal, mapRef := range disc.Mapping {
+				if refSchemaName(mapRef) == refSchemaName(ref.Ref) {
+					v.values = append(v.values, mapVal)
+				}
+			}
+			if len(v.values) == 0 {
+				v.values = append(v.values, refSchemaName(ref.Ref))
+			}
+			sort.Strings(v.values)
+		}
+		variants = append(variants, v)
+	}
+	g.imports["encoding/json"] = true
+	g.imports["fmt"] = true
+
+	var sb strings.Builder
+	sb.WriteString("type " + goName + " struct {\n")
+	for _, v := range variants {
+		sb.WriteString(fmt.Sprintf("\t%s *%s\n", v.field, v.typ))
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("// MarshalJSON encodes the variant that is set.\n")
+	sb.WriteString(fmt.Sprintf("func (v %s) MarshalJSON() ([]byte, error) {\n\tswitch {\n", goName))
+	for _, vr := range variants {
+		sb.WriteString(fmt.Sprintf("\tcase v.%s != nil:\n\t\treturn json.Marshal(v.%s)\n", vr.field, vr.field))
+	}
+	sb.WriteString("\t}\n\treturn []byte(\"null\"), nil\n}\n\n")
+
+	if disc != nil && disc.PropertyName != "" {
+		sb.WriteString(fmt.Sprintf("// UnmarshalJSON decodes the variant named by the `%s` property.\n", disc.PropertyName))
+		sb.WriteString(fmt.Sprintf("func (v *%s) UnmarshalJSON(data []byte) error {\n", goName))
+		sb.WriteString(fmt.Sprintf("\tvar d struct {\n\t\tValue string `json:%s`\n\t}\n", strconv.Quote(disc.PropertyName)))
+		sb.WriteString("\tif err := json.Unmarshal(data, &d); err != nil {\n\t\treturn err\n\t}\n\tswitch d.Value {\n")
+		for _, vr := range variants {
+			if len(vr.values) == 0 {
+				continue
+			}
+			quoted := []string{}
+			for _, val := range vr.values {
+				quoted = append(quoted, strconv.Quote(val))
+			}
+			sb.WriteString(fmt.Sprintf("\tcase %s:\n\t\tv.%s = new(%s)\n\t\treturn json.Unmarshal(data, v.%s)\n",
+				strings.Join(quoted, ", "), vr.field, vr.typ, vr.field))
+		}
+		sb.WriteString(fmt.Sprintf("\t}\n\treturn fmt.Errorf(\"%s: unknown %s [%%s]\", d.Value)\n}\n", goName, disc.PropertyName))
+		return sb.String(), nil
+	}
+
+	g.imports["bytes"] = true
+	sb.WriteString("// UnmarshalJSON decodes the first variant that matches without unknown fields.\n")
+	sb.WriteString(fmt.Sprintf("func (v *%s) UnmarshalJSON(data []byte) error {\n", goName))
+	for _, vr := range variants {
+		sb.WriteString(fmt.Sprintf("\tif x := new(%s); decodeStrict(data, x) == nil {\n\t\t*v = %s{%s: x}\n\t\treturn nil\n\t}\n",
+			vr.typ, goName, vr.field))
+	}
+	sb.WriteString(fmt.Sprintf("\treturn fmt.Errorf(\"%s: no variant matches\")\n}\n", goName))
+	g.decodeStrict = true
+	return sb.String(), nil
+}
+
+// docComment returns a Go doc comment from a description. The comment starts
+// with the identifier as Go convention expects.
+func docComment(indent, ident, desc string, deprecated bool) string {
+	desc = strings.TrimSpace(desc)
+	if desc == "" && !deprecated {
+		return ""
+	}
+	lines := []string{}
+	if desc != "" {
+		if !strings.HasPrefix(desc, ident+" ") {
+			// Lower case the first word unless it is an initialism, e.g. `API`.
+			if len(desc) < 2 || !unicode.IsUpper(rune(desc[1])) {
+				desc = stringsutil.ToLowerFirst(desc)
+			}
+			desc = ident + " " + desc
+		}
+		lines = strings.Split(desc, "\n")
+	}
+	if deprecated {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "Deprecated: "+ident+" is deprecated.")
+	}
+	var sb strings.Builder
+	for _, line := range lines {
+		sb.WriteString(strings.TrimRight(indent+"// "+line, " ") + "\n")
+	}
+	return sb.String()
+}
+
+// refSchemaName returns the component name of a local schema reference.
+func refSchemaName(ref string) string {
+	ref = strings.TrimPrefix(ref, openapi3.PointerComponentsSchemas+"/")
+	if idx := strings.LastIndex(ref, "/"); idx > -1 {
+		ref = ref[idx+1:]
+	}
+	return jsonpointer.PropertyNameUnescape(ref)
+}
@@ -0,0 +1,111 @@
+package openapi3go
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const generateTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {
+        "oneOf": [{"$ref": "#/components/schemas/Cat"}, {"$ref": "#/components/schemas/Dog"}],
+        "discriminator": {"propertyName": "petType", "mapping": {"cat": "#/components/schemas/Cat"}}
+      },
+      "Cat": {
+        "description": "A cat.",
+        "allOf": [{"$ref": "#/components/schemas/PetBase"}, {"type": "object", "properties": {"lives": {"type": "integer", "format": "int32"}}}]
+      },
+      "Dog": {"allOf": [{"$ref": "#/components/schemas/PetBase"}]},
+      "PetBase": {
+        "type": "object",
+        "required": ["id", "petType", "status"],
+        "properties": {
+          "id": {"type": "string", "format": "uuid", "description": "Unique ID."},
+          "petType": {"type": "string"},
+          "status": {"type": "string", "enum": ["available", "sold-out"]},
+          "nickname": {"type": "string", "nullable": true},
+          "birthDate": {"type": "string", "format": "date-time"},
+          "tags": {"type": "array", "items": {"type": "object", "properties": {"name": {"type": "string"}}}},
+          "owner": {"type": "object", "properties": {"userId": {"type": "integer"}}},
+          "attributes": {"type": "object", "additionalProperties": {"type": "string"}},
+          "legacyCode": {"type": "string", "deprecated": true}
+        }
+      },
+      "IDOrName": {"oneOf": [{"type": "string"}, {"type": "integer"}]},
+      "CatOrCats": {"anyOf": [
+        {"type": "array", "items": {"$ref": "#/components/schemas/Cat"}, "maxItems": 1},
+        {"type": "array", "items": {"$ref": "#/components/schemas/Cat"}}]},
+      "Pets": {"type": "array", "items": {"type": "object", "properties": {"name": {"type": "string"}}}},
+      "Status": {"type": "string", "enum": ["active", "closed"]},
+      "StatusActive": {"type": "object", "properties": {"since": {"type": "string"}}}
+    }
+  }
+}`
+
+func TestGenerate(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(generateTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	src, err := Generate(spec, &Options{PackageName: "pets"})
+	if err != nil {
+		t.Fatalf("openapi3go.Generate() error [%v]\n%s", err, string(src))
+	}
+	// Field alignment by gofmt is ignored.
+	out := regexp.MustCompile(` +`).ReplaceAllString(string(src), " ")
+	for _, want := range []string{
+		"package pets",
+		"// Cat a cat.\ntype Cat struct {\n\tPetBase\n\tLives *int32 `json:\"lives,omitempty\"`\n}",
+		"type PetBaseStatus string",
+		"PetBaseStatusSoldOut PetBaseStatus = \"sold-out\"",
+		"Status PetBaseStatus `json:\"status\"`",
+		"// ID unique ID.\n\tID string `json:\"id\"`",
+		"Nickname *string `json:\"nickname,omitempty\"`",
+		"BirthDate *time.Time `json:\"birthDate,omitempty\"`",
+		"Tags []PetBaseTags `json:\"tags,omitempty\"`",
+		"Owner *PetBaseOwner `json:\"owner,omitempty\"`",
+		"type PetBaseOwner struct {\n\tUserID *int64 `json:\"userId,omitempty\"`",
+		"Attributes map[string]string `json:\"attributes,omitempty\"`",
+		"// Deprecated: LegacyCode is deprecated.",
+		"type Pet struct {\n\tCat *Cat\n\tDog *Dog\n}",
+		"case \"cat\":\n\t\tv.Cat = new(Cat)",
+		"case \"Dog\":\n\t\tv.Dog = new(Dog)",
+		"type IDOrName struct {\n\tString *string\n\tInt64 *int64\n}",
+		"type CatOrCats struct {\n\tCatList *[]Cat\n\tCatList2 *[]Cat\n}",
+		"type Pets []PetsItem",
+		"type PetsItem struct {\n\tName *string `json:\"name,omitempty\"`\n}",
+		"StatusActive2 Status = \"active\"",
+		"StatusClosed Status = \"closed\"",
+		"type StatusActive struct {",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("openapi3go.Generate() mismatch: want [%s]\n%s", want, out)
+		}
+	}
+}
+
+var goNameTests = []struct {
+	v    string
+	want string
+}{
+	{"user_id", "UserID"},
+	{"userId", "UserID"},
+	{"billing-address", "BillingAddress"},
+	{"2fa", "N2fa"},
+	{"sold out", "SoldOut"},
+}
+
+func TestGoName(t *testing.T) {
+	for _, tt := range goNameTests {
+		if got := GoName(tt.v); got != tt.want {
+			t.Errorf("openapi3go.GoName(\"%s\") mismatch: want [%s], got [%s]", tt.v, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package openapi3go
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/grokify/mogo/type/stringsutil"
+)
+
+// initialisms are words written in upper case in Go identifiers.
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true, "HTML": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true,
+	"SSH": true, "TLS": true, "TTL": true, "UI": true, "URI": true, "URL": true,
+	"UTF8": true, "UUID": true, "XML": true}
+
+// GoName converts a schema, property or enum value name to an exported Go
+// identifier, e.g. `user_id` to `UserID` and `billing-address` to
+// `BillingAddress`.
+func GoName(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var sb strings.Builder
+	for _, part := range splitCamel(parts) {
+		if up := strings.ToUpper(part); initialisms[up] {
+			sb.WriteString(up)
+		} else {
+			sb.WriteString(stringsutil.ToUpperFirst(part, false))
+		}
+	}
+	name := sb.String()
+	if name == "" {
+		return "Empty"
+	} else if unicode.IsDigit(rune(name[0])) {
+		return "N" + name
+	}
+	return name
+}
+
+// inlineName returns the component name `SchemasFlatten` uses for an inline
+// schema of a property, e.g. `User` and `address` gives `UserAddress`.
+func inlineName(parentName, propName string) string {
+	return parentName + stringsutil.ToUpperFirst(propName, false)
+}
+
+// splitCamel splits words at lower to upper case transitions so that
+// initialisms in camel case names are detected, e.g. `userId`.
+func splitCamel(parts []string) []string {
+	words := []string{}
+	for _, part := range parts {
+		last := 0
+		runes := []rune(part)
+		for i := 1; i < len(runes); i++ {
+			if unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i]) {
+				words = append(words, string(runes[last:i]))
+				last = i
+			}
+		}
+		words = append(words, string(runes[last:]))
+	}
+	return words
+}
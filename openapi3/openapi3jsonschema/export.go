@@ -0,0 +1,280 @@
+package openapi3jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grokify/mogo/encoding/jsonpointer"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	// Draft202012 is the `$schema` URI of JSON Schema draft 2020-12.
+	Draft202012 = "https://json-schema.org/draft/2020-12/schema"
+
+	// RefModeFile rewrites component references to relative file references,
+	// e.g. `#/components/schemas/Pet` to `Pet.schema.json`.
+	RefModeFile = "file"
+	// RefModeDefs embeds referenced components under `$defs` so each file is
+	// self-contained, e.g. `#/components/schemas/Pet` to `#/$defs/Pet`.
+	RefModeDefs = "defs"
+
+	FileExtensionDefault = ".schema.json"
+)
+
+// Options configures JSON Schema export.
+type Options struct {
+	// RefMode is `RefModeFile` (default) or `RefModeDefs`.
+	RefMode string
+	// FileExtension is appended to component names for file names and file
+	// references. Defaults to `.schema.json`.
+	FileExtension string
+	// BaseURI, when set, is used to add an `$id` of `BaseURI` + file name.
+	BaseURI string
+}
+
+func (opts *Options) fileExtension() string {
+	if opts == nil || opts.FileExtension == "" {
+		return FileExtensionDefault
+	}
+	return opts.FileExtension
+}
+
+// Filename returns the file name of a component schema.
+func (opts *Options) Filename(name string) string {
+	return name + opts.fileExtension()
+}
+
+// Export returns a JSON Schema draft 2020-12 document for every component
+// schema, keyed by file name.
+func Export(spec *openapi3.Spec, opts *Options) (map[string][]byte, error) {
+	if spec == nil {
+		return nil, openapi3.ErrSpecNotSet
+	}
+	files := map[string][]byte{}
+	if spec.Components == nil {
+		return files, nil
+	}
+	names := []string{}
+	for name := range spec.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		doc, err := Convert(spec, name, opts)
+		if err != nil {
+			return nil, err
+		}
+		bytes, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files[opts.Filename(name)] = append(bytes, '\n')
+	}
+	return files, nil
+}
+
+// WriteFiles writes the output of `Export()` to a directory.
+func WriteFiles(dir string, spec *openapi3.Spec, opts *Options, perm os.FileMode) error {
+	files, err := Export(spec, opts)
+	if err != nil {
+		return err
+	}
+	for filename, bytes := range files {
+		if err := os.WriteFile(filepath.Join(dir, filename), bytes, perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Convert returns a JSON Schema draft 2020-12 document for one component
+// schema. OpenAPI 3.0 keywords are translated: `nullable` adds `null` to
+// `type`, `example` becomes `examples`, boolean `exclusiveMinimum` and
+// `exclusiveMaximum` become numbers and `discriminator` and `xml` are
+// removed. Component references are rewritten per `Options.RefMode`.
+func Convert(spec *openapi3.Spec, name string, opts *Options) (map[string]any, error) {
+	if spec == nil {
+		return nil, openapi3.ErrSpecNotSet
+	} else if opts == nil {
+		opts = &Options{}
+	}
+	switch opts.RefMode {
+	case "", RefModeFile, RefModeDefs:
+	default:
+		return nil, fmt.Errorf("unknown ref mode [%s]", opts.RefMode)
+	}
+	c := &converter{spec: spec, opts: opts, root: name, defs: map[string]any{}}
+	doc, err := c.component(name)
+	if err != nil {
+		return nil, err
+	}
+	// Referenced components are added to `$defs` as they are found.
+	for i := 0; i < len(c.pending); i++ {
+		defName := c.pending[i]
+		def, err := c.component(defName)
+		if err != nil {
+			return nil, err
+		}
+		c.defs[defName] = def
+	}
+	if len(c.defs) > 0 {
+		doc["$defs"] = c.defs
+	}
+	doc["$schema"] = Draft202012
+	if opts.BaseURI != "" {
+		doc["$id"] = opts.BaseURI + opts.Filename(name)
+	}
+	return doc, nil
+}
+
+type converter struct {
+	spec    *openapi3.Spec
+	opts    *Options
+	root    string
+	defs    map[string]any
+	pending []string
+}
+
+func (c *converter) component(name string) (map[string]any, error) {
+	if c.spec.Components == nil {
+		return nil, fmt.Errorf("schema not found [%s]", name)
+	}
+	schRef, ok := c.spec.Components.Schemas[name]
+	if !ok || schRef == nil {
+		return nil, fmt.Errorf("schema not found [%s]", name)
+	}
+	bytes, err := json.Marshal(schRef)
+	if err != nil {
+		return nil, err
+	}
+	sch := map[string]any{}
+	if err := json.Unmarshal(bytes, &sch); err != nil {
+		return nil, err
+	}
+	return c.schema(sch), nil
+}
+
+// schemaKeys, schemaMapKeys and schemaListKeys are keywords holding
+// subschemas. Other keywords such as `enum` and `example` hold instance values
+// and are not converted.
+var (
+	schemaKeys     = []string{"items", "not", "additionalProperties"}
+	schemaMapKeys  = []string{"properties"}
+	schemaListKeys = []string{"allOf", "anyOf", "oneOf"}
+)
+
+func (c *converter) schema(sch map[string]any) map[string]any {
+	if ref, ok := sch["$ref"].(string); ok {
+		sch["$ref"] = c.ref(ref)
+		return sch
+	}
+	for _, key := range schemaKeys {
+		if sub, ok := sch[key].(map[string]any); ok {
+			sch[key] = c.schema(sub)
+		}
+	}
+	for _, key := range schemaMapKeys {
+		if subs, ok := sch[key].(map[string]any); ok {
+			for k, v := range subs {
+				if sub, ok := v.(map[string]any); ok {
+					subs[k] = c.schema(sub)
+				}
+			}
+		}
+	}
+	for _, key := range schemaListKeys {
+		if subs, ok := sch[key].([]any); ok {
+			for i, v := range subs {
+				if sub, ok := v.(map[string]any); ok {
+					subs[i] = c.schema(sub)
+				}
+			}
+		}
+	}
+	if ex, ok := sch["example"]; ok {
+		sch["examples"] = []any{ex}
+		delete(sch, "example")
+	}
+	exclusiveBound(sch, "exclusiveMinimum", "minimum")
+	exclusiveBound(sch, "exclusiveMaximum", "maximum")
+	delete(sch, "discriminator")
+	delete(sch, "xml")
+	if nullable, ok := sch["nullable"].(bool); ok {
+		delete(sch, "nullable")
+		if nullable {
+			return nullableSchema(sch)
+		}
+	}
+	return sch
+}
+
+// exclusiveBound converts a boolean OpenAPI 3.0 exclusive bound to the
+// numeric form, e.g. `minimum: 1, exclusiveMinimum: true` to
+// `exclusiveMinimum: 1`.
+func exclusiveBound(sch map[string]any, exclusiveKey, boundKey string) {
+	exclusive, ok := sch[exclusiveKey].(bool)
+	if !ok {
+		return
+	}
+	delete(sch, exclusiveKey)
+	if bound, ok := sch[boundKey]; ok && exclusive {
+		sch[exclusiveKey] = bound
+		delete(sch, boundKey)
+	}
+}
+
+// nullableSchema adds `null` to `type` and `enum`. Schemas without a `type`,
+// such as composed schemas, are wrapped as `anyOf` with a `null` type.
+func nullableSchema(sch map[string]any) map[string]any {
+	typ, ok := sch["type"].(string)
+	if !ok || typ == "" {
+		return map[string]any{
+			"anyOf": []any{sch, map[string]any{"type": "null"}}}
+	}
+	sch["type"] = []any{typ, "null"}
+	if enum, ok := sch["enum"].([]any); ok {
+		hasNull := false
+		for _, v := range enum {
+			if v == nil {
+				hasNull = true
+				break
+			}
+		}
+		if !hasNull {
+			sch["enum"] = append(enum, nil)
+		}
+	}
+	return sch
+}
+
+// ref rewrites a component schema reference. Other references are returned
+// unchanged.
+func (c *converter) ref(ref string) string {
+	prefix := openapi3.PointerComponentsSchemas + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return ref
+	}
+	name, rest, _ := strings.Cut(strings.TrimPrefix(ref, prefix), "/")
+	name = jsonpointer.PropertyNameUnescape(name)
+	if rest != "" {
+		rest = "/" + rest
+	}
+	if name == c.root {
+		return "#" + rest
+	} else if c.opts.RefMode == RefModeDefs {
+		if _, ok := c.defs[name]; !ok {
+			c.defs[name] = nil
+			c.pending = append(c.pending, name)
+		}
+		return "#/$defs/" + jsonpointer.PropertyNameEscape(name) + rest
+	}
+	if rest != "" {
+		rest = "#" + rest
+	}
+	return c.opts.Filename(name) + rest
+}
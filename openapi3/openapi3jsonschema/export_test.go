@@ -0,0 +1,126 @@
+package openapi3jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const exportTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "name": {"type": "string", "example": "Rex"},
+          "nickname": {"type": "string", "nullable": true},
+          "status": {"type": "string", "enum": ["available", "sold"], "nullable": true},
+          "weight": {"type": "number", "minimum": 0, "exclusiveMinimum": true},
+          "owner": {"$ref": "#/components/schemas/Owner"},
+          "parent": {"$ref": "#/components/schemas/Pet"},
+          "friend": {"allOf": [{"$ref": "#/components/schemas/Owner"}], "nullable": true}
+        }
+      },
+      "Owner": {
+        "type": "object",
+        "properties": {
+          "address": {"$ref": "#/components/schemas/Address"}
+        }
+      },
+      "Address": {
+        "type": "object",
+        "properties": {"city": {"type": "string"}}
+      }
+    }
+  }
+}`
+
+func convertTestSchema(t *testing.T, name string, opts *Options) map[string]any {
+	spec, err := openapi3.Parse([]byte(exportTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	doc, err := Convert(spec, name, opts)
+	if err != nil {
+		t.Fatalf("openapi3jsonschema.Convert() error [%v]", err)
+	}
+	// Round trip to compare with JSON values.
+	bytes, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal() error [%v]", err)
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(bytes, &out); err != nil {
+		t.Fatalf("json.Unmarshal() error [%v]", err)
+	}
+	return out
+}
+
+func jsonValue(t *testing.T, s string) any {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("json.Unmarshal() error [%v]", err)
+	}
+	return v
+}
+
+func TestConvertFile(t *testing.T) {
+	doc := convertTestSchema(t, "Pet", &Options{BaseURI: "https://example.com/schemas/"})
+	props := doc["properties"].(map[string]any)
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{doc["$schema"], `"https://json-schema.org/draft/2020-12/schema"`},
+		{doc["$id"], `"https://example.com/schemas/Pet.schema.json"`},
+		{props["name"], `{"type": "string", "examples": ["Rex"]}`},
+		{props["nickname"], `{"type": ["string", "null"]}`},
+		{props["status"], `{"type": ["string", "null"], "enum": ["available", "sold", null]}`},
+		{props["weight"], `{"type": "number", "exclusiveMinimum": 0}`},
+		{props["owner"], `{"$ref": "Owner.schema.json"}`},
+		{props["parent"], `{"$ref": "#"}`},
+		{props["friend"], `{"anyOf": [{"allOf": [{"$ref": "Owner.schema.json"}]}, {"type": "null"}]}`},
+		{doc["$defs"], `null`},
+	}
+	for i, tt := range tests {
+		if want := jsonValue(t, tt.want); !reflect.DeepEqual(tt.value, want) {
+			t.Errorf("openapi3jsonschema.Convert() mismatch (%d): want [%v], got [%v]", i, want, tt.value)
+		}
+	}
+}
+
+func TestConvertDefs(t *testing.T) {
+	doc := convertTestSchema(t, "Pet", &Options{RefMode: RefModeDefs})
+	props := doc["properties"].(map[string]any)
+	if want := jsonValue(t, `{"$ref": "#/$defs/Owner"}`); !reflect.DeepEqual(props["owner"], want) {
+		t.Errorf("openapi3jsonschema.Convert() mismatch: want [%v], got [%v]", want, props["owner"])
+	}
+	want := jsonValue(t, `{
+		"Owner": {"type": "object", "properties": {"address": {"$ref": "#/$defs/Address"}}},
+		"Address": {"type": "object", "properties": {"city": {"type": "string"}}}}`)
+	if !reflect.DeepEqual(doc["$defs"], want) {
+		t.Errorf("openapi3jsonschema.Convert() $defs mismatch: want [%v], got [%v]", want, doc["$defs"])
+	}
+}
+
+func TestExport(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(exportTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	files, err := Export(spec, nil)
+	if err != nil {
+		t.Fatalf("openapi3jsonschema.Export() error [%v]", err)
+	}
+	for _, filename := range []string{"Address.schema.json", "Owner.schema.json", "Pet.schema.json"} {
+		if _, ok := files[filename]; !ok {
+			t.Errorf("openapi3jsonschema.Export() missing file [%s]", filename)
+		}
+	}
+}
@@ -0,0 +1,451 @@
+package openapi3proto
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	PackageDefault = "api"
+
+	wktDuration  = "google/protobuf/duration.proto"
+	wktStruct    = "google/protobuf/struct.proto"
+	wktTimestamp = "google/protobuf/timestamp.proto"
+	wktWrappers  = "google/protobuf/wrappers.proto"
+)
+
+// Options configures `.proto` export.
+type Options struct {
+	// Package is the proto package. Defaults to `api`.
+	Package string
+	// GoPackage sets the `go_package` option when not empty.
+	GoPackage string
+}
+
+// Export returns a proto3 `.proto` file for the spec's `components.schemas`.
+// Objects become messages, string enums become enums, arrays become repeated
+// fields, `oneOf` becomes a `oneof` and formats and nullable scalars map to
+// well-known types. Field numbers are assigned with `AssignFieldNumbers()`
+// on a copy of the spec, so call it and save the spec to keep numbers stable
+// across exports. Numbers no longer in use are written as `reserved`.
+func Export(spec *openapi3.Spec, opts *Options) ([]byte, error) {
+	if spec == nil {
+		return nil, openapi3.ErrSpecNotSet
+	}
+	spec, err := (&openapi3.SpecMore{Spec: spec}).Clone()
+	if err != nil {
+		return nil, err
+	}
+	if err := AssignFieldNumbers(spec); err != nil {
+		return nil, err
+	}
+	ex := &exporter{
+		spec:    spec,
+		fn:      newFieldNumbers(spec),
+		imports: map[string]bool{}}
+	if opts != nil {
+		ex.opts = *opts
+	}
+	if ex.opts.Package == "" {
+		ex.opts.Package = PackageDefault
+	}
+	var body strings.Builder
+	if spec.Components != nil {
+		for _, name := range sortedKeys(spec.Components.Schemas) {
+			decl, err := ex.topLevel(name, spec.Components.Schemas[name])
+			if err != nil {
+				return nil, fmt.Errorf("schema [%s]: %w", name, err)
+			}
+			body.WriteString("\n" + decl)
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString("// Code generated by spectrum openapi3proto. DO NOT EDIT.\n\n")
+	sb.WriteString("syntax = \"proto3\";\n\npackage " + ex.opts.Package + ";\n")
+	if ex.opts.GoPackage != "" {
+		sb.WriteString("\noption go_package = " + strconv.Quote(ex.opts.GoPackage) + ";\n")
+	}
+	if len(ex.imports) > 0 {
+		sb.WriteString("\n")
+		for _, imp := range sortedKeys(ex.imports) {
+			sb.WriteString("import " + strconv.Quote(imp) + ";\n")
+		}
+	}
+	sb.WriteString(body.String())
+	return []byte(sb.String()), nil
+}
+
+// WriteFile writes the `.proto` file for the spec's `components.schemas`.
+func WriteFile(filename string, spec *openapi3.Spec, opts *Options, perm os.FileMode) error {
+	src, err := Export(spec, opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, src, perm)
+}
+
+type exporter struct {
+	spec    *openapi3.Spec
+	opts    Options
+	fn      *fieldNumbers
+	imports map[string]bool
+}
+
+// topLevel converts a component schema. Schemas that are neither objects,
+// enums nor unions are wrapped in a message with a single `value` field.
+func (ex *exporter) topLevel(name string, schRef *oas3.SchemaRef) (string, error) {
+	msgName := MessageName(name)
+	if schRef == nil || schRef.Value == nil || schRef.Ref != "" {
+		return ex.wrapperMessage(msgName, schRef, "")
+	}
+	sch := schRef.Value
+	switch {
+	case isEnum(sch):
+		return enumDecl(msgName, sch, "")
+	case len(sch.OneOf) > 0 || len(sch.AnyOf) > 0:
+		return ex.oneofMessage(msgName, sch, "")
+	case isMessage(sch):
+		return ex.message(msgName, sch, "")
+	}
+	return ex.wrapperMessage(msgName, schRef, "")
+}
+
+func (ex *exporter) wrapperMessage(msgName string, schRef *oas3.SchemaRef, indent string) (string, error) {
+	f, err := ex.field(msgName, "value", schRef, indent+"  ")
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString(indent + "message " + msgName + " {\n")
+	sb.WriteString(f.nested)
+	sb.WriteString(fmt.Sprintf("%s  %s%s value = 1;\n", indent, f.label, f.typ))
+	sb.WriteString(indent + "}\n")
+	return sb.String(), nil
+}
+
+// message writes a message with fields ordered by field number. `allOf`
+// references are flattened into the message with their reserved numbers.
+func (ex *exporter) message(msgName string, sch *oas3.Schema, indent string) (string, error) {
+	nums, err := ex.fn.message(sch)
+	if err != nil {
+		return "", err
+	}
+	props := ex.properties(sch)
+	propNames := sortedKeys(props)
+	sort.SliceStable(propNames, func(i, j int) bool { return nums[propNames[i]] < nums[propNames[j]] })
+
+	var sb strings.Builder
+	sb.WriteString(docComment(indent, sch.Description))
+	sb.WriteString(indent + "message " + msgName + " {\n")
+	sb.WriteString(reservedDecl(indent+"  ", ex.fn.reserved[sch]))
+	fields := []string{}
+	for _, propName := range propNames {
+		propRef := props[propName]
+		fieldName := FieldName(propName)
+		f, err := ex.field(MessageName(propName), propName, propRef, indent+"  ")
+		if err != nil {
+			return "", fmt.Errorf("property [%s]: %w", propName, err)
+		}
+		sb.WriteString(f.nested)
+		line := ""
+		if propRef != nil && propRef.Ref == "" && propRef.Value != nil {
+			line += docComment(indent+"  ", propRef.Value.Description)
+		}
+		line += fmt.Sprintf("%s  %s%s %s = %d", indent, f.label, f.typ, fieldName, nums[propName])
+		opts := []string{}
+		if JSONName(fieldName) != propName {
+			opts = append(opts, "json_name = "+strconv.Quote(propName))
+		}
+		if propRef != nil && propRef.Value != nil && propRef.Value.Deprecated {
+			opts = append(opts, "deprecated = true")
+		}
+		if len(opts) > 0 {
+			line += " [" + strings.Join(opts, ", ") + "]"
+		}
+		fields = append(fields, line+";\n")
+	}
+	for _, line := range fields {
+		sb.WriteString(line)
+	}
+	sb.WriteString(indent + "}\n")
+	return sb.String(), nil
+}
+
+// properties returns the properties of a schema merged with `allOf` items.
+func (ex *exporter) properties(sch *oas3.Schema) oas3.Schemas {
+	props := oas3.Schemas{}
+	for _, item := range sch.AllOf {
+		if item == nil {
+			continue
+		} else if itemSch := ex.fn.resolve(item); itemSch != nil && itemSch != sch {
+			for k, v := range ex.properties(itemSch) {
+				props[k] = v
+			}
+		}
+	}
+	for k, v := range sch.Properties {
+		props[k] = v
+	}
+	return props
+}
+
+// oneofMessage writes a message with a `oneof` of the variants numbered
+// from `x-proto-oneof-numbers`.
+func (ex *exporter) oneofMessage(msgName string, sch *oas3.Schema, indent string) (string, error) {
+	variants := oneofVariants(sch)
+	nums, reserved, err := oneofNumbers(sch)
+	if err != nil {
+		return "", err
+	}
+	keys := oneofVariantKeys(variants)
+	order := make([]int, len(variants))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return nums[keys[order[i]]] < nums[keys[order[j]]] })
+
+	var sb strings.Builder
+	sb.WriteString(docComment(indent, sch.Description))
+	sb.WriteString(indent + "message " + msgName + " {\n")
+	sb.WriteString(reservedDecl(indent+"  ", numberRanges(reserved)))
+	lines := []string{}
+	seen := map[string]bool{}
+	for _, i := range order {
+		f, err := ex.field(msgName+"Option"+strconv.Itoa(i+1), "option"+strconv.Itoa(i+1), variants[i], indent+"  ")
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(f.nested)
+		fieldName := FieldName(f.typ[strings.LastIndex(f.typ, ".")+1:])
+		if f.label != "" || strings.HasPrefix(f.typ, "map<") || seen[fieldName] {
+			// `oneof` fields cannot be repeated or maps so they are wrapped.
+			fieldName = "option" + strconv.Itoa(i+1)
+			if strings.HasPrefix(f.typ, "map<") {
+				ex.imports[wktStruct] = true
+				f.typ = "google.protobuf.Struct"
+			} else if f.label != "" {
+				ex.imports[wktStruct] = true
+				f.typ = "google.protobuf.ListValue"
+			}
+		}
+		seen[fieldName] = true
+		lines = append(lines, fmt.Sprintf("%s    %s %s = %d;\n", indent, f.typ, fieldName, nums[keys[i]]))
+	}
+	sb.WriteString(indent + "  oneof value {\n")
+	for _, line := range lines {
+		sb.WriteString(line)
+	}
+	sb.WriteString(indent + "  }\n" + indent + "}\n")
+	return sb.String(), nil
+}
+
+type protoField struct {
+	label  string // `repeated ` or empty
+	typ    string
+	nested string // nested message or enum declarations
+}
+
+// field returns the field type for a property. Inline objects, enums and
+// unions become nested declarations named `nestedName`.
+func (ex *exporter) field(nestedName, propName string, schRef *oas3.SchemaRef, indent string) (protoField, error) {
+	if schRef == nil {
+		ex.imports[wktStruct] = true
+		return protoField{typ: "google.protobuf.Value"}, nil
+	} else if schRef.Ref != "" {
+		return protoField{typ: MessageName(refName(schRef.Ref))}, nil
+	}
+	sch := schRef.Value
+	if sch == nil {
+		ex.imports[wktStruct] = true
+		return protoField{typ: "google.protobuf.Value"}, nil
+	} else if isRefWrapper(sch) {
+		return protoField{typ: MessageName(refName(sch.AllOf[0].Ref))}, nil
+	}
+	switch {
+	case isEnum(sch):
+		decl, err := enumDecl(nestedName, sch, indent)
+		return protoField{typ: nestedName, nested: decl}, err
+	case len(sch.OneOf) > 0 || len(sch.AnyOf) > 0:
+		decl, err := ex.oneofMessage(nestedName, sch, indent)
+		return protoField{typ: nestedName, nested: decl}, err
+	case isMessage(sch):
+		decl, err := ex.message(nestedName, sch, indent)
+		return protoField{typ: nestedName, nested: decl}, err
+	}
+	switch sch.Type {
+	case openapi3.TypeArray:
+		items, err := ex.field(nestedName, propName, sch.Items, indent)
+		if err != nil {
+			return items, err
+		} else if items.label != "" || strings.HasPrefix(items.typ, "map<") {
+			ex.imports[wktStruct] = true
+			items.typ = "google.protobuf.ListValue"
+		}
+		items.label = "repeated "
+		return items, nil
+	case openapi3.TypeObject, "":
+		if aps := sch.AdditionalProperties.Schema; aps != nil {
+			vals, err := ex.field(nestedName, propName, aps, indent)
+			if err != nil {
+				return vals, err
+			} else if vals.label != "" || strings.HasPrefix(vals.typ, "map<") {
+				ex.imports[wktStruct] = true
+				vals.typ = "google.protobuf.Value"
+				vals.label = ""
+			}
+			vals.typ = "map<string, " + vals.typ + ">"
+			return vals, nil
+		}
+		ex.imports[wktStruct] = true
+		if sch.Type == openapi3.TypeObject {
+			return protoField{typ: "google.protobuf.Struct"}, nil
+		}
+		return protoField{typ: "google.protobuf.Value"}, nil
+	}
+	typ, wrapper := scalarType(sch)
+	if sch.Nullable && wrapper != "" {
+		ex.imports[wktWrappers] = true
+		return protoField{typ: wrapper}, nil
+	}
+	switch typ {
+	case "google.protobuf.Timestamp":
+		ex.imports[wktTimestamp] = true
+	case "google.protobuf.Duration":
+		ex.imports[wktDuration] = true
+	}
+	return protoField{typ: typ}, nil
+}
+
+// scalarType returns the proto type for a scalar schema and the wrapper
+// well-known type used when it is nullable.
+func scalarType(sch *oas3.Schema) (string, string) {
+	switch sch.Type {
+	case openapi3.TypeBoolean:
+		return "bool", "google.protobuf.BoolValue"
+	case openapi3.TypeInteger:
+		switch sch.Format {
+		case openapi3.FormatInt32:
+			if sch.Min != nil && *sch.Min >= 0 {
+				return "uint32", "google.protobuf.UInt32Value"
+			}
+			return "int32", "google.protobuf.Int32Value"
+		}
+		if sch.Min != nil && *sch.Min >= 0 && sch.Format == openapi3.FormatInt64 {
+			return "uint64", "google.protobuf.UInt64Value"
+		}
+		return "int64", "google.protobuf.Int64Value"
+	case openapi3.TypeNumber:
+		if sch.Format == "float" {
+			return "float", "google.protobuf.FloatValue"
+		}
+		return "double", "google.protobuf.DoubleValue"
+	case openapi3.TypeString:
+		switch sch.Format {
+		case openapi3.FormatDateTime:
+			return "google.protobuf.Timestamp", ""
+		case "duration":
+			return "google.protobuf.Duration", ""
+		case "byte", "binary":
+			return "bytes", "google.protobuf.BytesValue"
+		}
+		return "string", "google.protobuf.StringValue"
+	}
+	return "google.protobuf.Value", ""
+}
+
+func isEnum(sch *oas3.Schema) bool {
+	return sch.Type == openapi3.TypeString && len(sch.Enum) > 0
+}
+
+// enumDecl writes an enum with a zero `UNSPECIFIED` value as proto3 requires.
+// Values are numbered from `x-proto-enum-numbers`, ordered by number and
+// named with the enum name prefix.
+func enumDecl(enumName string, sch *oas3.Schema, indent string) (string, error) {
+	nums, reserved, err := enumNumbers(sch)
+	if err != nil {
+		return "", err
+	}
+	values := []string{}
+	for _, v := range sch.Enum {
+		if s, ok := v.(string); ok {
+			values = append(values, s)
+		}
+	}
+	sort.SliceStable(values, func(i, j int) bool { return nums[values[i]] < nums[values[j]] })
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("%s  %s = 0;\n", indent, EnumValueName(enumName, "unspecified")))
+	seen := map[string]bool{}
+	for _, s := range values {
+		valName := EnumValueName(enumName, s)
+		if seen[valName] {
+			reserved = append(reserved, nums[s])
+			continue
+		}
+		seen[valName] = true
+		body.WriteString(fmt.Sprintf("%s  %s = %d;\n", indent, valName, nums[s]))
+	}
+	var sb strings.Builder
+	sb.WriteString(docComment(indent, sch.Description))
+	sb.WriteString(indent + "enum " + enumName + " {\n")
+	sb.WriteString(reservedDecl(indent+"  ", numberRanges(reserved)))
+	sb.WriteString(body.String())
+	sb.WriteString(indent + "}\n")
+	return sb.String(), nil
+}
+
+// reservedDecl writes a `reserved` statement for number ranges, merging
+// adjacent ranges.
+func reservedDecl(indent string, ranges [][2]int) string {
+	if len(ranges) == 0 {
+		return ""
+	}
+	ranges = append([][2]int{}, ranges...)
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	merged := [][2]int{}
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r[0] <= merged[n-1][1]+1 {
+			if r[1] > merged[n-1][1] {
+				merged[n-1][1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	parts := []string{}
+	for _, r := range merged {
+		if r[0] == r[1] {
+			parts = append(parts, strconv.Itoa(r[0]))
+		} else {
+			parts = append(parts, strconv.Itoa(r[0])+" to "+strconv.Itoa(r[1]))
+		}
+	}
+	return indent + "reserved " + strings.Join(parts, ", ") + ";\n"
+}
+
+// numberRanges returns single number ranges for `reservedDecl()`.
+func numberRanges(nums []int) [][2]int {
+	ranges := [][2]int{}
+	for _, num := range nums {
+		ranges = append(ranges, [2]int{num, num})
+	}
+	return ranges
+}
+
+func docComment(indent, desc string) string {
+	desc = strings.TrimSpace(desc)
+	if desc == "" {
+		return ""
+	}
+	var sb strings.Builder
+	for _, line := range strings.Split(desc, "\n") {
+		sb.WriteString(strings.TrimRight(indent+"// "+line, " ") + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,488 @@
+package openapi3proto
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+// ImportFile reads a `.proto` file and converts its messages and enums to
+// schemas. See `Import()`.
+func ImportFile(filename string) (oas3.Schemas, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return Import(data)
+}
+
+// Import converts the messages and enums of a proto3 `.proto` file to
+// schemas. Messages become objects with properties named by `json_name` or
+// the default proto3 JSON name and `x-proto-field-number` set, repeated
+// fields become arrays, maps use `additionalProperties` and well-known types
+// become their JSON formats. Nested declarations are named by appending
+// their name to the parent name, e.g. `UserAddress`. Services are ignored.
+func Import(data []byte) (oas3.Schemas, error) {
+	p := &protoParser{toks: tokenize(string(data))}
+	file, err := p.file()
+	if err != nil {
+		return nil, err
+	}
+	im := &importer{decls: map[string]*protoDecl{}, schemas: oas3.Schemas{}}
+	im.index(file.decls, file.pkg, "")
+	for _, decl := range file.decls {
+		if err := im.convert(decl); err != nil {
+			return nil, err
+		}
+	}
+	return im.schemas, nil
+}
+
+// protoDecl is a parsed message or enum.
+type protoDecl struct {
+	enum   bool
+	name   string
+	fields []protoFieldDecl
+	values []string // enum value names
+	decls  []*protoDecl
+	// scope is the fully qualified name, set by `importer.index`.
+	scope      string
+	schemaName string
+}
+
+type protoFieldDecl struct {
+	name     string
+	typ      string
+	mapKey   string
+	repeated bool
+	number   int
+	jsonName string
+	comment  string
+}
+
+type protoFile struct {
+	pkg   string
+	decls []*protoDecl
+}
+
+// tokenize splits proto source into identifiers, numbers, string literals
+// and symbols. Leading `//` comments are kept as tokens starting with `//`
+// so field comments become descriptions. Trailing comments are dropped.
+func tokenize(src string) []string {
+	toks := []string{}
+	newline := true
+	for i := 0; i < len(src); {
+		c := src[i]
+		if c == '\n' {
+			newline = true
+		} else if !unicode.IsSpace(rune(c)) && !strings.HasPrefix(src[i:], "//") && !strings.HasPrefix(src[i:], "/*") {
+			newline = false
+		}
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			if newline {
+				toks = append(toks, strings.TrimRight(src[i:i+end], "\r"))
+			}
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return toks
+			}
+			i += end + 4
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				j = len(src) - 1
+			}
+			toks = append(toks, src[i:j+1])
+			i = j + 1
+		case unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || c == '_' || c == '.' || c == '-' || c == '+':
+			j := i + 1
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])) || src[j] == '_' || src[j] == '.') {
+				j++
+			}
+			toks = append(toks, src[i:j])
+			i = j
+		default:
+			toks = append(toks, string(c))
+			i++
+		}
+	}
+	return toks
+}
+
+type protoParser struct {
+	toks    []string
+	pos     int
+	comment string
+}
+
+// next returns the next token, collecting comments.
+func (p *protoParser) next() string {
+	for p.pos < len(p.toks) {
+		tok := p.toks[p.pos]
+		p.pos++
+		if strings.HasPrefix(tok, "//") {
+			line := strings.TrimSpace(strings.TrimPrefix(tok, "//"))
+			if p.comment != "" {
+				p.comment += "\n"
+			}
+			p.comment += line
+			continue
+		}
+		return tok
+	}
+	return ""
+}
+
+func (p *protoParser) peek() string {
+	pos, comment := p.pos, p.comment
+	tok := p.next()
+	p.pos, p.comment = pos, comment
+	return tok
+}
+
+func (p *protoParser) expect(want string) error {
+	if tok := p.next(); tok != want {
+		return fmt.Errorf("proto parse error: want [%s], got [%s]", want, tok)
+	}
+	return nil
+}
+
+// skipStatement skips to the end of a statement or block.
+func (p *protoParser) skipStatement() {
+	depth := 0
+	for tok := p.next(); tok != ""; tok = p.next() {
+		switch tok {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth <= 0 {
+				return
+			}
+		case ";":
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+func (p *protoParser) file() (*protoFile, error) {
+	f := &protoFile{}
+	for tok := p.peek(); tok != ""; tok = p.peek() {
+		switch tok {
+		case "package":
+			p.next()
+			f.pkg = p.next()
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		case "message", "enum":
+			decl, err := p.decl()
+			if err != nil {
+				return nil, err
+			}
+			f.decls = append(f.decls, decl)
+		default:
+			// `syntax`, `import`, `option`, `service` and `extend`.
+			p.skipStatement()
+		}
+		p.comment = ""
+	}
+	return f, nil
+}
+
+func (p *protoParser) decl() (*protoDecl, error) {
+	d := &protoDecl{enum: p.next() == "enum", name: p.next()}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	p.comment = ""
+	for {
+		tok := p.peek()
+		switch tok {
+		case "":
+			return nil, fmt.Errorf("proto parse error: [%s] not closed", d.name)
+		case "}":
+			p.next()
+			p.comment = ""
+			return d, nil
+		case ";":
+			p.next()
+		case "message", "enum":
+			nested, err := p.decl()
+			if err != nil {
+				return nil, err
+			}
+			d.decls = append(d.decls, nested)
+		case "option", "reserved", "extensions", "extend":
+			p.skipStatement()
+		case "oneof":
+			// `oneof` fields are regular optional fields in proto3 JSON.
+			p.next()
+			p.next()
+			if err := p.expect("{"); err != nil {
+				return nil, err
+			}
+			for p.peek() != "}" && p.peek() != "" {
+				if p.peek() == "option" {
+					p.skipStatement()
+					continue
+				}
+				f, err := p.field()
+				if err != nil {
+					return nil, err
+				}
+				d.fields = append(d.fields, f)
+			}
+			p.next()
+		default:
+			if d.enum {
+				name := p.next()
+				p.skipStatement()
+				d.values = append(d.values, name)
+				continue
+			}
+			f, err := p.field()
+			if err != nil {
+				return nil, err
+			}
+			d.fields = append(d.fields, f)
+		}
+		p.comment = ""
+	}
+}
+
+func (p *protoParser) field() (protoFieldDecl, error) {
+	f := protoFieldDecl{}
+	tok := p.next()
+	f.comment = p.comment
+	switch tok {
+	case "repeated":
+		f.repeated = true
+		tok = p.next()
+	case "optional", "required":
+		tok = p.next()
+	}
+	if tok == "map" {
+		if err := p.expect("<"); err != nil {
+			return f, err
+		}
+		f.mapKey = p.next()
+		if err := p.expect(","); err != nil {
+			return f, err
+		}
+		tok = p.next()
+		if err := p.expect(">"); err != nil {
+			return f, err
+		}
+	}
+	f.typ = tok
+	f.name = p.next()
+	if err := p.expect("="); err != nil {
+		return f, err
+	}
+	num, err := strconv.Atoi(p.next())
+	if err != nil {
+		return f, fmt.Errorf("proto parse error: field [%s] number: %w", f.name, err)
+	}
+	f.number = num
+	if p.peek() == "[" {
+		p.next()
+		for tok := p.next(); tok != "]" && tok != ""; tok = p.next() {
+			if tok == "json_name" && p.peek() == "=" {
+				p.next()
+				if s, err := strconv.Unquote(p.next()); err == nil {
+					f.jsonName = s
+				}
+			}
+		}
+	}
+	p.comment = ""
+	return f, p.expect(";")
+}
+
+type importer struct {
+	decls   map[string]*protoDecl // fully qualified name to declaration
+	schemas oas3.Schemas
+}
+
+// index registers declarations by fully qualified name and assigns schema
+// names.
+func (im *importer) index(decls []*protoDecl, scope, parentSchemaName string) {
+	for _, d := range decls {
+		if scope == "" {
+			d.scope = d.name
+		} else {
+			d.scope = scope + "." + d.name
+		}
+		d.schemaName = parentSchemaName + d.name
+		im.decls[d.scope] = d
+		im.index(d.decls, d.scope, d.schemaName)
+	}
+}
+
+// lookup resolves a type name using protobuf scoping rules, searching from
+// the innermost scope outwards.
+func (im *importer) lookup(typ, scope string) *protoDecl {
+	if strings.HasPrefix(typ, ".") {
+		return im.decls[strings.TrimPrefix(typ, ".")]
+	}
+	for {
+		name := typ
+		if scope != "" {
+			name = scope + "." + typ
+		}
+		if d, ok := im.decls[name]; ok {
+			return d
+		} else if scope == "" {
+			break
+		}
+		if idx := strings.LastIndex(scope, "."); idx > -1 {
+			scope = scope[:idx]
+		} else {
+			scope = ""
+		}
+	}
+	return nil
+}
+
+func (im *importer) convert(d *protoDecl) error {
+	if d.enum {
+		sch := oas3.NewStringSchema()
+		for _, v := range d.values {
+			sch.Enum = append(sch.Enum, v)
+		}
+		im.schemas[d.schemaName] = oas3.NewSchemaRef("", sch)
+		return nil
+	}
+	sch := oas3.NewObjectSchema()
+	for _, f := range d.fields {
+		propRef, err := im.fieldSchema(f, d.scope)
+		if err != nil {
+			return fmt.Errorf("message [%s]: %w", d.scope, err)
+		}
+		if f.comment != "" && propRef.Ref == "" {
+			propRef.Value.Description = f.comment
+		}
+		propName := f.jsonName
+		if propName == "" {
+			propName = JSONName(f.name)
+		}
+		sch.Properties[propName] = setPropertyFieldNumber(propRef, f.number)
+	}
+	im.schemas[d.schemaName] = oas3.NewSchemaRef("", sch)
+	for _, nested := range d.decls {
+		if err := im.convert(nested); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (im *importer) fieldSchema(f protoFieldDecl, scope string) (*oas3.SchemaRef, error) {
+	valRef, err := im.typeSchema(f.typ, scope)
+	if err != nil {
+		return nil, fmt.Errorf("field [%s]: %w", f.name, err)
+	}
+	switch {
+	case f.mapKey != "":
+		has := true
+		sch := oas3.NewObjectSchema()
+		sch.AdditionalProperties = oas3.AdditionalProperties{Has: &has, Schema: valRef}
+		return oas3.NewSchemaRef("", sch), nil
+	case f.repeated:
+		sch := oas3.NewArraySchema()
+		sch.Items = valRef
+		return oas3.NewSchemaRef("", sch), nil
+	}
+	return valRef, nil
+}
+
+// typeSchema returns the schema for a scalar, well-known or declared type.
+func (im *importer) typeSchema(typ, scope string) (*oas3.SchemaRef, error) {
+	if sch := scalarSchema(typ); sch != nil {
+		return oas3.NewSchemaRef("", sch), nil
+	}
+	d := im.lookup(typ, scope)
+	if d == nil {
+		return nil, fmt.Errorf("unknown type [%s]", typ)
+	}
+	return oas3.NewSchemaRef(openapi3.SchemaPointerExpand("", d.schemaName), nil), nil
+}
+
+// scalarSchema returns schemas for scalar and well-known types using their
+// proto3 JSON mapping, e.g. 64-bit integers are strings in JSON but are
+// mapped to integers with `int64` format here.
+func scalarSchema(typ string) *oas3.Schema {
+	switch strings.TrimPrefix(typ, ".") {
+	case "double":
+		return oas3.NewFloat64Schema().WithFormat("double")
+	case "float":
+		return oas3.NewFloat64Schema().WithFormat("float")
+	case "int32", "sint32", "sfixed32":
+		return oas3.NewInt32Schema()
+	case "uint32", "fixed32":
+		return oas3.NewInt32Schema().WithMin(0)
+	case "int64", "sint64", "sfixed64":
+		return oas3.NewInt64Schema()
+	case "uint64", "fixed64":
+		return oas3.NewInt64Schema().WithMin(0)
+	case "bool":
+		return oas3.NewBoolSchema()
+	case "string":
+		return oas3.NewStringSchema()
+	case "bytes":
+		return oas3.NewBytesSchema()
+	case "google.protobuf.Timestamp":
+		return oas3.NewDateTimeSchema()
+	case "google.protobuf.Duration":
+		return oas3.NewStringSchema().WithFormat("duration")
+	case "google.protobuf.Struct":
+		return oas3.NewObjectSchema()
+	case "google.protobuf.Value", "google.protobuf.Any":
+		return oas3.NewSchema()
+	case "google.protobuf.ListValue":
+		return oas3.NewArraySchema().WithItems(oas3.NewSchema())
+	case "google.protobuf.Empty":
+		return oas3.NewObjectSchema()
+	}
+	if strings.HasPrefix(typ, "google.protobuf.") && strings.HasSuffix(typ, "Value") {
+		if sch := scalarSchema(wrapperScalars[strings.TrimPrefix(typ, "google.protobuf.")]); sch != nil {
+			sch.Nullable = true
+			return sch
+		}
+	}
+	return nil
+}
+
+var wrapperScalars = map[string]string{
+	"BoolValue":   "bool",
+	"BytesValue":  "bytes",
+	"DoubleValue": "double",
+	"FloatValue":  "float",
+	"Int32Value":  "int32",
+	"Int64Value":  "int64",
+	"StringValue": "string",
+	"UInt32Value": "uint32",
+	"UInt64Value": "uint64"}
@@ -0,0 +1,91 @@
+package openapi3proto
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/grokify/mogo/encoding/jsonpointer"
+	"github.com/grokify/mogo/type/stringsutil"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+// nameWords splits a name into words at separators and lower to upper case
+// transitions, e.g. `userId` and `user-id` both return `[user id]`.
+func nameWords(s string) []string {
+	words := []string{}
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(part)
+		last := 0
+		for i := 1; i < len(runes); i++ {
+			if unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i]) {
+				words = append(words, string(runes[last:i]))
+				last = i
+			}
+		}
+		words = append(words, string(runes[last:]))
+	}
+	return words
+}
+
+// MessageName converts a schema name to a message or enum name, e.g.
+// `pet-base` to `PetBase`.
+func MessageName(s string) string {
+	var sb strings.Builder
+	for _, w := range nameWords(s) {
+		sb.WriteString(stringsutil.ToUpperFirst(w, false))
+	}
+	return identifier(sb.String())
+}
+
+// FieldName converts a property name to a snake case field name, e.g.
+// `userId` to `user_id`.
+func FieldName(s string) string {
+	words := nameWords(s)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return identifier(strings.Join(words, "_"))
+}
+
+// EnumValueName returns an enum value name prefixed with the enum name as
+// protobuf style requires, e.g. `Status` and `sold-out` give `STATUS_SOLD_OUT`.
+func EnumValueName(enumName, value string) string {
+	return identifier(strings.ToUpper(FieldName(enumName) + "_" + FieldName(value)))
+}
+
+// JSONName returns the default proto3 JSON name of a field, e.g. `user_id`
+// gives `userId`.
+func JSONName(fieldName string) string {
+	var sb strings.Builder
+	upper := false
+	for _, r := range fieldName {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func identifier(s string) string {
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		return "x" + s
+	}
+	return s
+}
+
+// refName returns the component name of a local schema reference.
+func refName(ref string) string {
+	ref = strings.TrimPrefix(ref, openapi3.PointerComponentsSchemas+"/")
+	if idx := strings.LastIndex(ref, "/"); idx > -1 {
+		ref = ref[idx+1:]
+	}
+	return jsonpointer.PropertyNameUnescape(ref)
+}
@@ -0,0 +1,506 @@
+package openapi3proto
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	// XProtoFieldNumber is the schema extension persisting a property's
+	// field number so numbers are stable when a spec is regenerated.
+	XProtoFieldNumber = "x-proto-field-number"
+	// XProtoFieldNumberMax is the schema extension persisting the highest
+	// field number assigned to a message's own properties so numbers of
+	// removed properties are reserved rather than reused.
+	XProtoFieldNumberMax = "x-proto-field-number-max"
+	// XProtoEnumNumbers is the schema extension mapping enum values to
+	// their numbers. Removed values are kept so their numbers stay reserved.
+	XProtoEnumNumbers = "x-proto-enum-numbers"
+	// XProtoOneofNumbers is the schema extension mapping `oneOf` / `anyOf`
+	// variants to their field numbers. Variants are keyed by referenced
+	// schema name, title or type, falling back to `optionN` by position.
+	XProtoOneofNumbers = "x-proto-oneof-numbers"
+)
+
+const (
+	fieldNumberMax           = 536870911
+	fieldNumberReservedStart = 19000
+	fieldNumberReservedEnd   = 19999
+
+	// fieldNumberRange is the size of the field number range of each
+	// `allOf` level. A message's own properties are numbered after
+	// `fieldNumberRange` times its `allOf` depth.
+	fieldNumberRange = 1000
+)
+
+// fieldNumbers assigns field numbers to object schemas, reusing
+// `x-proto-field-number` values and persisting new numbers.
+type fieldNumbers struct {
+	spec     *openapi3.Spec
+	numbers  map[*oas3.Schema]map[string]int
+	reserved map[*oas3.Schema][][2]int
+	depths   map[*oas3.Schema]int
+	bases    map[*oas3.Schema]bool
+	active   map[*oas3.Schema]bool
+}
+
+func newFieldNumbers(spec *openapi3.Spec) *fieldNumbers {
+	fn := &fieldNumbers{
+		spec:     spec,
+		numbers:  map[*oas3.Schema]map[string]int{},
+		reserved: map[*oas3.Schema][][2]int{},
+		depths:   map[*oas3.Schema]int{},
+		bases:    map[*oas3.Schema]bool{},
+		active:   map[*oas3.Schema]bool{}}
+	if spec != nil && spec.Components != nil {
+		seen := map[*oas3.Schema]bool{}
+		for _, name := range sortedKeys(spec.Components.Schemas) {
+			fn.findBases(spec.Components.Schemas[name], seen)
+		}
+	}
+	return fn
+}
+
+// AssignFieldNumbers sets `x-proto-field-number` on every property of the
+// object schemas in `components.schemas`, including inline objects, along
+// with `x-proto-field-number-max` on messages, `x-proto-enum-numbers` on
+// enums and `x-proto-oneof-numbers` on unions. Existing numbers are kept and
+// new ones follow the highest number ever assigned, so numbers do not change
+// or get reused when properties, values or variants are added or removed.
+// A message's own properties are numbered in a range above those of its
+// `allOf` references so properties added to a base cannot collide with its
+// children. Properties that are references are wrapped in `allOf` to hold
+// the extension since `$ref` siblings are ignored in OpenAPI 3.0.
+func AssignFieldNumbers(spec *openapi3.Spec) error {
+	if spec == nil {
+		return openapi3.ErrSpecNotSet
+	}
+	fn := newFieldNumbers(spec)
+	if spec.Components == nil {
+		return nil
+	}
+	for _, name := range sortedKeys(spec.Components.Schemas) {
+		if err := fn.schema(spec.Components.Schemas[name]); err != nil {
+			return fmt.Errorf("schema [%s]: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// schema numbers a schema converted to a message, enum or `oneof` and the
+// inline schemas it contains.
+func (fn *fieldNumbers) schema(schRef *oas3.SchemaRef) error {
+	if schRef == nil || schRef.Ref != "" || schRef.Value == nil {
+		return nil
+	}
+	sch := schRef.Value
+	switch {
+	case isRefWrapper(sch):
+		return nil
+	case isEnum(sch):
+		nums, _, err := enumNumbers(sch)
+		if err != nil {
+			return err
+		}
+		setExtension(sch, XProtoEnumNumbers, nums)
+		return nil
+	case len(sch.OneOf) > 0 || len(sch.AnyOf) > 0:
+		nums, _, err := oneofNumbers(sch)
+		if err != nil {
+			return err
+		}
+		setExtension(sch, XProtoOneofNumbers, nums)
+		for _, variant := range oneofVariants(sch) {
+			if err := fn.schema(variant); err != nil {
+				return err
+			}
+		}
+		return nil
+	case isMessage(sch):
+		_, err := fn.message(sch)
+		return err
+	case sch.Items != nil:
+		return fn.schema(sch.Items)
+	case sch.AdditionalProperties.Schema != nil:
+		return fn.schema(sch.AdditionalProperties.Schema)
+	}
+	return nil
+}
+
+// message returns the field numbers of a message schema. Properties of
+// `allOf` references are merged with their own numbers and inline properties
+// are numbered in the range above them.
+func (fn *fieldNumbers) message(sch *oas3.Schema) (map[string]int, error) {
+	if nums, ok := fn.numbers[sch]; ok {
+		return nums, nil
+	} else if fn.active[sch] {
+		return nil, fmt.Errorf("circular allOf")
+	}
+	fn.active[sch] = true
+	defer delete(fn.active, sch)
+
+	nums := map[string]int{}
+	used := map[int]string{}
+	reserved := [][2]int{}
+	own := []*oas3.Schema{sch}
+	depth := 0
+	for _, item := range sch.AllOf {
+		if item == nil {
+			continue
+		}
+		itemSch := fn.resolve(item)
+		if itemSch == nil || itemSch == sch {
+			continue
+		} else if item.Ref == "" {
+			// Inline `allOf` objects are numbered with the parent.
+			own = append(own, itemSch)
+			continue
+		}
+		refNums, err := fn.message(itemSch)
+		if err != nil {
+			return nil, err
+		}
+		for propName, num := range refNums {
+			if other, ok := used[num]; ok && other != propName {
+				return nil, fmt.Errorf("field number [%d] used by [%s] and [%s]", num, other, propName)
+			}
+			nums[propName] = num
+			used[num] = propName
+		}
+		reserved = append(reserved, fn.reserved[itemSch]...)
+		if d := fn.depths[itemSch] + 1; d > depth {
+			depth = d
+		}
+	}
+	ownReserved, err := fn.numberProperties(sch, own, depth*fieldNumberRange, nums, used)
+	if err != nil {
+		return nil, err
+	}
+	fn.numbers[sch] = nums
+	fn.reserved[sch] = append(reserved, ownReserved...)
+	fn.depths[sch] = depth
+	return nums, nil
+}
+
+// numberProperties numbers the properties of a message's own schemas after
+// `offset` and recurses into inline schemas. New numbers follow the highest
+// number ever assigned, persisted as `x-proto-field-number-max`, and the
+// unused numbers below it are returned as reserved ranges.
+func (fn *fieldNumbers) numberProperties(sch *oas3.Schema, own []*oas3.Schema, offset int, nums map[string]int, used map[int]string) ([][2]int, error) {
+	high, _, err := extensionNumber(sch.Extensions[XProtoFieldNumberMax])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", XProtoFieldNumberMax, err)
+	} else if high < offset {
+		high = offset
+	}
+	ownUsed := map[int]bool{}
+	for _, ownSch := range own {
+		for _, propName := range sortedKeys(ownSch.Properties) {
+			num, ok, err := propertyFieldNumber(ownSch.Properties[propName])
+			if err != nil {
+				return nil, fmt.Errorf("property [%s]: %w", propName, err)
+			} else if !ok {
+				continue
+			}
+			if other, ok := used[num]; ok && other != propName {
+				return nil, fmt.Errorf("field number [%d] used by [%s] and [%s]", num, other, propName)
+			}
+			nums[propName] = num
+			used[num] = propName
+			ownUsed[num] = true
+			if num > offset && num > high {
+				high = num
+			}
+		}
+	}
+	limit := fieldNumberMax
+	if fn.bases[sch] {
+		// Bases stay in their range to leave the next one to children.
+		limit = offset + fieldNumberRange - 1
+	}
+	for _, ownSch := range own {
+		for _, propName := range sortedKeys(ownSch.Properties) {
+			propRef := ownSch.Properties[propName]
+			if _, ok := nums[propName]; !ok {
+				next := high + 1
+				if next >= fieldNumberReservedStart && next <= fieldNumberReservedEnd {
+					next = fieldNumberReservedEnd + 1
+				}
+				if next > limit {
+					return nil, fmt.Errorf("field numbers exhausted")
+				}
+				high = next
+				nums[propName] = next
+				used[next] = propName
+				ownUsed[next] = true
+				propRef = setPropertyFieldNumber(propRef, next)
+				ownSch.Properties[propName] = propRef
+			}
+			if err := fn.schema(propRef); err != nil {
+				return nil, fmt.Errorf("property [%s]: %w", propName, err)
+			}
+		}
+	}
+	if high > offset {
+		setExtension(sch, XProtoFieldNumberMax, high)
+	}
+	return unusedRanges(ownUsed, offset+1, high), nil
+}
+
+// findBases marks the schemas referenced from `allOf`.
+func (fn *fieldNumbers) findBases(schRef *oas3.SchemaRef, seen map[*oas3.Schema]bool) {
+	if schRef == nil || schRef.Ref != "" || schRef.Value == nil || seen[schRef.Value] {
+		return
+	}
+	sch := schRef.Value
+	seen[sch] = true
+	for _, item := range sch.AllOf {
+		if item != nil && item.Ref != "" {
+			if base := fn.resolve(item); base != nil {
+				fn.bases[base] = true
+			}
+		}
+		fn.findBases(item, seen)
+	}
+	for _, name := range sortedKeys(sch.Properties) {
+		fn.findBases(sch.Properties[name], seen)
+	}
+	for _, variant := range append(append(oas3.SchemaRefs{}, sch.OneOf...), sch.AnyOf...) {
+		fn.findBases(variant, seen)
+	}
+	fn.findBases(sch.Items, seen)
+	fn.findBases(sch.AdditionalProperties.Schema, seen)
+}
+
+// enumNumbers returns the numbers of an enum's string values from
+// `x-proto-enum-numbers`, numbering new values after the highest number in
+// the map, and the numbers of removed values which remain reserved.
+func enumNumbers(sch *oas3.Schema) (map[string]int, []int, error) {
+	keys := []string{}
+	for _, v := range sch.Enum {
+		if s, ok := v.(string); ok {
+			keys = append(keys, s)
+		}
+	}
+	return persistedNumbers(sch, XProtoEnumNumbers, keys)
+}
+
+// oneofNumbers returns the field numbers of a union's variants keyed by
+// `oneofVariantKeys()` and the numbers of removed variants.
+func oneofNumbers(sch *oas3.Schema) (map[string]int, []int, error) {
+	return persistedNumbers(sch, XProtoOneofNumbers, oneofVariantKeys(oneofVariants(sch)))
+}
+
+// persistedNumbers reads a map of numbers from a schema extension and
+// numbers missing keys after the highest number in it. Numbers of keys no
+// longer present are returned as reserved.
+func persistedNumbers(sch *oas3.Schema, ext string, keys []string) (map[string]int, []int, error) {
+	nums := map[string]int{}
+	high := 0
+	m := map[string]any{}
+	switch val := sch.Extensions[ext].(type) {
+	case nil:
+	case map[string]any:
+		m = val
+	case map[string]int:
+		for k, v := range val {
+			m[k] = v
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid %s [%v]", ext, val)
+	}
+	for k, v := range m {
+		num, _, err := extensionNumber(v)
+		if err != nil || num < 1 {
+			return nil, nil, fmt.Errorf("invalid %s [%s]: [%v]", ext, k, v)
+		}
+		nums[k] = num
+		if num > high {
+			high = num
+		}
+	}
+	current := map[string]bool{}
+	for _, key := range keys {
+		current[key] = true
+		if _, ok := nums[key]; !ok {
+			high++
+			if high >= fieldNumberReservedStart && high <= fieldNumberReservedEnd {
+				high = fieldNumberReservedEnd + 1
+			}
+			nums[key] = high
+		}
+	}
+	reserved := []int{}
+	for _, key := range sortedKeys(nums) {
+		if !current[key] {
+			reserved = append(reserved, nums[key])
+		}
+	}
+	sort.Ints(reserved)
+	return nums, reserved, nil
+}
+
+func oneofVariants(sch *oas3.Schema) oas3.SchemaRefs {
+	if len(sch.OneOf) > 0 {
+		return sch.OneOf
+	}
+	return sch.AnyOf
+}
+
+// oneofVariantKeys returns the keys identifying union variants: the
+// referenced schema name, the title or the scalar type and format, falling
+// back to `optionN` by position for inline messages and duplicates.
+func oneofVariantKeys(variants oas3.SchemaRefs) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for i, ref := range variants {
+		key := ""
+		switch {
+		case ref == nil:
+		case ref.Ref != "":
+			key = refName(ref.Ref)
+		case ref.Value == nil:
+		case isRefWrapper(ref.Value):
+			key = refName(ref.Value.AllOf[0].Ref)
+		case ref.Value.Title != "":
+			key = ref.Value.Title
+		case !isEnum(ref.Value) && !isMessage(ref.Value) && len(ref.Value.OneOf) == 0 && len(ref.Value.AnyOf) == 0 && ref.Value.Type != "":
+			key = ref.Value.Type
+			if ref.Value.Format != "" {
+				key += ":" + ref.Value.Format
+			}
+		}
+		if key == "" || seen[key] {
+			key = "option" + strconv.Itoa(i+1)
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (fn *fieldNumbers) resolve(schRef *oas3.SchemaRef) *oas3.Schema {
+	if schRef.Ref == "" {
+		return schRef.Value
+	} else if fn.spec.Components == nil {
+		return nil
+	} else if c, ok := fn.spec.Components.Schemas[refName(schRef.Ref)]; ok && c != nil {
+		if c.Ref != "" {
+			return fn.resolve(c)
+		}
+		return c.Value
+	}
+	return nil
+}
+
+// propertyFieldNumber returns the `x-proto-field-number` of a property.
+func propertyFieldNumber(propRef *oas3.SchemaRef) (int, bool, error) {
+	if propRef == nil || propRef.Ref != "" || propRef.Value == nil {
+		return 0, false, nil
+	}
+	val, ok := propRef.Value.Extensions[XProtoFieldNumber]
+	if !ok {
+		return 0, false, nil
+	}
+	num, _, err := extensionNumber(val)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid %s [%v]", XProtoFieldNumber, val)
+	}
+	if num < 1 || num > fieldNumberMax || (num >= fieldNumberReservedStart && num <= fieldNumberReservedEnd) {
+		return 0, false, fmt.Errorf("invalid %s [%d]", XProtoFieldNumber, num)
+	}
+	return num, true, nil
+}
+
+// extensionNumber returns an integer extension value, which is an `int`
+// when set in code and a `float64` or `json.Number` when decoded.
+func extensionNumber(val any) (int, bool, error) {
+	switch v := val.(type) {
+	case nil:
+		return 0, false, nil
+	case int:
+		return v, true, nil
+	case int64:
+		return int(v), true, nil
+	case float64:
+		return int(v), true, nil
+	case json.Number:
+		n, err := strconv.Atoi(v.String())
+		return n, err == nil, err
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil, err
+	}
+	return 0, false, fmt.Errorf("invalid number [%v]", val)
+}
+
+func setExtension(sch *oas3.Schema, ext string, val any) {
+	if sch.Extensions == nil {
+		sch.Extensions = map[string]any{}
+	}
+	sch.Extensions[ext] = val
+}
+
+// unusedRanges returns the ranges of numbers in `[lo, hi]` not in `used`,
+// skipping the implementation reserved range.
+func unusedRanges(used map[int]bool, lo, hi int) [][2]int {
+	spans := [][2]int{{fieldNumberReservedStart, fieldNumberReservedEnd}}
+	for num := range used {
+		spans = append(spans, [2]int{num, num})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	ranges := [][2]int{}
+	next := lo
+	for _, span := range spans {
+		if span[0] > hi {
+			break
+		} else if span[0] > next {
+			ranges = append(ranges, [2]int{next, span[0] - 1})
+		}
+		if span[1]+1 > next {
+			next = span[1] + 1
+		}
+	}
+	if next <= hi {
+		ranges = append(ranges, [2]int{next, hi})
+	}
+	return ranges
+}
+
+// setPropertyFieldNumber sets `x-proto-field-number`, wrapping references in
+// `allOf`.
+func setPropertyFieldNumber(propRef *oas3.SchemaRef, num int) *oas3.SchemaRef {
+	if propRef == nil {
+		propRef = oas3.NewSchemaRef("", oas3.NewSchema())
+	} else if propRef.Ref != "" || propRef.Value == nil {
+		wrap := oas3.NewSchema()
+		wrap.AllOf = oas3.SchemaRefs{propRef}
+		propRef = oas3.NewSchemaRef("", wrap)
+	}
+	setExtension(propRef.Value, XProtoFieldNumber, num)
+	return propRef
+}
+
+// isRefWrapper returns true for `allOf` wrappers around a single reference.
+func isRefWrapper(sch *oas3.Schema) bool {
+	return len(sch.AllOf) == 1 && sch.AllOf[0] != nil && sch.AllOf[0].Ref != "" && len(sch.Properties) == 0
+}
+
+// isMessage returns true for schemas converted to messages.
+func isMessage(sch *oas3.Schema) bool {
+	return len(sch.Properties) > 0 || len(sch.AllOf) > 0
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,249 @@
+package openapi3proto
+
+import (
+	"strings"
+	"testing"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const exportTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "description": "A pet.",
+        "properties": {
+          "name": {"type": "string", "x-proto-field-number": 2},
+          "id": {"type": "integer", "format": "int64", "x-proto-field-number": 1},
+          "status": {"type": "string", "enum": ["available", "sold-out"]},
+          "birthDate": {"type": "string", "format": "date-time"},
+          "nickname": {"type": "string", "nullable": true},
+          "tags": {"type": "array", "items": {"type": "string"}},
+          "owner": {"$ref": "#/components/schemas/Owner"},
+          "attributes": {"type": "object", "additionalProperties": {"type": "string"}},
+          "x-rating": {"type": "number", "format": "float"}
+        }
+      },
+      "Owner": {
+        "type": "object",
+        "properties": {
+          "userId": {"type": "string", "description": "Owner ID."},
+          "address": {"type": "object", "properties": {"city": {"type": "string"}}}
+        }
+      },
+      "Status": {"type": "string", "enum": ["ACTIVE", "INACTIVE"]},
+      "Animal": {"oneOf": [{"$ref": "#/components/schemas/Pet"}, {"$ref": "#/components/schemas/Owner"}]}
+    }
+  }
+}`
+
+func TestExport(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(exportTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	src, err := Export(spec, &Options{Package: "pets.v1"})
+	if err != nil {
+		t.Fatalf("openapi3proto.Export() error [%v]", err)
+	}
+	out := string(src)
+	for _, want := range []string{
+		"package pets.v1;",
+		`import "google/protobuf/timestamp.proto";`,
+		`import "google/protobuf/wrappers.proto";`,
+		"// A pet.\nmessage Pet {",
+		"  int64 id = 1;\n  string name = 2;\n",
+		"  enum Status {\n    STATUS_UNSPECIFIED = 0;\n    STATUS_AVAILABLE = 1;\n    STATUS_SOLD_OUT = 2;\n  }",
+		"  google.protobuf.Timestamp birth_date = 4;",
+		"  google.protobuf.StringValue nickname = 5;",
+		"  Owner owner = 6;",
+		"  Status status = 7;",
+		"  repeated string tags = 8;",
+		"  float x_rating = 9 [json_name = \"x-rating\"];",
+		"  map<string, string> attributes = 3;",
+		"  message Address {\n    string city = 1;\n  }",
+		"  // Owner ID.\n  string user_id = 2;",
+		"  oneof value {\n    Pet pet = 1;\n    Owner owner = 2;\n  }",
+		"enum Status {\n  STATUS_UNSPECIFIED = 0;\n  STATUS_ACTIVE = 1;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("openapi3proto.Export() mismatch: want [%s]\n%s", want, out)
+		}
+	}
+
+	// Export works on a copy and `AssignFieldNumbers()` persists numbers so
+	// new properties do not renumber or reuse others.
+	if spec.Components.Schemas["Pet"].Value.Properties["owner"].Ref == "" {
+		t.Errorf("openapi3proto.Export() mismatch: want spec unchanged")
+	}
+	if err := AssignFieldNumbers(spec); err != nil {
+		t.Fatalf("openapi3proto.AssignFieldNumbers() error [%v]", err)
+	}
+	if got := spec.Components.Schemas["Pet"].Value.Properties["owner"].Value.Extensions[XProtoFieldNumber]; got != 6 {
+		t.Errorf("openapi3proto.AssignFieldNumbers() persisted number mismatch: want [6], got [%v]", got)
+	}
+	pet := spec.Components.Schemas["Pet"].Value
+	pet.Properties["age"] = pet.Properties["id"]
+	delete(pet.Properties, "id")
+	delete(pet.Properties, "x-rating")
+	pet.Properties["age"].Value.Extensions = nil
+	src, err = Export(spec, nil)
+	if err != nil {
+		t.Fatalf("openapi3proto.Export() error [%v]", err)
+	}
+	out = string(src)
+	for _, want := range []string{"  reserved 1, 9;\n", "  int64 age = 10;", "  string name = 2;"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("openapi3proto.Export() stable numbers mismatch: want [%s]\n%s", want, out)
+		}
+	}
+}
+
+const stableTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Shapes", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Base": {"type": "object", "properties": {"a": {"type": "string"}, "b": {"type": "string"}}},
+      "Child": {"allOf": [{"$ref": "#/components/schemas/Base"}, {"type": "object", "properties": {"c": {"type": "string"}}}]},
+      "Color": {"type": "string", "enum": ["red", "green", "blue"]},
+      "Shape": {"oneOf": [{"$ref": "#/components/schemas/Base"}, {"type": "string"}]}
+    }
+  }
+}`
+
+func TestAssignFieldNumbersStable(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(stableTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	if err := AssignFieldNumbers(spec); err != nil {
+		t.Fatalf("openapi3proto.AssignFieldNumbers() error [%v]", err)
+	}
+	schemas := spec.Components.Schemas
+
+	// Properties added to a base do not collide with its children, enum
+	// values and union variants keep their numbers when reordered and
+	// removed numbers are reserved.
+	schemas["Base"].Value.Properties["d"] = oas3.NewSchemaRef("", oas3.NewStringSchema())
+	schemas["Color"].Value.Enum = []any{"blue", "red", "purple"}
+	shape := schemas["Shape"].Value
+	shape.OneOf = oas3.SchemaRefs{shape.OneOf[1], shape.OneOf[0], oas3.NewSchemaRef("", oas3.NewBoolSchema())}
+	src, err := Export(spec, nil)
+	if err != nil {
+		t.Fatalf("openapi3proto.Export() error [%v]", err)
+	}
+	out := string(src)
+	for _, want := range []string{
+		"message Base {\n  string a = 1;\n  string b = 2;\n  string d = 3;\n}",
+		"message Child {\n  string a = 1;\n  string b = 2;\n  string d = 3;\n  string c = 1001;\n}",
+		"enum Color {\n  reserved 2;\n  COLOR_UNSPECIFIED = 0;\n  COLOR_RED = 1;\n  COLOR_BLUE = 3;\n  COLOR_PURPLE = 4;\n}",
+		"    Base base = 1;\n    string string = 2;\n    bool bool = 3;\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("openapi3proto.Export() stable numbers mismatch: want [%s]\n%s", want, out)
+		}
+	}
+}
+
+const importTestProto = `syntax = "proto3";
+
+package pets.v1;
+
+import "google/protobuf/timestamp.proto";
+
+// A pet.
+message Pet {
+  int64 id = 1;
+  // Display name.
+  string name = 2; // trailing
+  repeated string tags = 3;
+  map<string, Owner> owners = 4;
+  google.protobuf.Timestamp birth_date = 5;
+  google.protobuf.StringValue nickname = 6;
+  Status status = 7 [json_name = "state"];
+  Address address = 8;
+  oneof choice {
+    string text = 9;
+    .pets.v1.Owner owner = 10;
+  }
+
+  message Address {
+    string city = 1;
+  }
+}
+
+message Owner {
+  reserved 2;
+  string user_id = 1;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+}
+
+service PetService {
+  rpc GetPet(Pet) returns (Pet) {}
+}
+`
+
+func TestImport(t *testing.T) {
+	schemas, err := Import([]byte(importTestProto))
+	if err != nil {
+		t.Fatalf("openapi3proto.Import() error [%v]", err)
+	}
+	if len(schemas) != 4 {
+		t.Fatalf("openapi3proto.Import() schema count mismatch: want [4], got [%d]", len(schemas))
+	}
+	pet := schemas["Pet"].Value
+	tests := []struct {
+		prop   string
+		typ    string
+		format string
+		number int
+	}{
+		{"id", "integer", "int64", 1},
+		{"name", "string", "", 2},
+		{"tags", "array", "", 3},
+		{"owners", "object", "", 4},
+		{"birthDate", "string", "date-time", 5},
+		{"nickname", "string", "", 6},
+		{"text", "string", "", 9},
+	}
+	for _, tt := range tests {
+		propRef, ok := pet.Properties[tt.prop]
+		if !ok {
+			t.Errorf("openapi3proto.Import() property missing [%s]", tt.prop)
+			continue
+		}
+		if propRef.Value.Type != tt.typ || propRef.Value.Format != tt.format || propRef.Value.Extensions[XProtoFieldNumber] != tt.number {
+			t.Errorf("openapi3proto.Import() property [%s] mismatch: want [%s %s %d], got [%s %s %v]", tt.prop,
+				tt.typ, tt.format, tt.number, propRef.Value.Type, propRef.Value.Format, propRef.Value.Extensions[XProtoFieldNumber])
+		}
+	}
+	if got := pet.Properties["name"].Value.Description; got != "Display name." {
+		t.Errorf("openapi3proto.Import() description mismatch: want [Display name.], got [%s]", got)
+	}
+	if !pet.Properties["nickname"].Value.Nullable {
+		t.Errorf("openapi3proto.Import() wrapper not nullable")
+	}
+	if got := pet.Properties["state"].Value.AllOf[0].Ref; got != "#/components/schemas/Status" {
+		t.Errorf("openapi3proto.Import() json_name ref mismatch: got [%s]", got)
+	}
+	if got := pet.Properties["address"].Value.AllOf[0].Ref; got != "#/components/schemas/PetAddress" {
+		t.Errorf("openapi3proto.Import() nested ref mismatch: got [%s]", got)
+	}
+	if got := pet.Properties["owner"].Value.AllOf[0].Ref; got != "#/components/schemas/Owner" {
+		t.Errorf("openapi3proto.Import() qualified ref mismatch: got [%s]", got)
+	}
+	if got := len(schemas["Status"].Value.Enum); got != 2 {
+		t.Errorf("openapi3proto.Import() enum mismatch: want [2], got [%d]", got)
+	}
+}
@@ -0,0 +1,531 @@
+package openapi3ts
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/mogo/encoding/jsonpointer"
+	"github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/grokify/mogo/type/stringsutil"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	SuffixParameters = "Parameters"
+	SuffixRequest    = "Request"
+	SuffixResponse   = "Response"
+)
+
+// Options configures TypeScript generation.
+type Options struct {
+	// OperationTags limits operation types to operations with these tags,
+	// as with `SpecMore.Operations()`. All operations are used when empty.
+	OperationTags []string
+	// SkipOperations disables the generation of operation types.
+	SkipOperations bool
+}
+
+// Generate returns TypeScript declarations for the spec. Each component
+// schema becomes an exported `interface` or `type`. For each operation with
+// an `operationId`, `{OperationId}Parameters`, `{OperationId}Request` and
+// `{OperationId}Response` types are added for parameters, the JSON request
+// body and the union of success response bodies, with a numeric suffix if the
+// name is already used. `oneOf` and `anyOf` are unions, enums are literal
+// unions and `nullable` adds `| null`.
+func Generate(spec *openapi3.Spec, opts *Options) ([]byte, error) {
+	if spec == nil {
+		return nil, openapi3.ErrSpecNotSet
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	g := &generator{spec: spec, names: map[string]string{}}
+	g.sb.WriteString("// Code generated by spectrum openapi3ts. DO NOT EDIT.\n")
+	if spec.Components != nil {
+		names := []string{}
+		for name := range spec.Components.Schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := g.schemaDecl(name, spec.Components.Schemas[name]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !opts.SkipOperations {
+		sm := openapi3.SpecMore{Spec: spec}
+		oms := sm.Operations(opts.OperationTags)
+		if oms != nil {
+			ops := *oms
+			sort.SliceStable(ops, func(i, j int) bool {
+				return ops[i].Operation.OperationID < ops[j].Operation.OperationID
+			})
+			for _, om := range ops {
+				if err := g.operationDecls(om); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return []byte(g.sb.String()), nil
+}
+
+// WriteFile writes the TypeScript declarations for the spec.
+func WriteFile(filename string, spec *openapi3.Spec, opts *Options, perm os.FileMode) error {
+	src, err := Generate(spec, opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, src, perm)
+}
+
+type generator struct {
+	spec  *openapi3.Spec
+	sb    strings.Builder
+	names map[string]string // TypeScript name to source name, for collisions
+}
+
+// TypeName converts a schema name or operationId to a TypeScript type name,
+// e.g. `user-profile` to `UserProfile` and `listUsers` to `ListUsers`.
+func TypeName(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$'
+	})
+	var sb strings.Builder
+	for _, part := range parts {
+		sb.WriteString(stringsutil.ToUpperFirst(part, false))
+	}
+	name := sb.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+func (g *generator) reserve(tsName, srcName string) error {
+	if existing, ok := g.names[tsName]; ok {
+		return fmt.Errorf("typescript name collision [%s] for [%s] and [%s]", tsName, existing, srcName)
+	}
+	g.names[tsName] = srcName
+	return nil
+}
+
+// reserveUnique reserves `tsName`, adding a numeric suffix if it is already
+// used, e.g. by a component schema, and returns the reserved name.
+func (g *generator) reserveUnique(tsName, srcName string) string {
+	name := tsName
+	for i := 2; g.names[name] != ""; i++ {
+		name = tsName + strconv.Itoa(i)
+	}
+	g.names[name] = srcName
+	return name
+}
+
+// schemaDecl writes an `interface` for object schemas and a `type` alias
+// otherwise.
+func (g *generator) schemaDecl(name string, schRef *oas3.SchemaRef) error {
+	tsName := TypeName(name)
+	if err := g.reserve(tsName, name); err != nil {
+		return err
+	}
+	g.sb.WriteString("\n")
+	if schRef != nil && schRef.Ref == "" && schRef.Value != nil {
+		sch := schRef.Value
+		g.sb.WriteString(docComment("", sch.Description, sch.Deprecated))
+		if extends, props, ok := interfaceParts(sch); ok {
+			g.sb.WriteString("export interface " + tsName)
+			if len(extends) > 0 {
+				g.sb.WriteString(" extends " + strings.Join(extends, ", "))
+			}
+			g.sb.WriteString(" " + objectLiteral(props, "") + "\n")
+			return nil
+		}
+	}
+	g.sb.WriteString(fmt.Sprintf("export type %s = %s;\n", tsName, TypeExpr(schRef, "")))
+	return nil
+}
+
+// interfaceParts returns the extended interfaces and merged object schema
+// for schemas that can be written as interfaces: non-nullable objects with
+// properties and `allOf` lists of references and inline objects.
+func interfaceParts(sch *oas3.Schema) ([]string, *oas3.Schema, bool) {
+	if sch.Nullable || len(sch.OneOf) > 0 || len(sch.AnyOf) > 0 || len(sch.Enum) > 0 {
+		return nil, nil, false
+	} else if len(sch.AllOf) == 0 {
+		return nil, sch, len(sch.Properties) > 0 && sch.AdditionalProperties.Schema == nil
+	}
+	extends := []string{}
+	merged := oas3.NewObjectSchema()
+	for _, item := range append(oas3.SchemaRefs{oas3.NewSchemaRef("", sch)}, sch.AllOf...) {
+		if item == nil {
+			continue
+		} else if item.Ref != "" {
+			extends = append(extends, TypeName(refName(item.Ref)))
+		} else if item.Value == nil || len(item.Value.OneOf) > 0 || len(item.Value.AnyOf) > 0 ||
+			(len(item.Value.AllOf) > 0 && item.Value != sch) {
+			return nil, nil, false
+		} else {
+			for propName, propRef := range item.Value.Properties {
+				merged.Properties[propName] = propRef
+			}
+			merged.Required = append(merged.Required, item.Value.Required...)
+		}
+	}
+	return extends, merged, true
+}
+
+// TypeExpr returns the TypeScript type expression for a schema. Inline
+// objects are written as object literal types indented with `indent`.
+func TypeExpr(schRef *oas3.SchemaRef, indent string) string {
+	if schRef == nil {
+		return "unknown"
+	} else if schRef.Ref != "" {
+		return TypeName(refName(schRef.Ref))
+	} else if schRef.Value == nil {
+		return "unknown"
+	}
+	sch := schRef.Value
+	expr := typeExprValue(sch, indent)
+	if sch.Nullable && expr != "unknown" {
+		expr += " | null"
+	}
+	return expr
+}
+
+func typeExprValue(sch *oas3.Schema, indent string) string {
+	switch {
+	case len(sch.OneOf) > 0:
+		return unionExpr(sch.OneOf, " | ", indent)
+	case len(sch.AnyOf) > 0:
+		return unionExpr(sch.AnyOf, " | ", indent)
+	case len(sch.AllOf) > 0:
+		expr := unionExpr(sch.AllOf, " & ", indent)
+		if len(sch.Properties) > 0 {
+			expr += " & " + objectLiteral(sch, indent)
+		}
+		return expr
+	case len(sch.Enum) > 0:
+		vals := []string{}
+		for _, v := range sch.Enum {
+			if v == nil {
+				vals = append(vals, "null")
+			} else if b, err := jsonLiteral(v); err == nil {
+				vals = append(vals, b)
+			}
+		}
+		return strings.Join(vals, " | ")
+	}
+	switch sch.Type {
+	case openapi3.TypeString:
+		if sch.Format == "binary" {
+			return "Blob"
+		}
+		return "string"
+	case openapi3.TypeInteger, openapi3.TypeNumber:
+		return "number"
+	case openapi3.TypeBoolean:
+		return "boolean"
+	case openapi3.TypeArray:
+		items := TypeExpr(sch.Items, indent)
+		if strings.Contains(items, " | ") || strings.Contains(items, " & ") {
+			items = "(" + items + ")"
+		}
+		return items + "[]"
+	case openapi3.TypeObject, "":
+		if len(sch.Properties) > 0 {
+			expr := objectLiteral(sch, indent)
+			if aps := sch.AdditionalProperties.Schema; aps != nil {
+				expr += " & Record<string, " + TypeExpr(aps, indent) + ">"
+			}
+			return expr
+		} else if aps := sch.AdditionalProperties.Schema; aps != nil {
+			return "Record<string, " + TypeExpr(aps, indent) + ">"
+		} else if sch.Type == openapi3.TypeObject {
+			return "Record<string, unknown>"
+		}
+	}
+	return "unknown"
+}
+
+func unionExpr(refs oas3.SchemaRefs, sep, indent string) string {
+	parts := []string{}
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		part := TypeExpr(ref, indent)
+		if strings.Contains(part, " | ") && sep == " & " {
+			part = "(" + part + ")"
+		}
+		if !seen[part] {
+			parts = append(parts, part)
+			seen[part] = true
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// objectLiteral returns an object literal type for the schema properties,
+// sorted by name. Properties not in `required` are optional.
+func objectLiteral(sch *oas3.Schema, indent string) string {
+	if len(sch.Properties) == 0 {
+		return "{}"
+	}
+	required := map[string]bool{}
+	for _, req := range sch.Required {
+		required[req] = true
+	}
+	propNames := []string{}
+	for propName := range sch.Properties {
+		propNames = append(propNames, propName)
+	}
+	sort.Strings(propNames)
+	inner := indent + "  "
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for _, propName := range propNames {
+		propRef := sch.Properties[propName]
+		if propRef != nil && propRef.Ref == "" && propRef.Value != nil {
+			sb.WriteString(docComment(inner, propRef.Value.Description, propRef.Value.Deprecated))
+		}
+		opt := ""
+		if !required[propName] {
+			opt = "?"
+		}
+		sb.WriteString(fmt.Sprintf("%s%s%s: %s;\n", inner, PropertyName(propName), opt, TypeExpr(propRef, inner)))
+	}
+	sb.WriteString(indent + "}")
+	return sb.String()
+}
+
+// PropertyName returns the property name, quoted if it is not a valid
+// identifier.
+func PropertyName(name string) string {
+	for i, r := range name {
+		if !(unicode.IsLetter(r) || r == '_' || r == '$' || (i > 0 && unicode.IsDigit(r))) {
+			return strconv.Quote(name)
+		}
+	}
+	if name == "" {
+		return `""`
+	}
+	return name
+}
+
+func docComment(indent, desc string, deprecated bool) string {
+	lines := []string{}
+	if desc = strings.TrimSpace(desc); desc != "" {
+		lines = strings.Split(strings.ReplaceAll(desc, "*/", "*\\/"), "\n")
+	}
+	if deprecated {
+		lines = append(lines, "@deprecated")
+	}
+	if len(lines) == 0 {
+		return ""
+	} else if len(lines) == 1 {
+		return indent + "/** " + lines[0] + " */\n"
+	}
+	var sb strings.Builder
+	sb.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		sb.WriteString(strings.TrimRight(indent+" * "+line, " ") + "\n")
+	}
+	sb.WriteString(indent + " */\n")
+	return sb.String()
+}
+
+// operationDecls writes the parameter, request and response types of an
+// operation.
+func (g *generator) operationDecls(om openapi3.OperationMore) error {
+	op := om.Operation
+	if op == nil || strings.TrimSpace(op.OperationID) == "" {
+		return nil
+	}
+	base := TypeName(op.OperationID)
+
+	// Path item parameters apply unless the operation overrides them.
+	paramRefs := oas3.Parameters{}
+	if g.spec.Paths != nil {
+		if pathItem := g.spec.Paths.Find(om.Path); pathItem != nil {
+			paramRefs = append(paramRefs, pathItem.Parameters...)
+		}
+	}
+	paramRefs = append(paramRefs, op.Parameters...)
+	paramKeys := []string{}
+	paramsByKey := map[string]*oas3.Parameter{}
+	locations := map[string]map[string]bool{}
+	for _, paramRef := range paramRefs {
+		param := g.parameter(paramRef)
+		if param == nil || param.In == openapi3.InCookie {
+			continue
+		}
+		key := param.In + " " + param.Name
+		if _, ok := paramsByKey[key]; !ok {
+			paramKeys = append(paramKeys, key)
+		}
+		paramsByKey[key] = param
+		if locations[param.Name] == nil {
+			locations[param.Name] = map[string]bool{}
+		}
+		locations[param.Name][param.In] = true
+	}
+	params := oas3.NewObjectSchema()
+	for _, key := range paramKeys {
+		param := paramsByKey[key]
+		propRef := param.Schema
+		if propRef == nil {
+			propRef = oas3.NewSchemaRef("", oas3.NewStringSchema())
+		}
+		if param.Description != "" && propRef.Ref == "" && propRef.Value != nil && propRef.Value.Description == "" {
+			propRef = oas3.NewSchemaRef("", copySchema(propRef.Value))
+			propRef.Value.Description = param.Description
+		}
+		propName := param.Name
+		if len(locations[param.Name]) > 1 {
+			// Names used in more than one location are qualified by location,
+			// e.g. `idPath` and `idQuery`.
+			propName += TypeName(param.In)
+		}
+		params.Properties[propName] = propRef
+		if param.Required && !slices.Contains(params.Required, propName) {
+			params.Required = append(params.Required, propName)
+		}
+	}
+	if len(params.Properties) > 0 {
+		name := g.reserveUnique(base+SuffixParameters, op.OperationID)
+		g.sb.WriteString(fmt.Sprintf("\nexport interface %s %s\n", name, objectLiteral(params, "")))
+	}
+
+	if body := g.requestBody(op.RequestBody); body != nil {
+		name := g.reserveUnique(base+SuffixRequest, op.OperationID)
+		g.sb.WriteString(fmt.Sprintf("\nexport type %s = %s;\n", name, TypeExpr(body, "")))
+	}
+
+	if resp, ok := g.successResponse(op.Responses); ok {
+		name := g.reserveUnique(base+SuffixResponse, op.OperationID)
+		g.sb.WriteString(fmt.Sprintf("\nexport type %s = %s;\n", name, resp))
+	}
+	return nil
+}
+
+func (g *generator) parameter(paramRef *oas3.ParameterRef) *oas3.Parameter {
+	if paramRef == nil {
+		return nil
+	} else if paramRef.Value != nil {
+		return paramRef.Value
+	} else if g.spec.Components != nil {
+		if p, ok := g.spec.Components.Parameters[refName(paramRef.Ref)]; ok && p != nil {
+			return p.Value
+		}
+	}
+	return nil
+}
+
+func (g *generator) requestBody(bodyRef *oas3.RequestBodyRef) *oas3.SchemaRef {
+	if bodyRef == nil {
+		return nil
+	}
+	body := bodyRef.Value
+	if body == nil && g.spec.Components != nil {
+		if b, ok := g.spec.Components.RequestBodies[refName(bodyRef.Ref)]; ok && b != nil {
+			body = b.Value
+		}
+	}
+	if body == nil {
+		return nil
+	}
+	return contentSchema(body.Content)
+}
+
+// successResponse returns the union of the 2xx response body types. A success
+// response without a body is `void`.
+func (g *generator) successResponse(resps *oas3.Responses) (string, bool) {
+	if resps == nil {
+		return "", false
+	}
+	statuses := []string{}
+	for status := range resps.Map() {
+		if code, err := strconv.Atoi(status); err == nil && code >= http.StatusOK && code < http.StatusMultipleChoices {
+			statuses = append(statuses, status)
+		} else if strings.EqualFold(status, "2XX") {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Strings(statuses)
+	parts := []string{}
+	seen := map[string]bool{}
+	for _, status := range statuses {
+		respRef := resps.Value(status)
+		var resp *oas3.Response
+		if respRef != nil {
+			resp = respRef.Value
+			if resp == nil && g.spec.Components != nil {
+				if r, ok := g.spec.Components.Responses[refName(respRef.Ref)]; ok && r != nil {
+					resp = r.Value
+				}
+			}
+		}
+		expr := "void"
+		if resp != nil {
+			if schRef := contentSchema(resp.Content); schRef != nil {
+				expr = TypeExpr(schRef, "")
+			}
+		}
+		if !seen[expr] {
+			parts = append(parts, expr)
+			seen[expr] = true
+		}
+	}
+	return strings.Join(parts, " | "), len(parts) > 0
+}
+
+// contentSchema returns the schema of the JSON media type, or of the first
+// media type if there is no JSON media type.
+func contentSchema(content oas3.Content) *oas3.SchemaRef {
+	if len(content) == 0 {
+		return nil
+	}
+	if mt, ok := content[httputilmore.ContentTypeAppJSON]; ok && mt != nil {
+		return mt.Schema
+	}
+	mts := []string{}
+	for mt := range content {
+		mts = append(mts, mt)
+	}
+	sort.Strings(mts)
+	for _, mt := range mts {
+		if strings.HasSuffix(strings.Split(mt, ";")[0], "json") && content[mt] != nil {
+			return content[mt].Schema
+		}
+	}
+	if mt := content[mts[0]]; mt != nil {
+		return mt.Schema
+	}
+	return nil
+}
+
+func copySchema(sch *oas3.Schema) *oas3.Schema {
+	c := *sch
+	return &c
+}
+
+// refName returns the last segment of a local reference, e.g. `User` for
+// `#/components/schemas/User`.
+func refName(ref string) string {
+	if idx := strings.LastIndex(ref, "/"); idx > -1 {
+		ref = ref[idx+1:]
+	}
+	return jsonpointer.PropertyNameUnescape(ref)
+}
+
+func jsonLiteral(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
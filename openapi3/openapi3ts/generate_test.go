@@ -0,0 +1,128 @@
+package openapi3ts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const generateTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {
+    "/pets/{petId}": {
+      "parameters": [{"name": "petId", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "get": {
+        "operationId": "getPet",
+        "parameters": [{"name": "expand", "in": "query", "description": "Fields to expand.", "schema": {"type": "boolean"}}],
+        "responses": {
+          "200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+          "404": {"description": "Not found"}
+        }
+      },
+      "put": {
+        "operationId": "update-pet",
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Cat"}}}},
+        "responses": {"204": {"description": "No content"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"oneOf": [{"$ref": "#/components/schemas/Cat"}, {"$ref": "#/components/schemas/Dog"}]},
+      "Cat": {
+        "description": "A cat.",
+        "allOf": [{"$ref": "#/components/schemas/PetBase"}, {"type": "object", "required": ["lives"], "properties": {"lives": {"type": "integer"}}}]
+      },
+      "Dog": {"allOf": [{"$ref": "#/components/schemas/PetBase"}]},
+      "PetBase": {
+        "type": "object",
+        "required": ["id", "status"],
+        "properties": {
+          "id": {"type": "string"},
+          "status": {"type": "string", "enum": ["available", "sold"]},
+          "nickname": {"type": "string", "nullable": true},
+          "x-rating": {"type": "number"},
+          "tags": {"type": "array", "items": {"type": "string", "nullable": true}},
+          "attributes": {"type": "object", "additionalProperties": {"type": "string"}},
+          "legacyCode": {"type": "string", "deprecated": true}
+        }
+      }
+    }
+  }
+}`
+
+func TestGenerate(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(generateTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	src, err := Generate(spec, nil)
+	if err != nil {
+		t.Fatalf("openapi3ts.Generate() error [%v]", err)
+	}
+	out := string(src)
+	for _, want := range []string{
+		"/** A cat. */\nexport interface Cat extends PetBase {\n  lives: number;\n}",
+		"export interface Dog extends PetBase {}",
+		"export type Pet = Cat | Dog;",
+		"  status: \"available\" | \"sold\";",
+		"  nickname?: string | null;",
+		"  \"x-rating\"?: number;",
+		"  tags?: (string | null)[];",
+		"  attributes?: Record<string, string>;",
+		"  /** @deprecated */\n  legacyCode?: string;",
+		"export interface GetPetParameters {\n  /** Fields to expand. */\n  expand?: boolean;\n  petId: string;\n}",
+		"export type GetPetResponse = Pet;",
+		"export type UpdatePetRequest = Cat;",
+		"export type UpdatePetResponse = void;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("openapi3ts.Generate() mismatch: want [%s]\n%s", want, out)
+		}
+	}
+}
+
+const generateCollisionTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Users", "version": "1.0.0"},
+  "paths": {
+    "/users/{id}": {
+      "get": {
+        "operationId": "getUser",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "id", "in": "query", "schema": {"type": "integer"}}
+        ],
+        "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/GetUserResponse"}}}}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "GetUserResponse": {"type": "object", "properties": {"name": {"type": "string"}}}
+    }
+  }
+}`
+
+func TestGenerateCollisions(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(generateCollisionTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	src, err := Generate(spec, nil)
+	if err != nil {
+		t.Fatalf("openapi3ts.Generate() error [%v]", err)
+	}
+	out := string(src)
+	for _, want := range []string{
+		"export interface GetUserResponse {\n  name?: string;\n}",
+		"export type GetUserResponse2 = GetUserResponse;",
+		"export interface GetUserParameters {\n  idPath: string;\n  idQuery?: number;\n}",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("openapi3ts.Generate() mismatch: want [%s]\n%s", want, out)
+		}
+	}
+}
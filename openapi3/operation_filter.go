@@ -0,0 +1,128 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+)
+
+// OperationFilter selects operations. All criteria which are set must match.
+type OperationFilter struct {
+	// Tags matches operations with any of the tags.
+	Tags    []string `json:"tags,omitempty"`
+	Methods []string `json:"methods,omitempty"`
+	// PathGlob matches paths where `*` matches within a path segment and
+	// `**` matches across segments, e.g. `/users/*` or `/admin/**`.
+	PathGlob     string   `json:"pathGlob,omitempty"`
+	OperationIDs []string `json:"operationIds,omitempty"`
+	// Extensions matches operations with equal extension values, e.g.
+	// `{"x-internal": true}`.
+	Extensions map[string]any `json:"extensions,omitempty"`
+	Deprecated *bool          `json:"deprecated,omitempty"`
+}
+
+// IsEmpty returns true if no criterion is set, in which case the filter
+// matches every operation.
+func (f *OperationFilter) IsEmpty() bool {
+	return f == nil || (len(f.Tags) == 0 && len(f.Methods) == 0 && f.PathGlob == "" &&
+		len(f.OperationIDs) == 0 && len(f.Extensions) == 0 && f.Deprecated == nil)
+}
+
+// Func returns a function which reports whether an operation matches the
+// filter, for use with `VisitOperations()` and `SpecEdit.DeleteOperations()`.
+func (f *OperationFilter) Func() (func(path, method string, op *oas3.Operation) bool, error) {
+	if f == nil {
+		return func(path, method string, op *oas3.Operation) bool { return op != nil }, nil
+	}
+	var rxPath *regexp.Regexp
+	if f.PathGlob != "" {
+		var err error
+		if rxPath, err = regexp.Compile(globToRegexp(f.PathGlob)); err != nil {
+			return nil, err
+		}
+	}
+	extensions := map[string]string{}
+	for k, v := range f.Extensions {
+		extensions[k] = jsonString(v)
+	}
+	return func(path, method string, op *oas3.Operation) bool {
+		switch {
+		case op == nil:
+			return false
+		case len(f.Tags) > 0 && !stringsContainAny(op.Tags, f.Tags):
+			return false
+		case len(f.Methods) > 0 && !stringsContainFold(f.Methods, method):
+			return false
+		case rxPath != nil && !rxPath.MatchString(path):
+			return false
+		case len(f.OperationIDs) > 0 && !stringsContainAny(f.OperationIDs, []string{op.OperationID}):
+			return false
+		case f.Deprecated != nil && op.Deprecated != *f.Deprecated:
+			return false
+		}
+		for k, v := range extensions {
+			opVal, ok := op.Extensions[k]
+			if !ok || jsonString(opVal) != v {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// globToRegexp converts a path glob to an anchored regular expression.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			sb.WriteString("[^/]*")
+		case glob[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+func jsonString(v any) string {
+	if raw, ok := v.(json.RawMessage); ok {
+		var val any
+		if err := json.Unmarshal(raw, &val); err == nil {
+			v = val
+		}
+	}
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(bytes)
+}
+
+func stringsContainAny(haystack, needles []string) bool {
+	for _, h := range haystack {
+		for _, n := range needles {
+			if strings.TrimSpace(h) == strings.TrimSpace(n) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func stringsContainFold(haystack []string, needle string) bool {
+	for _, h := range haystack {
+		if strings.EqualFold(strings.TrimSpace(h), needle) {
+			return true
+		}
+	}
+	return false
+}
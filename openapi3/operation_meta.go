@@ -1,6 +1,8 @@
 package openapi3
 
 import (
+	"net/http"
+	"strconv"
 	"strings"
 
 	oas3 "github.com/getkin/kin-openapi/openapi3"
@@ -56,6 +58,17 @@ type OperationMeta struct {
 	MetaNotes            []string `json:"metaNotes,omitempty"`
 	XThrottlingGroup     string   `json:"x-throttlingGroup,omitempty"`
 	RequestBodySchemaRef string   `json:"requestBodySchemaRef,omitempty"`
+	// RequestBodySchemaRefUse has `Operation()` build a request body from
+	// `RequestBodySchemaRef` when `RequestBodySchema` is not set.
+	RequestBodySchemaRefUse bool `json:"requestBodySchemaRefUse,omitempty"`
+	// RequestBodySchema takes precedence over `RequestBodySchemaRef`.
+	RequestBodySchema    *oas3.SchemaRef `json:"requestBodySchema,omitempty"`
+	RequestBodyMediaType string          `json:"requestBodyMediaType,omitempty"`
+	RequestBodyRequired  bool            `json:"requestBodyRequired,omitempty"`
+	Parameters           oas3.Parameters `json:"parameters,omitempty"`
+	ResponseStatus       string          `json:"responseStatus,omitempty"`
+	ResponseSchema       *oas3.SchemaRef `json:"responseSchema,omitempty"`
+	ResponseMediaType    string          `json:"responseMediaType,omitempty"`
 }
 
 func (om *OperationMeta) Operation() *oas3.Operation {
@@ -64,9 +77,50 @@ func (om *OperationMeta) Operation() *oas3.Operation {
 	op.OperationID = om.OperationID
 	op.Summary = om.Summary
 	op.Tags = slices.Clone(om.Tags)
+	op.Parameters = slices.Clone(om.Parameters)
+	reqSchRef := om.RequestBodySchema
+	if reqSchRef == nil && om.RequestBodySchemaRefUse && strings.TrimSpace(om.RequestBodySchemaRef) != "" {
+		reqSchRef = oas3.NewSchemaRef(SchemaPointerExpand("", strings.TrimSpace(om.RequestBodySchemaRef)), nil)
+	}
+	if reqSchRef != nil {
+		reqBody := oas3.NewRequestBody().WithContent(
+			oas3.NewContentWithSchemaRef(reqSchRef, []string{mediaTypeOrDefault(om.RequestBodyMediaType)}))
+		reqBody.Required = om.RequestBodyRequired
+		op.RequestBody = &oas3.RequestBodyRef{Value: reqBody}
+	}
+	if om.ResponseStatus != "" || om.ResponseSchema != nil {
+		status := strings.TrimSpace(om.ResponseStatus)
+		if status == "" {
+			status = "200"
+		}
+		resp := oas3.NewResponse().WithDescription(ResponseDescriptionDefault(status))
+		if om.ResponseSchema != nil {
+			resp.Content = oas3.NewContentWithSchemaRef(om.ResponseSchema, []string{mediaTypeOrDefault(om.ResponseMediaType)})
+		}
+		op.Responses = oas3.NewResponsesWithCapacity(1)
+		op.Responses.Set(status, &oas3.ResponseRef{Value: resp})
+	}
 	return op
 }
 
+func mediaTypeOrDefault(mediaType string) string {
+	if mediaType = strings.TrimSpace(mediaType); mediaType != "" {
+		return mediaType
+	}
+	return "application/json"
+}
+
+// ResponseDescriptionDefault returns a response description for a status
+// code, using the HTTP status text if known, e.g. `Not Found` for `404`.
+func ResponseDescriptionDefault(status string) string {
+	if code, err := strconv.Atoi(status); err == nil {
+		if text := http.StatusText(code); text != "" {
+			return text
+		}
+	}
+	return "Response " + status
+}
+
 func (om *OperationMeta) PathMethod() string {
 	return pathmethod.PathMethod(om.Path, om.Method)
 }
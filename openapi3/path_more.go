@@ -37,3 +37,82 @@ func (pm *PathItemMore) AddPathItemOperations(add *oas3.PathItem, overwriteOprat
 	}
 	return nil
 }
+
+// MergePathItemOperations adds the operations of `add` for the path `path`
+// using the operation collision strategy of `mergeOpts`.
+func (pm *PathItemMore) MergePathItemOperations(path string, add *oas3.PathItem, specExtraNote string, mergeOpts *MergeOptions) error {
+	if add == nil {
+		return nil
+	} else if pm.PathItem == nil {
+		return errors.New("path item is not set")
+	}
+	if mergeOpts == nil {
+		mergeOpts = &MergeOptions{}
+	}
+	for _, method := range httputilmore.Methods() {
+		opAdd := add.GetOperation(method)
+		if opAdd == nil {
+			continue
+		}
+		opSrc := pm.PathItem.GetOperation(method)
+		if opSrc == nil {
+			pm.PathItem.SetOperation(method, opAdd)
+			continue
+		}
+		opName := method + " " + path
+		if reflect.DeepEqual(opAdd, opSrc) {
+			mergeOpts.recordCollision("operations", opName, specExtraNote, MergeResolutionSame)
+			continue
+		}
+		if mergeOpts.OperationFunc != nil {
+			op, err := mergeOpts.OperationFunc(path, method, opSrc, opAdd, specExtraNote)
+			if err != nil {
+				mergeOpts.recordCollision("operations", opName, specExtraNote, MergeResolutionError)
+				return err
+			}
+			mergeOpts.recordCollision("operations", opName, specExtraNote, MergeResolutionCallback)
+			pm.PathItem.SetOperation(method, op)
+			continue
+		}
+		switch mergeOpts.OperationCollision {
+		case OperationCollisionKeepFirst:
+			mergeOpts.recordCollision("operations", opName, specExtraNote, MergeResolutionKeepFirst)
+		case OperationCollisionOverwrite:
+			mergeOpts.recordCollision("operations", opName, specExtraNote, MergeResolutionOverwrite)
+			pm.PathItem.SetOperation(method, opAdd)
+		case OperationCollisionDeepMerge:
+			mergeOpts.recordCollision("operations", opName, specExtraNote, MergeResolutionMerge)
+			OperationDeepMerge(opSrc, opAdd)
+		default:
+			mergeOpts.recordCollision("operations", opName, specExtraNote, MergeResolutionError)
+			return fmt.Errorf("E_OPERATION_COLLISION PATH [%s] METHOD [%s] OPERATION_ID [%s] EXTRA_SPEC [%s]",
+				path, method, opSrc.OperationID, specExtraNote)
+		}
+	}
+	return nil
+}
+
+// OperationDeepMerge adds the parameters of `add`, by `in` and `name`, and
+// responses of `add`, by status code, which `op` does not have to `op`.
+func OperationDeepMerge(op, add *oas3.Operation) {
+	if op == nil || add == nil {
+		return
+	}
+	for _, paramRef := range add.Parameters {
+		if paramRef == nil || paramRef.Value == nil {
+			continue
+		} else if op.Parameters.GetByInAndName(paramRef.Value.In, paramRef.Value.Name) == nil {
+			op.Parameters = append(op.Parameters, paramRef)
+		}
+	}
+	if add.Responses == nil {
+		return
+	} else if op.Responses == nil {
+		op.Responses = oas3.NewResponsesWithCapacity(add.Responses.Len())
+	}
+	for status, respRef := range add.Responses.Map() {
+		if op.Responses.Value(status) == nil {
+			op.Responses.Set(status, respRef)
+		}
+	}
+}
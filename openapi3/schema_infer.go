@@ -0,0 +1,284 @@
+package openapi3
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	SchemaInferEnumMaxValuesDefault  = 5
+	SchemaInferEnumMinSamplesDefault = 5
+	SchemaInferRequiredRatioDefault  = 1.0
+)
+
+var rxUUID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// SchemaInferOptions configures schema inference from sample JSON documents.
+type SchemaInferOptions struct {
+	// RequiredRatio is the minimum frequency, from 0 to 1, at which a property
+	// must be present in object samples to be required. Defaults to 1.
+	RequiredRatio float64
+	// EnumMaxValues is the maximum number of distinct string values inferred as
+	// an enum. Defaults to 5. Use a negative value to disable enums.
+	EnumMaxValues int
+	// EnumMinSamples is the minimum number of string samples needed to infer an
+	// enum. Defaults to 5.
+	EnumMinSamples int
+}
+
+func (opts *SchemaInferOptions) defaults() SchemaInferOptions {
+	o := SchemaInferOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.RequiredRatio <= 0 {
+		o.RequiredRatio = SchemaInferRequiredRatioDefault
+	}
+	if o.EnumMaxValues == 0 {
+		o.EnumMaxValues = SchemaInferEnumMaxValuesDefault
+	}
+	if o.EnumMinSamples <= 0 {
+		o.EnumMinSamples = SchemaInferEnumMinSamplesDefault
+	}
+	return o
+}
+
+// SchemaInferer infers an `oas3.Schema` from one or more sample values.
+// Samples are accumulated so the schema reflects all documents, e.g. a
+// property is only required if it is present in all object samples.
+type SchemaInferer struct {
+	opts SchemaInferOptions
+	root *schemaSamples
+}
+
+func NewSchemaInferer(opts *SchemaInferOptions) *SchemaInferer {
+	return &SchemaInferer{
+		opts: opts.defaults(),
+		root: newSchemaSamples()}
+}
+
+// AddJSON adds a JSON document sample.
+func (si *SchemaInferer) AddJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	si.root.add(v, si.opts.EnumMaxValues)
+	return nil
+}
+
+// Add adds a decoded sample such as the output of `json.Unmarshal`.
+func (si *SchemaInferer) Add(v any) {
+	si.root.add(v, si.opts.EnumMaxValues)
+}
+
+// AddText adds a sample from a text value such as a query parameter or header,
+// inferring integer, number and boolean values.
+func (si *SchemaInferer) AddText(s string) {
+	if _, err := strconv.ParseFloat(s, 64); err == nil && json.Valid([]byte(s)) {
+		si.Add(json.Number(s))
+	} else if s == "true" || s == "false" {
+		si.Add(s == "true")
+	} else {
+		si.Add(s)
+	}
+}
+
+// Count returns the number of samples added.
+func (si *SchemaInferer) Count() int {
+	return si.root.count
+}
+
+// Schema returns the schema inferred from all samples.
+func (si *SchemaInferer) Schema() *oas3.Schema {
+	return si.root.schema(si.opts)
+}
+
+// InferSchema infers a schema from one or more JSON documents.
+func InferSchema(opts *SchemaInferOptions, docs ...[]byte) (*oas3.Schema, error) {
+	si := NewSchemaInferer(opts)
+	for _, doc := range docs {
+		if err := si.AddJSON(doc); err != nil {
+			return nil, err
+		}
+	}
+	return si.Schema(), nil
+}
+
+// SchemaInferSet infers a schema from one or more JSON documents and sets it
+// as `#/components/schemas/{schemaName}`.
+func (sm *SpecMore) SchemaInferSet(schemaName string, opts *SchemaInferOptions, docs ...[]byte) error {
+	if sm.Spec == nil {
+		return ErrSpecNotSet
+	}
+	sch, err := InferSchema(opts, docs...)
+	if err != nil {
+		return err
+	}
+	if sm.Spec.Components == nil {
+		sm.Spec.Components = &oas3.Components{}
+	}
+	return sm.SchemaRefSet(schemaName, oas3.NewSchemaRef("", sch))
+}
+
+// schemaSamples accumulates values observed at the same location.
+type schemaSamples struct {
+	count   int
+	nulls   int
+	types   map[string]int
+	formats map[string]int
+	strings map[string]int
+	objects int
+	props   map[string]*schemaSamples
+	items   *schemaSamples
+}
+
+func newSchemaSamples() *schemaSamples {
+	return &schemaSamples{
+		types:   map[string]int{},
+		formats: map[string]int{},
+		strings: map[string]int{},
+		props:   map[string]*schemaSamples{}}
+}
+
+func (ss *schemaSamples) add(val any, enumMaxValues int) {
+	ss.count++
+	switch v := val.(type) {
+	case nil:
+		ss.nulls++
+	case bool:
+		ss.types[TypeBoolean]++
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			ss.types[TypeInteger]++
+		} else {
+			ss.types[TypeNumber]++
+		}
+	case float64:
+		if v == float64(int64(v)) {
+			ss.types[TypeInteger]++
+		} else {
+			ss.types[TypeNumber]++
+		}
+	case string:
+		ss.types[TypeString]++
+		ss.formats[StringFormat(v)]++
+		// only track distinct values while they can still become an enum
+		if _, ok := ss.strings[v]; ok || len(ss.strings) <= enumMaxValues {
+			ss.strings[v]++
+		}
+	case []any:
+		ss.types[TypeArray]++
+		if ss.items == nil {
+			ss.items = newSchemaSamples()
+		}
+		for _, item := range v {
+			ss.items.add(item, enumMaxValues)
+		}
+	case map[string]any:
+		ss.types[TypeObject]++
+		ss.objects++
+		for k, propVal := range v {
+			prop, ok := ss.props[k]
+			if !ok {
+				prop = newSchemaSamples()
+				ss.props[k] = prop
+			}
+			prop.add(propVal, enumMaxValues)
+		}
+	}
+}
+
+func (ss *schemaSamples) schema(opts SchemaInferOptions) *oas3.Schema {
+	sch := oas3.NewSchema()
+	types := []string{}
+	for t := range ss.types {
+		types = append(types, t)
+	}
+	if len(types) == 2 && ss.types[TypeInteger] > 0 && ss.types[TypeNumber] > 0 {
+		types = []string{TypeNumber}
+	}
+	if len(types) != 1 {
+		// mixed or unknown types are left unconstrained
+		sch.Nullable = ss.nulls > 0 && len(types) > 0
+		return sch
+	}
+	sch.Type = types[0]
+	sch.Nullable = ss.nulls > 0
+	switch sch.Type {
+	case TypeString:
+		if len(ss.formats) == 1 {
+			for format := range ss.formats {
+				sch.Format = format
+			}
+		}
+		strCount := ss.types[TypeString]
+		if sch.Format == "" && opts.EnumMaxValues > 0 && len(ss.strings) <= opts.EnumMaxValues &&
+			strCount >= opts.EnumMinSamples && len(ss.strings) < strCount {
+			for _, v := range sortedMapKeys(ss.strings) {
+				sch.Enum = append(sch.Enum, v)
+			}
+		}
+	case TypeArray:
+		if ss.items != nil && ss.items.count > 0 {
+			sch.Items = oas3.NewSchemaRef("", ss.items.schema(opts))
+		} else {
+			sch.Items = oas3.NewSchemaRef("", oas3.NewSchema())
+		}
+	case TypeObject:
+		sch.Properties = oas3.Schemas{}
+		for _, name := range sortedMapKeys(ss.props) {
+			prop := ss.props[name]
+			sch.Properties[name] = oas3.NewSchemaRef("", prop.schema(opts))
+			if ss.objects > 0 && float64(prop.count)/float64(ss.objects) >= opts.RequiredRatio {
+				sch.Required = append(sch.Required, name)
+			}
+		}
+	}
+	return sch
+}
+
+// StringFormat returns the OpenAPI format detected for a string value, one of
+// `date-time`, `date`, `uuid`, `email` or `uri`, or an empty string.
+func StringFormat(s string) string {
+	switch {
+	case len(s) == 0:
+		return ""
+	case rxUUID.MatchString(s):
+		return FormatUUID
+	}
+	if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return FormatDateTime
+	} else if _, err := time.Parse(time.DateOnly, s); err == nil {
+		return FormatDate
+	}
+	if !strings.ContainsAny(s, " <>") && strings.Count(s, "@") == 1 {
+		if addr, err := mail.ParseAddress(s); err == nil && addr.Address == s {
+			return FormatEmail
+		}
+	}
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" && u.Host != "" && !strings.ContainsAny(s, " ") {
+		return FormatURI
+	}
+	return ""
+}
+
+func sortedMapKeys[V any](m map[string]V) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
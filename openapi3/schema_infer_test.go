@@ -0,0 +1,71 @@
+package openapi3
+
+import (
+	"testing"
+)
+
+var stringFormatTests = []struct {
+	v    string
+	want string
+}{
+	{"2024-01-02T03:04:05Z", FormatDateTime},
+	{"2024-01-02", FormatDate},
+	{"123e4567-e89b-12d3-a456-426614174000", FormatUUID},
+	{"alice@example.com", FormatEmail},
+	{"https://example.com/a", FormatURI},
+	{"hello world", ""},
+}
+
+func TestStringFormat(t *testing.T) {
+	for _, tt := range stringFormatTests {
+		got := StringFormat(tt.v)
+		if got != tt.want {
+			t.Errorf("openapi3.StringFormat(\"%s\") Mismatch: want [%v], got [%v]",
+				tt.v, tt.want, got)
+		}
+	}
+}
+
+var inferSchemaDocs = []string{
+	`{"id":"123e4567-e89b-12d3-a456-426614174000","status":"active","score":1,"tags":[{"name":"a"}],"note":null}`,
+	`{"id":"223e4567-e89b-12d3-a456-426614174000","status":"active","score":1.5,"tags":[{"name":"b","color":"red"}],"note":"x"}`,
+	`{"id":"323e4567-e89b-12d3-a456-426614174000","status":"closed","score":2,"tags":[]}`,
+	`{"id":"423e4567-e89b-12d3-a456-426614174000","status":"active","score":3,"tags":[]}`,
+	`{"id":"523e4567-e89b-12d3-a456-426614174000","status":"closed","score":4,"tags":[]}`,
+}
+
+func TestInferSchema(t *testing.T) {
+	docs := [][]byte{}
+	for _, d := range inferSchemaDocs {
+		docs = append(docs, []byte(d))
+	}
+	spec := NewSpec(OASVersionDefault, "Test", "1.0")
+	sm := SpecMore{Spec: spec}
+	if err := sm.SchemaInferSet("Item", nil, docs...); err != nil {
+		t.Fatalf("openapi3.SpecMore.SchemaInferSet() error [%v]", err)
+	}
+	schRef := sm.SchemaRef("Item")
+	if schRef == nil || schRef.Value == nil {
+		t.Fatalf("openapi3.SpecMore.SchemaInferSet() schema not set")
+	}
+	sch := schRef.Value
+	if len(sch.Required) != 4 {
+		t.Errorf("openapi3.InferSchema() required mismatch: want [4], got [%v]", sch.Required)
+	}
+	if sch.Properties["id"].Value.Format != FormatUUID {
+		t.Errorf("openapi3.InferSchema() uuid format mismatch")
+	}
+	if len(sch.Properties["status"].Value.Enum) != 2 {
+		t.Errorf("openapi3.InferSchema() enum mismatch: got [%v]", sch.Properties["status"].Value.Enum)
+	}
+	if sch.Properties["score"].Value.Type != TypeNumber {
+		t.Errorf("openapi3.InferSchema() number mismatch")
+	}
+	if note := sch.Properties["note"].Value; note.Type != TypeString || !note.Nullable {
+		t.Errorf("openapi3.InferSchema() nullable mismatch")
+	}
+	items := sch.Properties["tags"].Value.Items.Value
+	if len(items.Properties) != 2 || len(items.Required) != 1 || items.Required[0] != "name" {
+		t.Errorf("openapi3.InferSchema() array items mismatch")
+	}
+}
@@ -1,11 +1,14 @@
 package openapi3
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"strings"
 
 	oas3 "github.com/getkin/kin-openapi/openapi3"
 	"github.com/grokify/mogo/encoding/jsonpointer"
+	"github.com/grokify/mogo/type/maputil"
 )
 
 func SchemaPointerExpand(prefix, schemaName string) string {
@@ -53,3 +56,88 @@ func AdditionalPropertiesExists(props oas3.AdditionalProperties) bool {
 	}
 	return true
 }
+
+// SchemaFromJSONSchema converts a JSON Schema document, such as draft-03 or
+// draft-04 schemas embedded in RAML, to an OpenAPI 3.0 schema. The `$schema`
+// and `id` keywords are removed, draft-03 boolean `required` properties are
+// collected into the parent `required` list and `type` arrays that include
+// `null` are converted to `nullable`.
+func SchemaFromJSONSchema(data []byte) (*oas3.Schema, error) {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	msa, ok := raw.(map[string]any)
+	if !ok {
+		return nil, errors.New("json schema is not an object")
+	}
+	delete(msa, "$schema")
+	delete(msa, "id")
+	jsonSchemaNormalize(msa)
+	bytes, err := json.Marshal(msa)
+	if err != nil {
+		return nil, err
+	}
+	sch := oas3.NewSchema()
+	return sch, sch.UnmarshalJSON(bytes)
+}
+
+func jsonSchemaNormalize(msa map[string]any) {
+	if types, ok := msa["type"].([]any); ok {
+		nonNull := []any{}
+		for _, t := range types {
+			if t == "null" {
+				msa["nullable"] = true
+			} else {
+				nonNull = append(nonNull, t)
+			}
+		}
+		if len(nonNull) == 1 {
+			msa["type"] = nonNull[0]
+		} else {
+			delete(msa, "type")
+		}
+	}
+	if props, ok := msa["properties"].(map[string]any); ok {
+		required := []any{}
+		if reqs, ok := msa["required"].([]any); ok {
+			required = reqs
+		}
+		// Properties are visited in order so `required` is stable.
+		for _, propName := range maputil.Keys(props) {
+			prop, ok := props[propName].(map[string]any)
+			if !ok {
+				continue
+			}
+			if req, ok := prop["required"].(bool); ok {
+				if req {
+					required = append(required, propName)
+				}
+				delete(prop, "required")
+			}
+			jsonSchemaNormalize(prop)
+		}
+		if len(required) > 0 {
+			msa["required"] = required
+		} else if _, ok := msa["required"].(bool); ok {
+			delete(msa, "required")
+		}
+	}
+	if _, ok := msa["required"].(bool); ok {
+		delete(msa, "required")
+	}
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		if child, ok := msa[key].(map[string]any); ok {
+			jsonSchemaNormalize(child)
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if children, ok := msa[key].([]any); ok {
+			for _, childAny := range children {
+				if child, ok := childAny.(map[string]any); ok {
+					jsonSchemaNormalize(child)
+				}
+			}
+		}
+	}
+}
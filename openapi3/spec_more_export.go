@@ -1,6 +1,7 @@
 package openapi3
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -66,6 +67,102 @@ func (sm *SpecMore) ExportByTag(tag string) (*Spec, error) {
 	return tagSm.Clone()
 }
 
+// Export returns a spec with the operations matching the filter and only the
+// components they reference directly or transitively, including security
+// schemes of operation and global security requirements. Tag definitions
+// are limited to the tags of the exported operations. A nil filter exports
+// all operations.
+func (sm *SpecMore) Export(filter *OperationFilter) (*Spec, error) {
+	if sm.Spec == nil {
+		return nil, ErrSpecNotSet
+	}
+	match, err := filter.Func()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := specJSONMap(sm.Spec)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	for k, v := range doc {
+		switch k {
+		case "paths", PathComponents, PropertyTags:
+		default:
+			out[k] = v
+		}
+	}
+
+	paths := map[string]any{}
+	tagNames := map[string]bool{}
+	docPaths, _ := doc["paths"].(map[string]any)
+	for path, pathItem := range sm.Spec.Paths.Map() {
+		docPathItem, ok := docPaths[path].(map[string]any)
+		if !ok || pathItem == nil {
+			continue
+		}
+		methods := map[string]bool{}
+		for _, method := range PathMethods(pathItem) {
+			op := pathItem.GetOperation(method)
+			methods[strings.ToLower(method)] = true
+			if !match(path, method, op) {
+				delete(docPathItem, strings.ToLower(method))
+				continue
+			}
+			for _, tag := range op.Tags {
+				tagNames[tag] = true
+			}
+		}
+		for k := range docPathItem {
+			if methods[k] {
+				paths[path] = docPathItem
+				break
+			}
+		}
+	}
+	out["paths"] = paths
+
+	comps, _ := doc[PathComponents].(map[string]any)
+	reachable := componentsReachable(comps, out)
+	outComps := map[string]any{}
+	for kind, v := range comps {
+		items, ok := v.(map[string]any)
+		if !ok || strings.HasPrefix(kind, "x-") {
+			outComps[kind] = v
+			continue
+		}
+		for name := range items {
+			if !reachable[ComponentKey{Kind: kind, Name: name}] {
+				delete(items, name)
+			}
+		}
+		if len(items) > 0 {
+			outComps[kind] = items
+		}
+	}
+	out[PathComponents] = outComps
+
+	if tags, ok := doc[PropertyTags].([]any); ok {
+		outTags := []any{}
+		for _, tag := range tags {
+			if tagMap, ok := tag.(map[string]any); ok {
+				if name, ok := tagMap["name"].(string); ok && tagNames[name] {
+					outTags = append(outTags, tag)
+				}
+			}
+		}
+		if len(outTags) > 0 {
+			out[PropertyTags] = outTags
+		}
+	}
+
+	bytes, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(bytes)
+}
+
 var ErrJSONPointerNotParamOrSchema = errors.New("pointer is not components/parameters or components/schemas")
 
 func (sm *SpecMore) SchemasCopyOperation(destSpec *Spec, op *oas3.Operation) error {
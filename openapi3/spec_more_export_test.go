@@ -0,0 +1,160 @@
+package openapi3
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const exportTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "security": [{"apiKey": []}],
+  "tags": [{"name": "pets"}, {"name": "admin"}],
+  "paths": {
+    "/pets": {
+      "get": {
+        "tags": ["pets"],
+        "x-audience": "partner",
+        "parameters": [{"$ref": "#/components/parameters/Limit"}],
+        "responses": {"200": {"$ref": "#/components/responses/Pets"}}
+      },
+      "post": {
+        "tags": ["pets"],
+        "security": [{"oauth": ["write"]}],
+        "x-audience": "partner",
+        "requestBody": {"$ref": "#/components/requestBodies/Pet"},
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/admin/users": {
+      "get": {
+        "tags": ["admin"],
+        "security": [{"basic": []}],
+        "responses": {"200": {"description": "Users", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "discriminator": {"propertyName": "kind", "mapping": {"dog": "#/components/schemas/Dog"}},
+        "properties": {"kind": {"type": "string"}, "owner": {"$ref": "#/components/schemas/Owner"}}
+      },
+      "Dog": {"allOf": [{"$ref": "#/components/schemas/Pet"}]},
+      "Owner": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "User": {"type": "object", "properties": {"name": {"type": "string"}}}
+    },
+    "parameters": {
+      "Limit": {"name": "limit", "in": "query", "schema": {"type": "integer"}},
+      "Offset": {"name": "offset", "in": "query", "schema": {"type": "integer"}}
+    },
+    "responses": {
+      "Pets": {
+        "description": "Pets",
+        "headers": {"X-Rate-Limit": {"$ref": "#/components/headers/RateLimit"}},
+        "content": {"application/json": {
+          "schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}},
+          "examples": {"pets": {"$ref": "#/components/examples/Pets"}}}}
+      }
+    },
+    "requestBodies": {
+      "Pet": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+    },
+    "headers": {
+      "RateLimit": {"schema": {"type": "integer"}}
+    },
+    "examples": {
+      "Pets": {"value": [{"kind": "dog", "$ref": "#/components/schemas/User"}]}
+    },
+    "securitySchemes": {
+      "apiKey": {"type": "apiKey", "in": "header", "name": "X-API-Key"},
+      "oauth": {"type": "oauth2", "flows": {"implicit": {"authorizationUrl": "https://example.com/auth", "scopes": {"write": "Write"}}}},
+      "basic": {"type": "http", "scheme": "basic"}
+    }
+  }
+}`
+
+func TestExport(t *testing.T) {
+	spec, err := Parse([]byte(exportTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	sm := SpecMore{Spec: spec}
+	tests := []struct {
+		filter     *OperationFilter
+		operations []string
+		components []string
+		tags       []string
+	}{
+		{
+			filter:     &OperationFilter{Extensions: map[string]any{"x-audience": "partner"}},
+			operations: []string{"/pets GET", "/pets POST"},
+			components: []string{
+				"#/components/examples/Pets", "#/components/headers/RateLimit",
+				"#/components/parameters/Limit", "#/components/requestBodies/Pet",
+				"#/components/responses/Pets", "#/components/schemas/Dog",
+				"#/components/schemas/Owner", "#/components/schemas/Pet",
+				"#/components/securitySchemes/apiKey", "#/components/securitySchemes/oauth"},
+			tags: []string{"pets"},
+		},
+		{
+			filter:     &OperationFilter{PathGlob: "/admin/**"},
+			operations: []string{"/admin/users GET"},
+			components: []string{
+				"#/components/schemas/User",
+				"#/components/securitySchemes/apiKey", "#/components/securitySchemes/basic"},
+			tags: []string{"admin"},
+		},
+		{
+			filter:     &OperationFilter{Methods: []string{"post"}},
+			operations: []string{"/pets POST"},
+			components: []string{
+				"#/components/requestBodies/Pet", "#/components/schemas/Dog",
+				"#/components/schemas/Owner", "#/components/schemas/Pet",
+				"#/components/securitySchemes/apiKey", "#/components/securitySchemes/oauth"},
+			tags: []string{"pets"},
+		},
+	}
+	for _, tt := range tests {
+		exp, err := sm.Export(tt.filter)
+		if err != nil {
+			t.Fatalf("openapi3.SpecMore.Export() error [%v]", err)
+		}
+		expMore := SpecMore{Spec: exp}
+		operations := expMore.PathMethods(false)
+		sort.Strings(operations)
+		if !reflect.DeepEqual(operations, tt.operations) {
+			t.Errorf("openapi3.SpecMore.Export() operations mismatch: want [%v], got [%v]", tt.operations, operations)
+		}
+		components := exportTestComponents(exp)
+		if !reflect.DeepEqual(components, tt.components) {
+			t.Errorf("openapi3.SpecMore.Export() components mismatch: want [%v], got [%v]", tt.components, components)
+		}
+		tags := []string{}
+		for _, tag := range exp.Tags {
+			tags = append(tags, tag.Name)
+		}
+		if !reflect.DeepEqual(tags, tt.tags) {
+			t.Errorf("openapi3.SpecMore.Export() tags mismatch: want [%v], got [%v]", tt.tags, tags)
+		}
+	}
+}
+
+func exportTestComponents(spec *Spec) []string {
+	doc, err := specJSONMap(spec)
+	if err != nil {
+		return nil
+	}
+	pointers := []string{}
+	comps, _ := doc[PathComponents].(map[string]any)
+	for kind, v := range comps {
+		items, _ := v.(map[string]any)
+		for name := range items {
+			pointers = append(pointers, ComponentKey{Kind: kind, Name: name}.Pointer())
+		}
+	}
+	sort.Strings(pointers)
+	return pointers
+}
@@ -0,0 +1,234 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/mogo/encoding/jsonpointer"
+	"github.com/grokify/mogo/text/stringcase"
+	"github.com/grokify/spectrum/openapi3/ontology"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	SplitRootFilenameDefault = "openapi"
+	SplitFileExtDefault      = ".yaml"
+	SplitDirPaths            = "paths"
+)
+
+// SplitComponentKinds are the component kinds written to their own files by
+// `SpecMore.Split()`. Other components stay in the root file.
+var SplitComponentKinds = []string{PathSchemas, PathParameters, "responses"}
+
+var rxSplitFilenameInvalid = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SplitOptions configures `SpecMore.Split()`. File names use the
+// `SpecFileCase`, `SpecFilePrefix`, `SpecFileSuffix` and `SpecFileExt`
+// fields of `Ontology`, as with `TagOnology.SpecFilename()`, except that
+// names are not changed when `SpecFileCase` is empty. A `SpecFileExt` of
+// `.json` writes JSON and other extensions write YAML.
+type SplitOptions struct {
+	Ontology ontology.Ontology
+	// RootFilename is the root file base name without extension. Defaults to
+	// `openapi`.
+	RootFilename string
+}
+
+func (opts *SplitOptions) ext() string {
+	if opts == nil || opts.Ontology.SpecFileExt == "" {
+		return SplitFileExtDefault
+	}
+	return opts.Ontology.SpecFileExt
+}
+
+// Filename returns the file name for a path or component name, e.g.
+// `pets_pet_id.yaml` for `/pets/{petId}` with a `SpecFileCase` of
+// `snake_case` or `pets_petId.yaml` without one.
+func (opts *SplitOptions) Filename(name string) string {
+	name = strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}'
+	}), " ")
+	if name == "" {
+		name = "root"
+	}
+	casefunc := stringcase.NoOp
+	if opts != nil {
+		casefunc = stringcase.FuncToWantCaseOrNoOp(opts.Ontology.SpecFileCase)
+		if opts.Ontology.SpecFilePrefix != "" {
+			name = opts.Ontology.SpecFilePrefix + " " + name
+		}
+		if opts.Ontology.SpecFileSuffix != "" {
+			name = name + " " + opts.Ontology.SpecFileSuffix
+		}
+	}
+	return rxSplitFilenameInvalid.ReplaceAllString(casefunc(name), "_") + opts.ext()
+}
+
+func (opts *SplitOptions) rootFilename() string {
+	if opts == nil || opts.RootFilename == "" {
+		return SplitRootFilenameDefault + opts.ext()
+	}
+	return opts.RootFilename + opts.ext()
+}
+
+// Split returns a multi-file layout of the spec keyed by relative file path:
+// one file per path item under `paths/`, one file per schema, parameter and
+// response under `components/{kind}/` and a root file whose `$ref`s point at
+// them. References between files are rewritten to relative file references.
+// `BundleFile()` reverses the split.
+func (sm *SpecMore) Split(opts *SplitOptions) (map[string][]byte, error) {
+	if sm.Spec == nil {
+		return nil, ErrSpecNotSet
+	}
+	bytes, err := sm.Spec.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	root := map[string]any{}
+	if err := json.Unmarshal(bytes, &root); err != nil {
+		return nil, err
+	}
+	s := &splitter{
+		root:     opts.rootFilename(),
+		files:    map[string]any{},
+		compFile: map[string]map[string]string{}}
+
+	// Assign file names first so references can be rewritten.
+	used := map[string]bool{s.root: true}
+	pathFiles := map[string]string{}
+	paths, _ := root["paths"].(map[string]any)
+	for _, p := range sortedMapKeys(paths) {
+		pathFiles[p] = s.uniqueFilename(used, path.Join(SplitDirPaths, opts.Filename(p)))
+	}
+	comps, _ := root[PathComponents].(map[string]any)
+	for _, kind := range SplitComponentKinds {
+		items, _ := comps[kind].(map[string]any)
+		s.compFile[kind] = map[string]string{}
+		for _, name := range sortedMapKeys(items) {
+			s.compFile[kind][name] = s.uniqueFilename(used, path.Join(PathComponents, kind, opts.Filename(name)))
+		}
+	}
+
+	for p, item := range paths {
+		filename := pathFiles[p]
+		s.files[filename] = s.rewrite(item, filename)
+		paths[p] = map[string]any{"$ref": filename}
+	}
+	for _, kind := range SplitComponentKinds {
+		items, _ := comps[kind].(map[string]any)
+		for name, item := range items {
+			filename := s.compFile[kind][name]
+			s.files[filename] = s.rewrite(item, filename)
+			items[name] = map[string]any{"$ref": filename}
+		}
+	}
+	s.files[s.root] = root
+
+	out := map[string][]byte{}
+	for filename, doc := range s.files {
+		bytes, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(opts.ext(), ".json") {
+			if bytes, err = yaml.JSONToYAML(bytes); err != nil {
+				return nil, err
+			}
+		} else {
+			bytes = append(bytes, '\n')
+		}
+		out[filename] = bytes
+	}
+	return out, nil
+}
+
+// SplitToDir writes the output of `Split()` to a directory, creating
+// subdirectories as needed.
+func (sm *SpecMore) SplitToDir(dir string, opts *SplitOptions, perm os.FileMode) error {
+	files, err := sm.Split(opts)
+	if err != nil {
+		return err
+	}
+	for filename, bytes := range files {
+		filename = filepath.Join(dir, filepath.FromSlash(filename))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filename, bytes, perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type splitter struct {
+	root  string
+	files map[string]any
+	// compFile maps component kind to name to file.
+	compFile map[string]map[string]string
+}
+
+func (s *splitter) uniqueFilename(used map[string]bool, filename string) string {
+	ext := path.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	for i := 2; used[filename]; i++ {
+		filename = base + strconv.Itoa(i) + ext
+	}
+	used[filename] = true
+	return filename
+}
+
+// rewrite rewrites local references in a value moved to `filename` into
+// references relative to it.
+func (s *splitter) rewrite(val any, filename string) any {
+	var walk func(val any, valPath []string) any
+	walk = func(val any, valPath []string) any {
+		switch v := val.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok && strings.HasPrefix(ref, "#") {
+				v["$ref"] = s.ref(ref, filename)
+				return v
+			}
+			for k, item := range v {
+				if !isBundleValueKey(valPath, k) {
+					v[k] = walk(item, append(valPath, k))
+				}
+			}
+		case []any:
+			for i, item := range v {
+				v[i] = walk(item, append(valPath, strconv.Itoa(i)))
+			}
+		}
+		return val
+	}
+	return walk(val, []string{})
+}
+
+// ref returns a reference relative to `filename` for a local reference of
+// the root spec.
+func (s *splitter) ref(ref, filename string) string {
+	target, fragment := s.root, strings.TrimPrefix(ref, "#")
+	parts := strings.SplitN(strings.TrimPrefix(fragment, "/"), "/", 4)
+	if len(parts) >= 3 && parts[0] == PathComponents {
+		if compFile, ok := s.compFile[parts[1]][jsonpointer.PropertyNameUnescape(parts[2])]; ok {
+			target, fragment = compFile, ""
+			if len(parts) == 4 {
+				fragment = "/" + parts[3]
+			}
+		}
+	}
+	rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(filename)), filepath.FromSlash(target))
+	if err != nil {
+		rel = target
+	}
+	rel = filepath.ToSlash(rel)
+	if fragment != "" {
+		rel += "#" + fragment
+	}
+	return rel
+}
@@ -0,0 +1,105 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/grokify/mogo/text/stringcase"
+	"github.com/grokify/spectrum/openapi3/ontology"
+)
+
+const splitTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {
+    "/pets/{petId}": {
+      "parameters": [{"$ref": "#/components/parameters/PetId"}],
+      "get": {
+        "responses": {"200": {"$ref": "#/components/responses/PetResponse"}}
+      },
+      "put": {
+        "requestBody": {"$ref": "#/components/requestBodies/PetBody"},
+        "responses": {"204": {"description": "Updated"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "properties": {
+          "owner": {"$ref": "#/components/schemas/Owner"},
+          "parent": {"$ref": "#/components/schemas/Pet"},
+          "city": {"$ref": "#/components/schemas/Owner/properties/city"}
+        }
+      },
+      "Owner": {"type": "object", "properties": {"city": {"type": "string"}}}
+    },
+    "parameters": {
+      "PetId": {"name": "petId", "in": "path", "required": true, "schema": {"type": "string"}}
+    },
+    "responses": {
+      "PetResponse": {
+        "description": "A pet",
+        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+      }
+    },
+    "requestBodies": {
+      "PetBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+    }
+  }
+}`
+
+func TestSplit(t *testing.T) {
+	spec, err := Parse([]byte(splitTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	sm := SpecMore{Spec: spec}
+	opts := &SplitOptions{Ontology: ontology.Ontology{SpecFileCase: stringcase.SnakeCase, SpecFileExt: ".yaml"}}
+	files, err := sm.Split(opts)
+	if err != nil {
+		t.Fatalf("SpecMore.Split() error [%v]", err)
+	}
+	for _, filename := range []string{
+		"openapi.yaml",
+		"paths/pets_pet_id.yaml",
+		"components/schemas/pet.yaml",
+		"components/schemas/owner.yaml",
+		"components/parameters/pet_id.yaml",
+		"components/responses/pet_response.yaml",
+	} {
+		if _, ok := files[filename]; !ok {
+			t.Errorf("SpecMore.Split() missing file [%s]", filename)
+		}
+	}
+	if len(files) != 6 {
+		t.Errorf("SpecMore.Split() file count mismatch: want [6], got [%d]", len(files))
+	}
+
+	// Bundling the split files returns the original spec.
+	dir := t.TempDir()
+	if err := sm.SplitToDir(dir, opts, 0600); err != nil {
+		t.Fatalf("SpecMore.SplitToDir() error [%v]", err)
+	}
+	bundled, err := BundleFile(filepath.Join(dir, "openapi.yaml"))
+	if err != nil {
+		t.Fatalf("openapi3.BundleFile() error [%v]", err)
+	}
+	want, got := map[string]any{}, map[string]any{}
+	if err := json.Unmarshal([]byte(splitTestSpec), &want); err != nil {
+		t.Fatal(err)
+	}
+	bytes, err := bundled.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(bytes, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("SpecMore.Split() round trip mismatch: want [%v], got [%v]", want, got)
+	}
+}
@@ -0,0 +1,208 @@
+package springopenapi3
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	rxJavaStringLiteral = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+	rxJavaIdentifier    = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$.]*`)
+)
+
+// JavaAnnotation is a parsed Java annotation such as `@RequestParam(name = "q")`.
+// A single positional argument is stored under the `value` key. Argument
+// values are raw Java source, e.g. `"q"` or `{RequestMethod.GET}`.
+type JavaAnnotation struct {
+	Name string
+	Args map[string]string
+}
+
+// Strings returns the string literals of an argument, e.g. `{"/a", "/b"}`
+// returns `[/a /b]`.
+func (ja JavaAnnotation) Strings(key string) []string {
+	out := []string{}
+	for _, m := range rxJavaStringLiteral.FindAllStringSubmatch(ja.Args[key], -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
+
+// String returns the first string literal of the first argument key that exists.
+func (ja JavaAnnotation) String(keys ...string) (string, bool) {
+	for _, key := range keys {
+		if vals := ja.Strings(key); len(vals) > 0 {
+			return vals[0], true
+		}
+	}
+	return "", false
+}
+
+// Bool returns a boolean argument value.
+func (ja JavaAnnotation) Bool(key string) (bool, bool) {
+	switch strings.TrimSpace(ja.Args[key]) {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	}
+	return false, false
+}
+
+// JavaAnnotations is an ordered list of annotations.
+type JavaAnnotations []JavaAnnotation
+
+// Get returns the first annotation with the simple name.
+func (jas JavaAnnotations) Get(name string) (JavaAnnotation, bool) {
+	for _, ja := range jas {
+		if ja.Name == name {
+			return ja, true
+		}
+	}
+	return JavaAnnotation{}, false
+}
+
+func (jas JavaAnnotations) Has(name string) bool {
+	_, ok := jas.Get(name)
+	return ok
+}
+
+// stripJavaComments removes `//` and `/* */` comments outside of string and
+// character literals.
+func stripJavaComments(src string) string {
+	var sb strings.Builder
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '"' || c == '\'':
+			end := literalEnd(src, i)
+			sb.WriteString(src[i:end])
+			i = end - 1
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			if i < len(src) {
+				sb.WriteByte('\n')
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return sb.String()
+			}
+			i += end + 3
+			sb.WriteByte(' ')
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// literalEnd returns the index after the string or character literal that
+// starts at `start`.
+func literalEnd(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == quote {
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// matchingIndex returns the index of the bracket closing the bracket at
+// `open`, skipping literals, or -1.
+func matchingIndex(s string, open int) int {
+	openChar := s[open]
+	closeChar := map[byte]byte{'(': ')', '{': '}', '[': ']', '<': '>'}[openChar]
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			i = literalEnd(s, i) - 1
+		case openChar:
+			depth++
+		case closeChar:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits on `sep` outside of brackets, generics and literals.
+func splitTopLevel(s string, sep byte) []string {
+	parts := []string{}
+	depth := 0
+	last := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			i = literalEnd(s, i) - 1
+		case '(', '{', '[', '<':
+			depth++
+		case ')', '}', ']', '>':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[last:i]))
+				last = i + 1
+			}
+		}
+	}
+	if tail := strings.TrimSpace(s[last:]); len(tail) > 0 || len(parts) > 0 {
+		parts = append(parts, tail)
+	}
+	return parts
+}
+
+// parseJavaAnnotations parses the annotations at the start of `s` and returns
+// them with the remaining source.
+func parseJavaAnnotations(s string) (JavaAnnotations, string) {
+	anns := JavaAnnotations{}
+	s = strings.TrimSpace(s)
+	for strings.HasPrefix(s, "@") && !strings.HasPrefix(s, "@interface") {
+		name := rxJavaIdentifier.FindString(s[1:])
+		if name == "" {
+			break
+		}
+		s = strings.TrimSpace(s[1+len(name):])
+		if idx := strings.LastIndex(name, "."); idx > -1 {
+			name = name[idx+1:]
+		}
+		ann := JavaAnnotation{Name: name, Args: map[string]string{}}
+		if strings.HasPrefix(s, "(") {
+			end := matchingIndex(s, 0)
+			if end < 0 {
+				end = len(s) - 1
+			}
+			ann.Args = parseJavaAnnotationArgs(s[1:end])
+			s = strings.TrimSpace(s[end+1:])
+		}
+		anns = append(anns, ann)
+	}
+	return anns, s
+}
+
+func parseJavaAnnotationArgs(inner string) map[string]string {
+	args := map[string]string{}
+	for _, part := range splitTopLevel(inner, ',') {
+		if len(part) == 0 {
+			continue
+		}
+		if idx := strings.Index(part, "="); idx > 0 {
+			key := strings.TrimSpace(part[:idx])
+			if rxJavaIdentifier.MatchString(key) && !strings.ContainsAny(key, " \"") {
+				args[key] = strings.TrimSpace(part[idx+1:])
+				continue
+			}
+		}
+		args["value"] = part
+	}
+	return args
+}
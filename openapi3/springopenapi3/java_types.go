@@ -0,0 +1,156 @@
+package springopenapi3
+
+import (
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+// javaWrapperTypes are generic types whose single type argument is used as the schema.
+var javaWrapperTypes = map[string]bool{
+	"CompletableFuture": true,
+	"CompletionStage":   true,
+	"DeferredResult":    true,
+	"HttpEntity":        true,
+	"Mono":              true,
+	"Optional":          true,
+	"ResponseEntity":    true}
+
+// javaCollectionTypes are generic types converted to arrays.
+var javaCollectionTypes = map[string]bool{
+	"ArrayList":  true,
+	"Collection": true,
+	"Flux":       true,
+	"HashSet":    true,
+	"Iterable":   true,
+	"LinkedList": true,
+	"List":       true,
+	"Set":        true,
+	"SortedSet":  true,
+	"Stream":     true}
+
+// JavaTypeSchemaRef converts a Java type, such as `ResponseEntity<List<User>>`,
+// into a schema ref. Wrapper types such as `ResponseEntity` and `Optional` are
+// unwrapped, collections become arrays and maps become objects with
+// `additionalProperties`. Unknown types are references to
+// `#/components/schemas/{SimpleName}`. It returns `nil` for `void`.
+func JavaTypeSchemaRef(javaType string) *oas3.SchemaRef {
+	javaType = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(javaType), "final "))
+	if strings.HasSuffix(javaType, "[]") || strings.HasSuffix(javaType, "...") {
+		itemType := strings.TrimSuffix(strings.TrimSuffix(javaType, "[]"), "...")
+		if itemType == "byte" || itemType == "Byte" {
+			return oas3.NewSchemaRef("", oas3.NewStringSchema().WithFormat("byte"))
+		}
+		return oas3.NewSchemaRef("", arraySchema(JavaTypeSchemaRef(itemType)))
+	}
+	if strings.HasPrefix(javaType, "?") {
+		javaType = strings.TrimSpace(strings.TrimPrefix(javaType, "?"))
+		javaType = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(javaType, "extends"), "super"))
+		if javaType == "" {
+			return oas3.NewSchemaRef("", oas3.NewSchema())
+		}
+	}
+	base, args := javaGenericParts(javaType)
+	switch {
+	case javaWrapperTypes[base]:
+		if len(args) == 0 {
+			return oas3.NewSchemaRef("", oas3.NewSchema())
+		}
+		return JavaTypeSchemaRef(args[0])
+	case javaCollectionTypes[base]:
+		if len(args) == 0 {
+			return oas3.NewSchemaRef("", arraySchema(oas3.NewSchemaRef("", oas3.NewSchema())))
+		}
+		return oas3.NewSchemaRef("", arraySchema(JavaTypeSchemaRef(args[0])))
+	case base == "Map" || base == "HashMap" || base == "LinkedHashMap" || base == "TreeMap":
+		sch := oas3.NewObjectSchema()
+		if len(args) == 2 {
+			has := true
+			sch.AdditionalProperties = oas3.AdditionalProperties{
+				Has:    &has,
+				Schema: nonNilSchema(JavaTypeSchemaRef(args[1]))}
+		}
+		return oas3.NewSchemaRef("", sch)
+	}
+	if sch, ok := javaScalarSchema(base); ok {
+		if sch == nil {
+			return nil
+		}
+		return oas3.NewSchemaRef("", sch)
+	}
+	return oas3.NewSchemaRef(schemaPath(base), nil)
+}
+
+// javaScalarSchema returns schemas for Java primitives, wrappers and common
+// JDK types. A `nil` schema with `true` is returned for `void`.
+func javaScalarSchema(base string) (*oas3.Schema, bool) {
+	switch base {
+	case "void", "Void":
+		return nil, true
+	case "String", "CharSequence", "char", "Character":
+		return oas3.NewStringSchema(), true
+	case "int", "Integer", "short", "Short", "byte", "Byte":
+		return oas3.NewInt32Schema(), true
+	case "long", "Long", "BigInteger":
+		return oas3.NewInt64Schema(), true
+	case "float", "Float":
+		return oas3.NewFloat64Schema().WithFormat("float"), true
+	case "double", "Double":
+		return oas3.NewFloat64Schema().WithFormat("double"), true
+	case "BigDecimal", "Number":
+		return oas3.NewFloat64Schema(), true
+	case "boolean", "Boolean":
+		return oas3.NewBoolSchema(), true
+	case "UUID":
+		return oas3.NewStringSchema().WithFormat(openapi3.FormatUUID), true
+	case "LocalDate":
+		return oas3.NewStringSchema().WithFormat(FormatStringDate), true
+	case "Date", "Instant", "LocalDateTime", "OffsetDateTime", "ZonedDateTime", "Timestamp":
+		return oas3.NewStringSchema().WithFormat(FormatStringDateTime), true
+	case "URI", "URL":
+		return oas3.NewStringSchema().WithFormat(openapi3.FormatURI), true
+	case "MultipartFile", "Resource", "InputStream", "File":
+		return oas3.NewStringSchema().WithFormat("binary"), true
+	case "Object", "JsonNode", "ObjectNode":
+		return oas3.NewSchema(), true
+	}
+	return nil, false
+}
+
+func arraySchema(items *oas3.SchemaRef) *oas3.Schema {
+	sch := oas3.NewArraySchema()
+	sch.Items = nonNilSchema(items)
+	return sch
+}
+
+func nonNilSchema(schRef *oas3.SchemaRef) *oas3.SchemaRef {
+	if schRef == nil {
+		return oas3.NewSchemaRef("", oas3.NewSchema())
+	}
+	return schRef
+}
+
+// javaGenericParts splits `Map<String, List<User>>` into `Map` and
+// `[String, List<User>]`. Package qualifiers are removed from the base.
+func javaGenericParts(javaType string) (string, []string) {
+	base := javaType
+	args := []string{}
+	if idx := strings.Index(javaType, "<"); idx > -1 && strings.HasSuffix(javaType, ">") {
+		base = javaType[:idx]
+		args = splitTopLevel(javaType[idx+1:len(javaType)-1], ',')
+	}
+	base = strings.TrimSpace(base)
+	if idx := strings.LastIndex(base, "."); idx > -1 {
+		base = base[idx+1:]
+	}
+	return base, args
+}
+
+// JavaSimpleScalar returns true for Java types that Spring binds from a single
+// request parameter value, such as `String`, `Long` and `UUID`.
+func JavaSimpleScalar(javaType string) bool {
+	base, _ := javaGenericParts(strings.TrimSpace(javaType))
+	sch, ok := javaScalarSchema(base)
+	return ok && sch != nil && sch.Type != "" && sch.Format != "binary"
+}
@@ -0,0 +1,242 @@
+package springopenapi3
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	AnnotationNotNull    = "NotNull"
+	AnnotationNonNull    = "NonNull"
+	AnnotationNotBlank   = "NotBlank"
+	AnnotationNotEmpty   = "NotEmpty"
+	AnnotationSize       = "Size"
+	AnnotationMin        = "Min"
+	AnnotationMax        = "Max"
+	AnnotationDecimalMin = "DecimalMin"
+	AnnotationDecimalMax = "DecimalMax"
+	AnnotationPattern    = "Pattern"
+	AnnotationEmail      = "Email"
+	AnnotationJSONProp   = "JsonProperty"
+	AnnotationJSONIgnore = "JsonIgnore"
+	AnnotationDeprecated = "Deprecated"
+	FormatStringEmail    = openapi3.FormatEmail
+)
+
+var (
+	rxJavaEnum        = regexp.MustCompile(`\benum\s+([A-Za-z_$][A-Za-z0-9_$]*)`)
+	rxJavaStaticField = regexp.MustCompile(`(?:^|\s)(?:static|transient)\s`)
+)
+
+// applySpringAnnotations applies Bean Validation and Jackson annotations to a
+// property schema. It returns the JSON property name, whether the property is
+// required and whether it is ignored by `@JsonIgnore`.
+func applySpringAnnotations(anns JavaAnnotations, name string, schRef *oas3.SchemaRef) (string, bool, bool) {
+	required := false
+	var sch *oas3.Schema
+	if schRef != nil && schRef.Ref == "" {
+		sch = schRef.Value
+	}
+	for _, ann := range anns {
+		switch ann.Name {
+		case AnnotationNotNull, AnnotationNonNull:
+			required = true
+		case AnnotationNotBlank, AnnotationNotEmpty:
+			required = true
+			if sch != nil && sch.Type == openapi3.TypeString && sch.MinLength == 0 {
+				sch.MinLength = 1
+			} else if sch != nil && sch.Type == openapi3.TypeArray && sch.MinItems == 0 {
+				sch.MinItems = 1
+			}
+		case AnnotationSize:
+			if sch == nil {
+				continue
+			}
+			minVal, minOK := annotationUint(ann, "min")
+			maxVal, maxOK := annotationUint(ann, "max")
+			if sch.Type == openapi3.TypeArray {
+				if minOK {
+					sch.MinItems = minVal
+				}
+				if maxOK {
+					sch.MaxItems = &maxVal
+				}
+			} else {
+				if minOK {
+					sch.MinLength = minVal
+				}
+				if maxOK {
+					sch.MaxLength = &maxVal
+				}
+			}
+		case AnnotationMin, AnnotationDecimalMin:
+			if v, ok := annotationFloat(ann, "value"); ok && sch != nil {
+				sch.Min = &v
+			}
+		case AnnotationMax, AnnotationDecimalMax:
+			if v, ok := annotationFloat(ann, "value"); ok && sch != nil {
+				sch.Max = &v
+			}
+		case AnnotationPattern:
+			if pattern, ok := annotationJavaString(ann, "regexp"); ok && sch != nil {
+				sch.Pattern = pattern
+			}
+		case AnnotationEmail:
+			if sch != nil {
+				sch.Format = FormatStringEmail
+			}
+		case AnnotationJSONProp:
+			if jsonName, ok := annotationJavaString(ann, "value"); ok && len(jsonName) > 0 {
+				name = jsonName
+			}
+			if req, ok := ann.Bool("required"); ok && req {
+				required = true
+			}
+		case AnnotationJSONIgnore:
+			if ignore, ok := ann.Bool("value"); !ok || ignore {
+				return name, required, true
+			}
+		case AnnotationDeprecated:
+			if sch != nil {
+				sch.Deprecated = true
+			}
+		}
+	}
+	return name, required, false
+}
+
+// annotationJavaString returns an unescaped Java string literal argument.
+func annotationJavaString(ann JavaAnnotation, key string) (string, bool) {
+	m := rxJavaStringLiteral.FindStringSubmatch(ann.Args[key])
+	if len(m) < 2 {
+		return "", false
+	}
+	if s, err := strconv.Unquote(`"` + m[1] + `"`); err == nil {
+		return s, true
+	}
+	return m[1], true
+}
+
+func annotationFloat(ann JavaAnnotation, key string) (float64, bool) {
+	raw := strings.TrimSpace(ann.Args[key])
+	if s, ok := annotationJavaString(ann, key); ok {
+		raw = s
+	}
+	raw = strings.TrimRight(raw, "lLdDfF")
+	v, err := strconv.ParseFloat(raw, 64)
+	return v, err == nil
+}
+
+func annotationUint(ann JavaAnnotation, key string) (uint64, bool) {
+	v, ok := annotationFloat(ann, key)
+	if !ok || v < 0 {
+		return 0, false
+	}
+	return uint64(v), true
+}
+
+// ParseSpringClassToSchema parses a Java model class or enum. Class fields
+// become object properties with Bean Validation and Jackson annotations
+// applied. Enums become string enums using `@JsonProperty` values when
+// present. It returns the Java class name and the schema.
+func ParseSpringClassToSchema(src string) (string, *oas3.Schema, error) {
+	src = stripJavaComments(src)
+	classLoc := rxJavaClass.FindStringSubmatchIndex(src)
+	enumLoc := rxJavaEnum.FindStringSubmatchIndex(src)
+	if enumLoc != nil && (classLoc == nil || enumLoc[0] < classLoc[0]) {
+		name := src[enumLoc[2]:enumLoc[3]]
+		body, err := javaTypeBody(src, enumLoc[1])
+		if err != nil {
+			return name, nil, err
+		}
+		return name, javaEnumSchema(body), nil
+	} else if classLoc == nil {
+		return "", nil, fmt.Errorf("java class or enum not found")
+	}
+	name := src[classLoc[2]:classLoc[3]]
+	body, err := javaTypeBody(src, classLoc[1])
+	if err != nil {
+		return name, nil, err
+	}
+	sch := oas3.NewObjectSchema()
+	for _, header := range classMemberHeaders(body) {
+		anns, rest := parseJavaAnnotations(header)
+		if eq := strings.Index(rest, "="); eq > -1 {
+			rest = strings.TrimSpace(rest[:eq])
+		}
+		if len(rest) == 0 || strings.Contains(rest, "(") || rxJavaStaticField.MatchString(" "+rest) ||
+			rxJavaEnum.MatchString(rest) || rxJavaClass.MatchString(rest) {
+			continue
+		}
+		rest = strings.TrimSpace(rxJavaModifiers.ReplaceAllString(rest, ""))
+		idx := strings.LastIndexAny(rest, " \t\n>")
+		if idx < 0 {
+			continue
+		}
+		javaName := strings.TrimSpace(rest[idx+1:])
+		propRef := JavaTypeSchemaRef(rest[:idx+1])
+		if propRef == nil {
+			continue
+		}
+		propName, required, ignored := applySpringAnnotations(anns, javaName, propRef)
+		if ignored {
+			continue
+		}
+		sch.Properties[propName] = propRef
+		if required {
+			sch.Required = append(sch.Required, propName)
+		}
+	}
+	return name, sch, nil
+}
+
+func javaTypeBody(src string, from int) (string, error) {
+	start := strings.Index(src[from:], "{")
+	if start < 0 {
+		return "", fmt.Errorf("java type body not found")
+	}
+	start += from
+	end := matchingIndex(src, start)
+	if end < 0 {
+		return "", fmt.Errorf("java type body not closed")
+	}
+	return src[start+1 : end], nil
+}
+
+// javaEnumSchema converts the constants of a Java enum body to a string enum.
+func javaEnumSchema(body string) *oas3.Schema {
+	sch := oas3.NewStringSchema()
+	constants := body
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '"', '\'':
+			i = literalEnd(body, i) - 1
+		case '(', '{':
+			if end := matchingIndex(body, i); end > -1 {
+				i = end
+			}
+		case ';':
+			constants = body[:i]
+			i = len(body)
+		}
+	}
+	for _, part := range splitTopLevel(constants, ',') {
+		anns, rest := parseJavaAnnotations(part)
+		value := rxJavaIdentifier.FindString(rest)
+		if len(value) == 0 {
+			continue
+		}
+		if ann, ok := anns.Get(AnnotationJSONProp); ok {
+			if jsonName, ok := annotationJavaString(ann, "value"); ok {
+				value = jsonName
+			}
+		}
+		sch.Enum = append(sch.Enum, value)
+	}
+	return sch
+}
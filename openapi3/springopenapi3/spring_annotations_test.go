@@ -0,0 +1,171 @@
+package springopenapi3
+
+import (
+	"strings"
+	"testing"
+)
+
+const springModelTest = `package com.example.users;
+
+import javax.validation.constraints.*;
+import com.fasterxml.jackson.annotation.*;
+
+public class User {
+    public static final int MAX_TAGS = 10;
+
+    @NotNull
+    private Long id;
+
+    @NotBlank
+    @Size(min = 3, max = 64)
+    @JsonProperty("user_name")
+    private String username;
+
+    @Email
+    private String email;
+
+    @Min(0) @Max(150)
+    private Integer age;
+
+    @Pattern(regexp = "^[A-Z]{2}\\d{2}$")
+    private String code;
+
+    @Size(max = MAX_TAGS)
+    private List<String> tags = new ArrayList<>();
+
+    @JsonIgnore
+    private String password;
+
+    @Deprecated
+    private Status status;
+
+    public enum Status { ACTIVE, INACTIVE }
+
+    public String getUsername() { return username; }
+}
+`
+
+const springEnumTest = `public enum Status {
+    @JsonProperty("active") ACTIVE("a"),
+    INACTIVE("i"), // comment
+    PENDING("p");
+
+    private final String code;
+
+    Status(String code) { this.code = code; }
+}`
+
+func TestParseSpringClassToSchema(t *testing.T) {
+	name, sch, err := ParseSpringClassToSchema(springModelTest)
+	if err != nil {
+		t.Fatalf("springopenapi3.ParseSpringClassToSchema() error [%v]", err)
+	}
+	if name != "User" || len(sch.Properties) != 7 {
+		t.Fatalf("springopenapi3.ParseSpringClassToSchema() mismatch: want [User 7], got [%s %d]", name, len(sch.Properties))
+	}
+	if got := strings.Join(sch.Required, ","); got != "id,user_name" {
+		t.Errorf("springopenapi3.ParseSpringClassToSchema() required mismatch: want [id,user_name], got [%s]", got)
+	}
+	if _, ok := sch.Properties["password"]; ok {
+		t.Errorf("springopenapi3.ParseSpringClassToSchema() @JsonIgnore property not dropped")
+	}
+	username := sch.Properties["user_name"].Value
+	if username.MinLength != 3 || username.MaxLength == nil || *username.MaxLength != 64 {
+		t.Errorf("springopenapi3.ParseSpringClassToSchema() @Size mismatch for [user_name]")
+	}
+	if age := sch.Properties["age"].Value; age.Min == nil || *age.Min != 0 || age.Max == nil || *age.Max != 150 {
+		t.Errorf("springopenapi3.ParseSpringClassToSchema() @Min/@Max mismatch for [age]")
+	}
+	if got := sch.Properties["code"].Value.Pattern; got != `^[A-Z]{2}\d{2}$` {
+		t.Errorf("springopenapi3.ParseSpringClassToSchema() @Pattern mismatch: want [%s], got [%s]", `^[A-Z]{2}\d{2}$`, got)
+	}
+	if got := sch.Properties["email"].Value.Format; got != FormatStringEmail {
+		t.Errorf("springopenapi3.ParseSpringClassToSchema() @Email mismatch: want [%s], got [%s]", FormatStringEmail, got)
+	}
+	if got := sch.Properties["status"].Ref; got != "#/components/schemas/Status" {
+		t.Errorf("springopenapi3.ParseSpringClassToSchema() status ref mismatch: got [%s]", got)
+	}
+
+	name, sch, err = ParseSpringClassToSchema(springEnumTest)
+	if err != nil {
+		t.Fatalf("springopenapi3.ParseSpringClassToSchema() error [%v]", err)
+	}
+	if got := []string{}; name == "Status" {
+		for _, v := range sch.Enum {
+			got = append(got, v.(string))
+		}
+		if strings.Join(got, ",") != "active,INACTIVE,PENDING" {
+			t.Errorf("springopenapi3.ParseSpringClassToSchema() enum mismatch: want [active,INACTIVE,PENDING], got [%s]", strings.Join(got, ","))
+		}
+	} else {
+		t.Errorf("springopenapi3.ParseSpringClassToSchema() enum name mismatch: want [Status], got [%s]", name)
+	}
+}
+
+var parseLineAnnotationTests = []struct {
+	v         string
+	oasName   string
+	minLength uint64
+	format    string
+}{
+	{`@JsonProperty("first_name") @Size(min = 2) private String firstName;`, "first_name", 2, ""},
+	{`@Email private String emailAddress;`, "emailAddress", 0, FormatStringEmail},
+	{`@JsonIgnore private String secret;`, "", 0, ""},
+}
+
+func TestParseLineAnnotations(t *testing.T) {
+	for _, tt := range parseLineAnnotationTests {
+		name, schRef, err := ParseSpringLineToSchemaRef(tt.v, []string{})
+		if err != nil {
+			t.Errorf("springopenapi3.ParseSpringLineToSchemaRef() error [%v]", err)
+			continue
+		}
+		if name != tt.oasName {
+			t.Errorf("springopenapi3.ParseSpringLineToSchemaRef(\"%s\") name mismatch: want [%s], got [%s]", tt.v, tt.oasName, name)
+		} else if name != "" && (schRef.Value.MinLength != tt.minLength || schRef.Value.Format != tt.format) {
+			t.Errorf("springopenapi3.ParseSpringLineToSchemaRef(\"%s\") constraint mismatch: want [%d %s], got [%d %s]",
+				tt.v, tt.minLength, tt.format, schRef.Value.MinLength, schRef.Value.Format)
+		}
+	}
+
+	name, sch, err := ParseSpringPropertyLinesToSchema([]string{
+		`@Column(name = "display_name")`,
+		`@NotBlank`,
+		`@JsonProperty("display_name")`,
+		`private String displayName;`})
+	if err != nil || name != "display_name" || sch.MinLength != 1 {
+		t.Errorf("springopenapi3.ParseSpringPropertyLinesToSchema() mismatch: want [display_name 1], got [%s %v]", name, sch)
+	}
+}
+
+func TestParseSpringLinesToObjectSchema(t *testing.T) {
+	sch, err := ParseSpringLinesToObjectSchema([]string{
+		`@NotNull`,
+		`private Long id;`,
+		``,
+		`@JsonProperty(value = "user_name", required = true)`,
+		`private String username;`,
+		`@NotBlank private String email;`,
+		`private String nickname;`}, []string{})
+	if err != nil {
+		t.Fatalf("springopenapi3.ParseSpringLinesToObjectSchema() error [%v]", err)
+	}
+	if got := strings.Join(sch.Required, ","); got != "id,user_name,email" {
+		t.Errorf("springopenapi3.ParseSpringLinesToObjectSchema() required mismatch: want [id,user_name,email], got [%s]", got)
+	}
+	if len(sch.Properties) != 4 || sch.Properties["email"].Value.MinLength != 1 {
+		t.Errorf("springopenapi3.ParseSpringLinesToObjectSchema() properties mismatch: got [%d]", len(sch.Properties))
+	}
+
+	name, _, required, err := ParseSpringLineToProperty(`@NotNull private Integer count;`, []string{})
+	if err != nil || name != "count" || !required {
+		t.Errorf("springopenapi3.ParseSpringLineToProperty() mismatch: want [count true], got [%s %v]", name, required)
+	}
+
+	obj, err := ParseSpringPropertyLinesSliceToObjectSchema([][]string{
+		{`@Column(name = "id")`, `@NotNull`, `private Long id;`},
+		{`@Column(name = "note")`, `private String note;`}})
+	if err != nil || strings.Join(obj.Required, ",") != "id" || len(obj.Properties) != 2 {
+		t.Errorf("springopenapi3.ParseSpringPropertyLinesSliceToObjectSchema() mismatch: want [id], got [%v]", obj.Required)
+	}
+}
@@ -0,0 +1,436 @@
+package springopenapi3
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/mogo/errors/errorsutil"
+	"github.com/grokify/mogo/net/urlutil"
+	"github.com/grokify/spectrum/openapi3"
+	"github.com/grokify/spectrum/openapi3edit"
+)
+
+const (
+	AnnotationController     = "Controller"
+	AnnotationRestController = "RestController"
+	AnnotationRequestMapping = "RequestMapping"
+	AnnotationGetMapping     = "GetMapping"
+	AnnotationPostMapping    = "PostMapping"
+	AnnotationPutMapping     = "PutMapping"
+	AnnotationPatchMapping   = "PatchMapping"
+	AnnotationDeleteMapping  = "DeleteMapping"
+	AnnotationPathVariable   = "PathVariable"
+	AnnotationRequestParam   = "RequestParam"
+	AnnotationRequestHeader  = "RequestHeader"
+	AnnotationRequestBody    = "RequestBody"
+	AnnotationResponseStatus = "ResponseStatus"
+)
+
+// mappingMethods maps Spring mapping annotations to HTTP methods.
+var mappingMethods = map[string]string{
+	AnnotationGetMapping:    http.MethodGet,
+	AnnotationPostMapping:   http.MethodPost,
+	AnnotationPutMapping:    http.MethodPut,
+	AnnotationPatchMapping:  http.MethodPatch,
+	AnnotationDeleteMapping: http.MethodDelete}
+
+// httpStatusNames maps Spring `HttpStatus` constants to status codes.
+var httpStatusNames = map[string]int{
+	"OK":                    http.StatusOK,
+	"CREATED":               http.StatusCreated,
+	"ACCEPTED":              http.StatusAccepted,
+	"NO_CONTENT":            http.StatusNoContent,
+	"RESET_CONTENT":         http.StatusResetContent,
+	"PARTIAL_CONTENT":       http.StatusPartialContent,
+	"MOVED_PERMANENTLY":     http.StatusMovedPermanently,
+	"FOUND":                 http.StatusFound,
+	"SEE_OTHER":             http.StatusSeeOther,
+	"NOT_MODIFIED":          http.StatusNotModified,
+	"BAD_REQUEST":           http.StatusBadRequest,
+	"UNAUTHORIZED":          http.StatusUnauthorized,
+	"FORBIDDEN":             http.StatusForbidden,
+	"NOT_FOUND":             http.StatusNotFound,
+	"CONFLICT":              http.StatusConflict,
+	"INTERNAL_SERVER_ERROR": http.StatusInternalServerError}
+
+var (
+	rxJavaClass       = regexp.MustCompile(`\b(?:class|interface)\s+([A-Za-z_$][A-Za-z0-9_$]*)`)
+	rxJavaModifiers   = regexp.MustCompile(`^(?:(?:public|protected|private|static|final|abstract|synchronized|default|native)\s+)+`)
+	rxSpringPathRegex = regexp.MustCompile(`\{([A-Za-z0-9_]+):[^/]*\}`)
+	rxRequestMethod   = regexp.MustCompile(`RequestMethod\.([A-Z]+)`)
+	rxHTTPStatus      = regexp.MustCompile(`HttpStatus\.([A-Z_]+)`)
+)
+
+// ParseSpringControllerFile reads a Java source file and parses Spring MVC
+// controller mappings. See `ParseSpringController`.
+func ParseSpringControllerFile(filename string) (openapi3.OperationMetas, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	oms, err := ParseSpringController(string(data))
+	if err != nil {
+		return nil, errorsutil.Wrapf(err, "error parsing spring controller (%s)", filename)
+	}
+	return oms, nil
+}
+
+// ParseSpringController parses Spring MVC controller Java source into
+// operations. It supports `@RestController` and `@Controller` classes with a
+// class level `@RequestMapping`, the `@GetMapping`, `@PostMapping`,
+// `@PutMapping`, `@PatchMapping`, `@DeleteMapping` and `@RequestMapping`
+// method annotations, `@PathVariable`, `@RequestParam`, `@RequestHeader` and
+// `@RequestBody` parameters, and return types. The Java method name is used as
+// the `operationId` and the class name, without a `Controller` suffix, as the
+// tag. Model classes are referenced as `#/components/schemas/{SimpleName}`.
+// Use `ControllerSpec()` or `OperationMetas.Spec()` to build a spec.
+func ParseSpringController(src string) (openapi3.OperationMetas, error) {
+	src = stripJavaComments(src)
+	loc := rxJavaClass.FindStringSubmatchIndex(src)
+	if loc == nil {
+		return nil, fmt.Errorf("spring controller class not found")
+	}
+	className := src[loc[2]:loc[3]]
+	prefix := src[:loc[0]]
+	if idx := strings.LastIndex(prefix, ";"); idx > -1 {
+		prefix = prefix[idx+1:]
+	}
+	classAnns, _ := parseJavaAnnotations(prefix[strings.Index(prefix+"@", "@"):])
+	if !classAnns.Has(AnnotationRestController) && !classAnns.Has(AnnotationController) {
+		return nil, fmt.Errorf("spring class is not a controller (%s)", className)
+	}
+	basePaths := []string{""}
+	if ann, ok := classAnns.Get(AnnotationRequestMapping); ok {
+		if paths := mappingPaths(ann); len(paths) > 0 {
+			basePaths = paths
+		}
+	}
+	bodyStart := strings.Index(src[loc[1]:], "{")
+	if bodyStart < 0 {
+		return nil, fmt.Errorf("spring controller class body not found (%s)", className)
+	}
+	bodyStart += loc[1]
+	bodyEnd := matchingIndex(src, bodyStart)
+	if bodyEnd < 0 {
+		bodyEnd = len(src)
+	}
+	tag := strings.TrimSuffix(className, "Controller")
+
+	oms := openapi3.OperationMetas{}
+	for _, header := range classMemberHeaders(src[bodyStart+1 : bodyEnd]) {
+		anns, rest := parseJavaAnnotations(header)
+		methods, paths, mapping, ok := methodMapping(anns)
+		if !ok {
+			continue
+		}
+		sig, err := parseJavaMethodSignature(rest)
+		if err != nil {
+			return oms, errorsutil.Wrapf(err, "error parsing method in class (%s)", className)
+		}
+		for _, basePath := range basePaths {
+			for _, p := range paths {
+				for _, method := range methods {
+					om := sig.operationMeta(anns, mapping)
+					om.Method = method
+					om.Path = springPathToOpenAPI(urlutil.JoinAbsolute(basePath, p))
+					if len(tag) > 0 {
+						om.Tags = []string{tag}
+					}
+					oms = append(oms, om)
+				}
+			}
+		}
+	}
+	return oms, nil
+}
+
+// ControllerSpec builds a spec from controller operations with
+// `OperationMetas.Spec()` and adds the Java model types referenced as
+// `#/components/schemas/{SimpleName}` so the spec has no dangling references.
+// Types are taken from `schemas`, e.g. built with
+// `ParseSpringLinesToObjectSchema()`, and are otherwise added as empty object
+// placeholder schemas.
+func ControllerSpec(oms openapi3.OperationMetas, schemas oas3.Schemas, opIDSep, opIDWantCase string) (*openapi3.Spec, error) {
+	spec, err := oms.Spec(opIDSep, opIDWantCase)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Components == nil {
+		spec.Components = &oas3.Components{}
+	}
+	if spec.Components.Schemas == nil {
+		spec.Components.Schemas = oas3.Schemas{}
+	}
+	se := openapi3edit.NewSpecEdit(spec)
+	for {
+		missing := []string{}
+		se.SchemaRefsModify(func(ref string) string {
+			if name, ok := strings.CutPrefix(ref, schemaPath("")); ok && !strings.Contains(name, "/") {
+				if _, ok := spec.Components.Schemas[name]; !ok && !slices.Contains(missing, name) {
+					missing = append(missing, name)
+				}
+			}
+			return ref
+		})
+		if len(missing) == 0 {
+			return spec, nil
+		}
+		for _, name := range missing {
+			if schRef, ok := schemas[name]; ok && schRef != nil {
+				spec.Components.Schemas[name] = schRef
+			} else {
+				sch := oas3.NewObjectSchema()
+				sch.Description = fmt.Sprintf("Placeholder for Java type `%s`.", name)
+				spec.Components.Schemas[name] = oas3.NewSchemaRef("", sch)
+			}
+		}
+	}
+}
+
+// classMemberHeaders returns the declarations of class members without
+// their bodies, e.g. annotations and method signatures.
+func classMemberHeaders(body string) []string {
+	headers := []string{}
+	last := 0
+	parens := 0
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '"', '\'':
+			i = literalEnd(body, i) - 1
+		case '(':
+			parens++
+		case ')':
+			parens--
+		case ';':
+			if parens == 0 {
+				headers = append(headers, strings.TrimSpace(body[last:i]))
+				last = i + 1
+			}
+		case '{':
+			if parens == 0 {
+				headers = append(headers, strings.TrimSpace(body[last:i]))
+				end := matchingIndex(body, i)
+				if end < 0 {
+					return headers
+				}
+				i = end
+				last = i + 1
+			}
+		}
+	}
+	return headers
+}
+
+// methodMapping returns the HTTP methods and paths for a method's mapping
+// annotation.
+func methodMapping(anns JavaAnnotations) ([]string, []string, JavaAnnotation, bool) {
+	for _, ann := range anns {
+		if method, ok := mappingMethods[ann.Name]; ok {
+			return []string{method}, mappingPathsOrRoot(ann), ann, true
+		} else if ann.Name == AnnotationRequestMapping {
+			methods := []string{}
+			for _, m := range rxRequestMethod.FindAllStringSubmatch(ann.Args["method"], -1) {
+				methods = append(methods, m[1])
+			}
+			if len(methods) == 0 {
+				methods = []string{http.MethodGet}
+			}
+			return methods, mappingPathsOrRoot(ann), ann, true
+		}
+	}
+	return nil, nil, JavaAnnotation{}, false
+}
+
+func mappingPaths(ann JavaAnnotation) []string {
+	if paths := ann.Strings("value"); len(paths) > 0 {
+		return paths
+	}
+	return ann.Strings("path")
+}
+
+func mappingPathsOrRoot(ann JavaAnnotation) []string {
+	if paths := mappingPaths(ann); len(paths) > 0 {
+		return paths
+	}
+	return []string{""}
+}
+
+// springPathToOpenAPI removes Spring path variable regular expressions, e.g.
+// `/users/{id:[0-9]+}` becomes `/users/{id}`.
+func springPathToOpenAPI(p string) string {
+	p = rxSpringPathRegex.ReplaceAllString(p, "{$1}")
+	if len(p) > 1 {
+		p = strings.TrimSuffix(p, "/")
+	}
+	return p
+}
+
+// javaMethodSignature is a parsed Java method declaration.
+type javaMethodSignature struct {
+	Name       string
+	ReturnType string
+	Params     []javaParam
+}
+
+type javaParam struct {
+	Annotations JavaAnnotations
+	Type        string
+	Name        string
+}
+
+func parseJavaMethodSignature(s string) (javaMethodSignature, error) {
+	sig := javaMethodSignature{}
+	open := strings.Index(s, "(")
+	if open < 0 {
+		return sig, fmt.Errorf("java method parameters not found (%s)", s)
+	}
+	closeIdx := matchingIndex(s, open)
+	if closeIdx < 0 {
+		return sig, fmt.Errorf("java method parameters not closed (%s)", s)
+	}
+	head := strings.TrimSpace(rxJavaModifiers.ReplaceAllString(strings.TrimSpace(s[:open]), ""))
+	if strings.HasPrefix(head, "<") {
+		if end := matchingIndex(head, 0); end > -1 {
+			head = strings.TrimSpace(head[end+1:])
+		}
+	}
+	idx := strings.LastIndexAny(head, " \t\n>")
+	if idx < 0 {
+		return sig, fmt.Errorf("java method return type not found (%s)", s)
+	}
+	sig.Name = strings.TrimSpace(head[idx+1:])
+	sig.ReturnType = strings.TrimSpace(head[:idx+1])
+	for _, paramSrc := range splitTopLevel(s[open+1:closeIdx], ',') {
+		if len(paramSrc) == 0 {
+			continue
+		}
+		anns, rest := parseJavaAnnotations(paramSrc)
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, "final "))
+		pIdx := strings.LastIndexAny(rest, " \t\n>")
+		if pIdx < 0 {
+			continue
+		}
+		sig.Params = append(sig.Params, javaParam{
+			Annotations: anns,
+			Type:        strings.TrimSpace(rest[:pIdx+1]),
+			Name:        strings.TrimSpace(rest[pIdx+1:])})
+	}
+	return sig, nil
+}
+
+func (sig javaMethodSignature) operationMeta(anns JavaAnnotations, mapping JavaAnnotation) *openapi3.OperationMeta {
+	om := &openapi3.OperationMeta{
+		OperationID: sig.Name,
+		MetaNotes:   []string{}}
+	if consumes := mapping.Strings("consumes"); len(consumes) > 0 {
+		om.RequestBodyMediaType = consumes[0]
+	}
+	if produces := mapping.Strings("produces"); len(produces) > 0 {
+		om.ResponseMediaType = produces[0]
+	}
+	for _, p := range sig.Params {
+		switch {
+		case p.Annotations.Has(AnnotationPathVariable):
+			ann, _ := p.Annotations.Get(AnnotationPathVariable)
+			param := oas3.NewPathParameter(annotationParamName(ann, p.Name))
+			param.Schema = nonNilSchema(JavaTypeSchemaRef(p.Type))
+			om.Parameters = append(om.Parameters, &oas3.ParameterRef{Value: param})
+		case p.Annotations.Has(AnnotationRequestParam):
+			ann, _ := p.Annotations.Get(AnnotationRequestParam)
+			if base, _ := javaGenericParts(p.Type); strings.HasSuffix(base, "Map") || base == "MultipartFile" {
+				om.MetaNotes = append(om.MetaNotes, fmt.Sprintf("request param (%s) of type (%s) not converted", p.Name, p.Type))
+				continue
+			}
+			om.Parameters = append(om.Parameters, &oas3.ParameterRef{
+				Value: valueParameter(oas3.NewQueryParameter(annotationParamName(ann, p.Name)), ann, p.Type)})
+		case p.Annotations.Has(AnnotationRequestHeader):
+			ann, _ := p.Annotations.Get(AnnotationRequestHeader)
+			if base, _ := javaGenericParts(p.Type); strings.HasSuffix(base, "Map") || base == "HttpHeaders" {
+				continue
+			}
+			om.Parameters = append(om.Parameters, &oas3.ParameterRef{
+				Value: valueParameter(oas3.NewHeaderParameter(annotationParamName(ann, p.Name)), ann, p.Type)})
+		case p.Annotations.Has(AnnotationRequestBody):
+			ann, _ := p.Annotations.Get(AnnotationRequestBody)
+			om.RequestBodySchema = nonNilSchema(JavaTypeSchemaRef(p.Type))
+			om.RequestBodyRequired = true
+			if req, ok := ann.Bool("required"); ok {
+				om.RequestBodyRequired = req
+			}
+		case len(p.Annotations) == 0 && JavaSimpleScalar(p.Type):
+			// Spring binds unannotated simple types as optional request parameters.
+			param := oas3.NewQueryParameter(p.Name)
+			param.Schema = JavaTypeSchemaRef(p.Type)
+			om.Parameters = append(om.Parameters, &oas3.ParameterRef{Value: param})
+		}
+	}
+	om.ResponseStatus = strconv.Itoa(http.StatusOK)
+	if ann, ok := anns.Get(AnnotationResponseStatus); ok {
+		for _, key := range []string{"value", "code"} {
+			if m := rxHTTPStatus.FindStringSubmatch(ann.Args[key]); len(m) > 1 {
+				if code, ok := httpStatusNames[m[1]]; ok {
+					om.ResponseStatus = strconv.Itoa(code)
+				}
+			}
+		}
+	}
+	om.ResponseSchema = JavaTypeSchemaRef(sig.ReturnType)
+	if base, args := javaGenericParts(sig.ReturnType); base == "ResponseEntity" && (len(args) == 0 || strings.TrimSpace(args[0]) == "?") {
+		om.ResponseSchema = nil
+	}
+	return om
+}
+
+// annotationParamName returns the `value` or `name` argument, defaulting to
+// the Java parameter name.
+func annotationParamName(ann JavaAnnotation, javaName string) string {
+	if name, ok := ann.String("value", "name"); ok && len(name) > 0 {
+		return name
+	}
+	return javaName
+}
+
+// valueParameter sets the schema, `required` and `defaultValue` for
+// `@RequestParam` and `@RequestHeader` parameters. Spring parameters are
+// required by default unless a default value is set.
+func valueParameter(param *oas3.Parameter, ann JavaAnnotation, javaType string) *oas3.Parameter {
+	param.Schema = nonNilSchema(JavaTypeSchemaRef(javaType))
+	param.Required = true
+	if def, ok := ann.String("defaultValue"); ok {
+		param.Required = false
+		if param.Schema.Ref == "" {
+			param.Schema.Value.Default = defaultValue(param.Schema.Value.Type, def)
+		}
+	}
+	if req, ok := ann.Bool("required"); ok {
+		param.Required = req
+	}
+	if base, _ := javaGenericParts(javaType); base == "Optional" {
+		param.Required = false
+	}
+	return param
+}
+
+func defaultValue(schemaType, def string) any {
+	switch schemaType {
+	case openapi3.TypeInteger:
+		if v, err := strconv.ParseInt(def, 10, 64); err == nil {
+			return v
+		}
+	case openapi3.TypeNumber:
+		if v, err := strconv.ParseFloat(def, 64); err == nil {
+			return v
+		}
+	case openapi3.TypeBoolean:
+		if v, err := strconv.ParseBool(def); err == nil {
+			return v
+		}
+	}
+	return def
+}
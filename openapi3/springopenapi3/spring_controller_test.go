@@ -0,0 +1,146 @@
+package springopenapi3
+
+import (
+	"strings"
+	"testing"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/openapi3edit"
+)
+
+const springControllerTest = `package com.example.users;
+
+import org.springframework.web.bind.annotation.*;
+
+/**
+ * Users API. See https://example.com/docs
+ */
+@RestController
+@RequestMapping("/api/v1/users")
+public class UserController {
+
+    private final UserService userService; // injected
+
+    @GetMapping
+    public ResponseEntity<List<User>> listUsers(
+            @RequestParam(name = "page", defaultValue = "1") Integer page,
+            @RequestHeader("X-Tenant-Id") String tenantId) {
+        return ResponseEntity.ok(userService.list(page));
+    }
+
+    @GetMapping(value = "/{id:[0-9]+}", produces = "application/json")
+    public User getUser(@PathVariable("id") Long userId) {
+        if (userId == null) { throw new IllegalArgumentException("{}"); }
+        return userService.get(userId);
+    }
+
+    @ResponseStatus(HttpStatus.CREATED)
+    @PostMapping
+    public User createUser(@RequestBody User user) {
+        return userService.create(user);
+    }
+
+    @RequestMapping(path = {"/{id}"}, method = {RequestMethod.PUT, RequestMethod.PATCH})
+    public void updateUser(@PathVariable Long id, @RequestBody(required = false) Map<String, Object> fields) {
+        userService.update(id, fields);
+    }
+
+    private void helper() {}
+}
+`
+
+var springControllerOpTests = []struct {
+	method       string
+	path         string
+	operationID  string
+	paramsCount  int
+	status       string
+	hasReqBody   bool
+	respSchemaOK bool
+}{
+	{"GET", "/api/v1/users", "listUsers", 2, "200", false, true},
+	{"GET", "/api/v1/users/{id}", "getUser", 1, "200", false, true},
+	{"POST", "/api/v1/users", "createUser", 0, "201", true, true},
+	{"PUT", "/api/v1/users/{id}", "updateUser", 1, "200", true, false},
+	{"PATCH", "/api/v1/users/{id}", "updateUser", 1, "200", true, false},
+}
+
+func TestParseSpringController(t *testing.T) {
+	oms, err := ParseSpringController(springControllerTest)
+	if err != nil {
+		t.Fatalf("springopenapi3.ParseSpringController() error [%v]", err)
+	}
+	if len(oms) != len(springControllerOpTests) {
+		t.Fatalf("springopenapi3.ParseSpringController() operation count mismatch: want [%d], got [%d]",
+			len(springControllerOpTests), len(oms))
+	}
+	for i, tt := range springControllerOpTests {
+		om := oms[i]
+		if om.Method != tt.method || om.Path != tt.path || om.OperationID != tt.operationID ||
+			len(om.Parameters) != tt.paramsCount || om.ResponseStatus != tt.status ||
+			(om.RequestBodySchema != nil) != tt.hasReqBody || (om.ResponseSchema != nil) != tt.respSchemaOK {
+			t.Errorf("springopenapi3.ParseSpringController() operation [%d] mismatch: want [%s %s %s %d %s], got [%s %s %s %d %s]",
+				i, tt.method, tt.path, tt.operationID, tt.paramsCount, tt.status,
+				om.Method, om.Path, om.OperationID, len(om.Parameters), om.ResponseStatus)
+		}
+	}
+	if p := oms[0].Parameters[0].Value; p.Required || p.Schema.Value.Default != int64(1) {
+		t.Errorf("springopenapi3.ParseSpringController() request param default mismatch")
+	}
+	if oms[0].ResponseSchema.Value.Items.Ref != "#/components/schemas/User" {
+		t.Errorf("springopenapi3.ParseSpringController() return type mismatch")
+	}
+	spec, err := oms.Spec("", "")
+	if err != nil {
+		t.Fatalf("springopenapi3.OperationMetas.Spec() error [%v]", err)
+	}
+	if op := spec.Paths.Find("/api/v1/users").Post; op == nil || op.Responses.Value("201") == nil || op.Tags[0] != "User" {
+		t.Errorf("springopenapi3.OperationMetas.Spec() operation [POST /api/v1/users] mismatch")
+	}
+}
+
+const springControllerRefsTest = `@RestController
+@RequestMapping("/orders")
+public class OrderController {
+    @GetMapping("/{id}")
+    public Order getOrder(@PathVariable Long id) { return null; }
+
+    @PostMapping
+    public List<Order> createOrders(@RequestBody OrderRequest req) { return null; }
+}
+`
+
+func TestControllerSpec(t *testing.T) {
+	oms, err := ParseSpringController(springControllerRefsTest)
+	if err != nil {
+		t.Fatalf("springopenapi3.ParseSpringController() error [%v]", err)
+	}
+	order := oas3.NewObjectSchema()
+	order.Properties["customer"] = oas3.NewSchemaRef("#/components/schemas/Customer", nil)
+	spec, err := ControllerSpec(oms, oas3.Schemas{"Order": oas3.NewSchemaRef("", order)}, "", "")
+	if err != nil {
+		t.Fatalf("springopenapi3.ControllerSpec() error [%v]", err)
+	}
+	if spec.Components.Schemas["Order"].Value != order {
+		t.Errorf("springopenapi3.ControllerSpec() schema [Order] not taken from schemas")
+	}
+	refs := 0
+	se := openapi3edit.NewSpecEdit(spec)
+	se.SchemaRefsModify(func(ref string) string {
+		if name, ok := strings.CutPrefix(ref, "#/components/schemas/"); ok {
+			refs++
+			if _, ok := spec.Components.Schemas[name]; !ok {
+				t.Errorf("springopenapi3.ControllerSpec() ref does not resolve [%s]", ref)
+			}
+		}
+		return ref
+	})
+	if refs < 4 {
+		t.Errorf("springopenapi3.ControllerSpec() ref count mismatch: want [>=4], got [%d]", refs)
+	}
+	for _, name := range []string{"OrderRequest", "Customer"} {
+		if sch, ok := spec.Components.Schemas[name]; !ok || sch.Value.Type != "object" {
+			t.Errorf("springopenapi3.ControllerSpec() placeholder schema [%s] missing", name)
+		}
+	}
+}
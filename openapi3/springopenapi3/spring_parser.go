@@ -31,36 +31,67 @@ var (
 )
 
 // ParseSpringPropertyLinesSliceToSchema takes a set of string slices
-// and attempts to parse one property per set of lines.
+// and attempts to parse one property per set of lines. Use
+// `ParseSpringPropertyLinesSliceToObjectSchema()` to keep `required`.
 func ParseSpringPropertyLinesSliceToSchema(groups [][]string) (map[string]*oas3.SchemaRef, error) {
-	mss := map[string]*oas3.SchemaRef{}
+	sch, err := ParseSpringPropertyLinesSliceToObjectSchema(groups)
+	if err != nil {
+		return map[string]*oas3.SchemaRef{}, err
+	}
+	return sch.Properties, nil
+}
+
+// ParseSpringPropertyLinesSliceToObjectSchema takes a set of string slices,
+// parses one property per set of lines and returns an object schema with
+// the properties and those required by annotations such as `@NotNull`.
+func ParseSpringPropertyLinesSliceToObjectSchema(groups [][]string) (*oas3.Schema, error) {
+	sch := oas3.NewObjectSchema()
 	for _, group := range groups {
-		name, prop, err := ParseSpringPropertyLinesToSchema(group)
+		name, prop, required, err := parseSpringPropertyLines(group)
 		if err != nil {
-			return mss, err
+			return sch, err
 		} else if name == "" || prop == nil {
 			continue
 		}
-		if prop != nil {
-			mss[name] = oas3.NewSchemaRef("", prop)
-		} else {
-			mss[name] = oas3.NewSchemaRef(name, nil)
+		sch.Properties[name] = oas3.NewSchemaRef("", prop)
+		if required {
+			sch.Required = append(sch.Required, name)
 		}
 	}
-	return mss, nil
+	return sch, nil
 }
 
 // ParseSpringPropertyLinesToSchema parses a set of lines looking for
 // a property line. Only one property line is matched in this set.
+// Annotations on preceding lines, such as `@NotNull` or `@JsonProperty`,
+// are applied to the property. Use `ParseSpringPropertyLinesSliceToObjectSchema()`
+// to keep `required`.
 func ParseSpringPropertyLinesToSchema(lines []string) (string, *oas3.Schema, error) {
+	name, prop, _, err := parseSpringPropertyLines(lines)
+	return name, prop, err
+}
+
+func parseSpringPropertyLines(lines []string) (string, *oas3.Schema, bool, error) {
+	lineAnns := JavaAnnotations{}
 	for _, line := range lines {
-		name, prop, err := ParseSpringLineToSchema(line)
+		anns, rest := parseJavaAnnotations(line)
+		if len(rest) == 0 {
+			lineAnns = append(lineAnns, anns...)
+			continue
+		}
+		name, prop, err := parseSpringLineToSchema(rest)
 		if err != nil { // not every line is designed to match
 			continue
+		} else if name == "" {
+			return "", nil, false, nil
 		}
-		return name, prop, nil
+		name, required, ignored := applySpringAnnotations(append(lineAnns, anns...), name, oas3.NewSchemaRef("", prop))
+		if ignored {
+			return "", nil, false, nil
+		}
+		return name, prop, required, nil
 	}
-	return "", nil, nil
+	return "", nil, false, nil
 }
 
 func lineToBoolDef(line string) (string, oas3.Schema) {
@@ -157,29 +188,71 @@ func lineToArrayDef(line string, explicitCustomTypes []string) (string, *oas3.Sc
 
 // ParseSpringLinesToMapStringSchemaRefs parses a Spring Java code line and
 // attempts to extract a property name, type, format and default value.
+// Use `ParseSpringLinesToObjectSchema()` to keep `required`.
 func ParseSpringLinesToMapStringSchemaRefs(lines, explicitCustomTypes []string) (map[string]*oas3.SchemaRef, error) {
-	mss := map[string]*oas3.SchemaRef{}
+	sch, err := ParseSpringLinesToObjectSchema(lines, explicitCustomTypes)
+	return sch.Properties, err
+}
+
+// ParseSpringLinesToObjectSchema parses Spring Java field lines into an
+// object schema. Annotations on their own lines apply to the next field
+// line and properties with annotations such as `@NotNull` are `required`.
+func ParseSpringLinesToObjectSchema(lines, explicitCustomTypes []string) (*oas3.Schema, error) {
+	sch := oas3.NewObjectSchema()
+	pending := JavaAnnotations{}
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if len(line) == 0 {
+		anns, rest := parseJavaAnnotations(strings.TrimSpace(line))
+		if len(rest) == 0 {
+			pending = append(pending, anns...)
 			continue
 		}
-		name, prop, err := ParseSpringLineToSchemaRef(line, explicitCustomTypes)
+		name, prop, required, err := parseSpringLineToProperty(append(pending, anns...), rest, explicitCustomTypes)
+		pending = JavaAnnotations{}
 		if err != nil {
-			return mss, err
+			return sch, err
 		} else if name == "" || prop == nil {
 			continue
-		} else {
-			mss[name] = prop
+		}
+		sch.Properties[name] = prop
+		if required {
+			sch.Required = append(sch.Required, name)
 		}
 	}
-	return mss, nil
+	return sch, nil
 }
 
 // ParseSpringLineToSchemaRef parses a Spring Java code line and
 // attempts to extract a property name, type, format and default
-// value.
+// value. Leading Bean Validation and Jackson annotations are applied
+// and an empty name is returned for `@JsonIgnore` properties. Use
+// `ParseSpringLineToProperty()` to keep `required`.
 func ParseSpringLineToSchemaRef(line string, explicitCustomTypes []string) (string, *oas3.SchemaRef, error) {
+	name, schRef, _, err := ParseSpringLineToProperty(line, explicitCustomTypes)
+	return name, schRef, err
+}
+
+// ParseSpringLineToProperty parses a Spring Java code line like
+// `ParseSpringLineToSchemaRef()` and also returns whether the property is
+// required by annotations such as `@NotNull`, `@NotBlank` or
+// `@JsonProperty(required = true)`.
+func ParseSpringLineToProperty(line string, explicitCustomTypes []string) (string, *oas3.SchemaRef, bool, error) {
+	anns, line := parseJavaAnnotations(line)
+	return parseSpringLineToProperty(anns, line, explicitCustomTypes)
+}
+
+func parseSpringLineToProperty(anns JavaAnnotations, line string, explicitCustomTypes []string) (string, *oas3.SchemaRef, bool, error) {
+	name, schRef, err := parseSpringLineToSchemaRef(line, explicitCustomTypes)
+	if err != nil || len(name) == 0 {
+		return name, schRef, false, err
+	}
+	name, required, ignored := applySpringAnnotations(anns, name, schRef)
+	if ignored {
+		return "", nil, false, nil
+	}
+	return name, schRef, required, nil
+}
+
+func parseSpringLineToSchemaRef(line string, explicitCustomTypes []string) (string, *oas3.SchemaRef, error) {
 	var sch oas3.Schema
 	line = strings.Trim(line, " \t")
 
@@ -252,8 +325,23 @@ func schemaPath(object string) string {
 
 // ParseSpringLineToSchema parses a Spring Java code line and
 // attempts to extract a property name, type, format and default
-// value. DEPRECATED
+// value. Leading annotations are applied as with `ParseSpringLineToSchemaRef()`.
+// Use `ParseSpringLineToProperty()` to keep `required`.
+// DEPRECATED
 func ParseSpringLineToSchema(line string) (string, *oas3.Schema, error) {
+	anns, line := parseJavaAnnotations(line)
+	name, sch, err := parseSpringLineToSchema(line)
+	if err != nil || len(name) == 0 {
+		return name, sch, err
+	}
+	name, _, ignored := applySpringAnnotations(anns, name, oas3.NewSchemaRef("", sch))
+	if ignored {
+		return "", nil, nil
+	}
+	return name, sch, nil
+}
+
+func parseSpringLineToSchema(line string) (string, *oas3.Schema, error) {
 	var sch oas3.Schema
 	line = strings.Trim(line, " \t")
 
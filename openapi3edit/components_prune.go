@@ -0,0 +1,87 @@
+package openapi3edit
+
+import (
+	"github.com/grokify/gocharts/v2/data/table"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+// PruneReport lists the components removed by
+// `SpecEdit.PruneUnusedComponents()`, or which would be removed for a dry
+// run.
+type PruneReport struct {
+	DryRun  bool
+	Removed []openapi3.ComponentKey
+}
+
+// Counts returns the number of removed components by kind.
+func (r *PruneReport) Counts() map[string]int {
+	counts := map[string]int{}
+	for _, key := range r.Removed {
+		counts[key.Kind]++
+	}
+	return counts
+}
+
+func (r *PruneReport) Table() *table.Table {
+	name := "Removed Components"
+	if r.DryRun {
+		name = "Unused Components"
+	}
+	tbl := table.NewTable(name)
+	tbl.Columns = []string{"Kind", "Name", "Pointer"}
+	for _, key := range r.Removed {
+		tbl.Rows = append(tbl.Rows, []string{key.Kind, key.Name, key.Pointer()})
+	}
+	return &tbl
+}
+
+// PruneUnusedComponents removes the components returned by
+// `SpecMore.ComponentsUnused()`. Reachability is transitive, so components
+// which are only used by removed components are removed in the same call
+// and the result has no unused components. With `dryRun`, the spec is not
+// modified.
+func (se *SpecEdit) PruneUnusedComponents(dryRun bool) (*PruneReport, error) {
+	defer se.journalEdit("PruneUnusedComponents")()
+	if se.SpecMore.Spec == nil {
+		return nil, openapi3.ErrSpecNotSet
+	}
+	unused, err := se.SpecMore.ComponentsUnused()
+	if err != nil {
+		return nil, err
+	}
+	report := &PruneReport{DryRun: dryRun, Removed: unused}
+	if dryRun {
+		return report, nil
+	}
+	for _, key := range unused {
+		componentDelete(se.SpecMore.Spec, key)
+	}
+	return report, nil
+}
+
+func componentDelete(spec *openapi3.Spec, key openapi3.ComponentKey) {
+	comps := spec.Components
+	if comps == nil {
+		return
+	}
+	switch key.Kind {
+	case openapi3.PathSchemas:
+		delete(comps.Schemas, key.Name)
+	case openapi3.PathParameters:
+		delete(comps.Parameters, key.Name)
+	case "headers":
+		delete(comps.Headers, key.Name)
+	case "requestBodies":
+		delete(comps.RequestBodies, key.Name)
+	case "responses":
+		delete(comps.Responses, key.Name)
+	case openapi3.PathSecuritySchemes:
+		delete(comps.SecuritySchemes, key.Name)
+	case "examples":
+		delete(comps.Examples, key.Name)
+	case "links":
+		delete(comps.Links, key.Name)
+	case "callbacks":
+		delete(comps.Callbacks, key.Name)
+	}
+}
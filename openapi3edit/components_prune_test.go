@@ -0,0 +1,80 @@
+package openapi3edit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const pruneTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "security": [{"apiKey": []}],
+  "paths": {
+    "/pets": {
+      "get": {
+        "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"owner": {"$ref": "#/components/schemas/Owner"}}},
+      "Owner": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "Orphan": {"type": "object", "properties": {"child": {"$ref": "#/components/schemas/OrphanChild"}}},
+      "OrphanChild": {"type": "string"},
+      "CycleA": {"type": "object", "properties": {"b": {"$ref": "#/components/schemas/CycleB"}}},
+      "CycleB": {"type": "object", "properties": {"a": {"$ref": "#/components/schemas/CycleA"}}}
+    },
+    "parameters": {
+      "Limit": {"name": "limit", "in": "query", "schema": {"$ref": "#/components/schemas/Owner"}}
+    },
+    "responses": {
+      "PetResponse": {"description": "A pet", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+    },
+    "securitySchemes": {
+      "apiKey": {"type": "apiKey", "in": "header", "name": "X-API-Key"},
+      "basic": {"type": "http", "scheme": "basic"}
+    }
+  }
+}`
+
+func TestPruneUnusedComponents(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(pruneTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	want := []string{
+		"#/components/parameters/Limit",
+		"#/components/responses/PetResponse",
+		"#/components/schemas/CycleA",
+		"#/components/schemas/CycleB",
+		"#/components/schemas/Orphan",
+		"#/components/schemas/OrphanChild",
+		"#/components/securitySchemes/basic"}
+	se := NewSpecEdit(spec)
+	for _, dryRun := range []bool{true, false} {
+		report, err := se.PruneUnusedComponents(dryRun)
+		if err != nil {
+			t.Fatalf("openapi3edit.SpecEdit.PruneUnusedComponents() error [%v]", err)
+		}
+		got := []string{}
+		for _, key := range report.Removed {
+			got = append(got, key.Pointer())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("openapi3edit.SpecEdit.PruneUnusedComponents() mismatch: dryRun [%v] want [%v], got [%v]", dryRun, want, got)
+		}
+	}
+	if len(spec.Components.Schemas) != 2 || spec.Components.Schemas["Owner"] == nil {
+		t.Errorf("openapi3edit.SpecEdit.PruneUnusedComponents() schemas mismatch: want [2], got [%d]", len(spec.Components.Schemas))
+	}
+	unused, err := se.SpecMore.ComponentsUnused()
+	if err != nil {
+		t.Fatalf("openapi3.SpecMore.ComponentsUnused() error [%v]", err)
+	}
+	if len(unused) != 0 {
+		t.Errorf("openapi3.SpecMore.ComponentsUnused() fixpoint mismatch: want [0], got [%v]", unused)
+	}
+}
@@ -9,6 +9,7 @@ import (
 )
 
 func (se *SpecEdit) DeleteProperties(md openapi3.SpecMetadata) {
+	defer se.journalEdit("DeleteProperties")()
 	if se.SpecMore.Spec == nil {
 		return
 	}
@@ -45,6 +46,7 @@ func (se *SpecEdit) DeleteProperties(md openapi3.SpecMetadata) {
 }
 
 func (se *SpecEdit) DeleteOperations(delThis func(urlpath, method string, op *oas3.Operation) bool) {
+	defer se.journalEdit("DeleteOperations")()
 	if se.SpecMore.Spec == nil {
 		return
 	}
@@ -8,6 +8,7 @@ import (
 )
 
 func (se *SpecEdit) SchemasSetDeprecated(newDeprecated bool) {
+	defer se.journalEdit("SchemasSetDeprecated")()
 	if se.SpecMore.Spec == nil {
 		return
 	}
@@ -19,6 +20,7 @@ func (se *SpecEdit) SchemasSetDeprecated(newDeprecated bool) {
 }
 
 func (se *SpecEdit) OperationsSetDeprecated(newDeprecated bool) {
+	defer se.journalEdit("OperationsSetDeprecated")()
 	if se.SpecMore.Spec == nil {
 		return
 	}
@@ -35,6 +37,7 @@ func (se *SpecEdit) OperationsSetDeprecated(newDeprecated bool) {
 var rxDeprecated = regexp.MustCompile(`(?i)\bdeprecated\b`)
 
 func (se *SpecEdit) SetDeprecatedImplicit() {
+	defer se.journalEdit("SetDeprecatedImplicit")()
 	if se.SpecMore.Spec == nil {
 		return
 	}
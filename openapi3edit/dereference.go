@@ -0,0 +1,420 @@
+package openapi3edit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/mogo/encoding/jsonpointer"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+// XCircular marks where a recursive schema was cut off by
+// `DereferenceCircularDepth`. Its value is the schema reference.
+const XCircular = "x-circular"
+
+const (
+	// DereferenceCircularKeep keeps the `$ref` of recursive schemas.
+	DereferenceCircularKeep = "keep"
+	// DereferenceCircularDepth inlines recursive schemas until
+	// `DereferenceOptions.MaxDepth` and then adds an empty schema with an
+	// `x-circular` marker.
+	DereferenceCircularDepth = "depth"
+)
+
+// DereferenceOptions configures `SpecEdit.Dereference()`.
+type DereferenceOptions struct {
+	// Circular is `DereferenceCircularKeep` (default) or
+	// `DereferenceCircularDepth`.
+	Circular string
+	// MaxDepth is the number of times a recursive schema is inlined within
+	// itself for `DereferenceCircularDepth`. Defaults to 1.
+	MaxDepth int
+}
+
+// DereferenceReport lists the results of `SpecEdit.Dereference()`.
+type DereferenceReport struct {
+	// Inlined is the number of references replaced.
+	Inlined int
+	// Circular lists recursive schema references kept or cut off.
+	Circular []string
+	// Unresolved lists references which could not be inlined, such as
+	// external references and references to missing components.
+	Unresolved []string
+}
+
+// Dereference replaces `$ref`s for schemas, parameters, responses, request
+// bodies, headers and examples with the referenced values, in paths and in
+// components. Each use gets its own copy. Recursive schemas are handled per
+// `DereferenceOptions.Circular`. Components are kept so references left in
+// place still resolve. Callbacks and links are not changed.
+func (se *SpecEdit) Dereference(opts *DereferenceOptions) (*DereferenceReport, error) {
+	defer se.journalEdit("Dereference")()
+	if se.SpecMore.Spec == nil {
+		return nil, openapi3.ErrSpecNotSet
+	}
+	if opts == nil {
+		opts = &DereferenceOptions{}
+	}
+	switch opts.Circular {
+	case "", DereferenceCircularKeep, DereferenceCircularDepth:
+	default:
+		return nil, fmt.Errorf("unknown circular option [%s]", opts.Circular)
+	}
+	maxDepth := opts.MaxDepth
+	if maxDepth < 1 {
+		maxDepth = 1
+	}
+	spec := se.SpecMore.Spec
+	d := &dereferencer{
+		spec:       spec,
+		depth:      opts.Circular == DereferenceCircularDepth,
+		maxDepth:   maxDepth,
+		active:     map[string]int{},
+		circular:   map[string]bool{},
+		unresolved: map[string]bool{},
+		report:     &DereferenceReport{}}
+
+	if spec.Paths != nil {
+		for _, pathItem := range spec.Paths.Map() {
+			if pathItem == nil {
+				continue
+			}
+			pathItem.Parameters = d.parameters(pathItem.Parameters)
+			for _, op := range pathItem.Operations() {
+				op.Parameters = d.parameters(op.Parameters)
+				op.RequestBody = d.requestBodyRef(op.RequestBody)
+				if op.Responses != nil {
+					for code, respRef := range op.Responses.Map() {
+						op.Responses.Set(code, d.responseRef(respRef))
+					}
+				}
+			}
+		}
+	}
+	if comps := spec.Components; comps != nil {
+		for name, schRef := range comps.Schemas {
+			// The component is active so self references are circular.
+			key := openapi3.PointerComponentsSchemas + "/" + jsonpointer.PropertyNameEscape(name)
+			d.active[key]++
+			comps.Schemas[name] = d.schemaRef(schRef)
+			d.active[key]--
+		}
+		for name, paramRef := range comps.Parameters {
+			comps.Parameters[name] = d.parameterRef(paramRef)
+		}
+		for name, respRef := range comps.Responses {
+			comps.Responses[name] = d.responseRef(respRef)
+		}
+		for name, bodyRef := range comps.RequestBodies {
+			comps.RequestBodies[name] = d.requestBodyRef(bodyRef)
+		}
+		comps.Headers = d.headers(comps.Headers)
+		comps.Examples = d.examples(comps.Examples)
+	}
+	d.report.Circular = sortedSet(d.circular)
+	d.report.Unresolved = sortedSet(d.unresolved)
+	return d.report, nil
+}
+
+type dereferencer struct {
+	spec       *openapi3.Spec
+	depth      bool
+	maxDepth   int
+	active     map[string]int
+	circular   map[string]bool
+	unresolved map[string]bool
+	report     *DereferenceReport
+}
+
+// component returns the name of a local component reference of a kind.
+func (d *dereferencer) component(ref, kind string) (string, bool) {
+	prefix := "#/" + openapi3.PathComponents + "/" + kind + "/"
+	if d.spec.Components == nil || !strings.HasPrefix(ref, prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(ref, prefix)
+	if strings.Contains(name, "/") {
+		return "", false
+	}
+	return jsonpointer.PropertyNameUnescape(name), true
+}
+
+func (d *dereferencer) schemaRef(schRef *oas3.SchemaRef) *oas3.SchemaRef {
+	if schRef == nil {
+		return nil
+	} else if schRef.Ref != "" {
+		name, ok := d.component(schRef.Ref, openapi3.PathSchemas)
+		if !ok {
+			d.unresolved[schRef.Ref] = true
+			return schRef
+		}
+		comp, ok := d.spec.Components.Schemas[name]
+		if !ok || comp == nil {
+			d.unresolved[schRef.Ref] = true
+			return schRef
+		}
+		if n := d.active[schRef.Ref]; n > 0 && (!d.depth || n > d.maxDepth) {
+			d.circular[schRef.Ref] = true
+			if !d.depth {
+				return schRef
+			}
+			return oas3.NewSchemaRef("", &oas3.Schema{
+				Extensions: map[string]any{XCircular: schRef.Ref}})
+		}
+		d.active[schRef.Ref]++
+		defer func() { d.active[schRef.Ref]-- }()
+		resolved := d.schemaRef(comp)
+		if resolved.Ref != "" {
+			return schRef
+		}
+		d.report.Inlined++
+		return resolved
+	} else if schRef.Value == nil {
+		return schRef
+	}
+	sch := *schRef.Value
+	if sch.Properties != nil {
+		props := oas3.Schemas{}
+		for propName, propRef := range sch.Properties {
+			props[propName] = d.schemaRef(propRef)
+		}
+		sch.Properties = props
+	}
+	sch.Items = d.schemaRef(sch.Items)
+	sch.Not = d.schemaRef(sch.Not)
+	sch.AdditionalProperties.Schema = d.schemaRef(sch.AdditionalProperties.Schema)
+	sch.AllOf = d.schemaRefs(sch.AllOf)
+	sch.AnyOf = d.schemaRefs(sch.AnyOf)
+	sch.OneOf = d.schemaRefs(sch.OneOf)
+	return oas3.NewSchemaRef("", &sch)
+}
+
+func (d *dereferencer) schemaRefs(schRefs oas3.SchemaRefs) oas3.SchemaRefs {
+	if schRefs == nil {
+		return nil
+	}
+	out := oas3.SchemaRefs{}
+	for _, schRef := range schRefs {
+		out = append(out, d.schemaRef(schRef))
+	}
+	return out
+}
+
+// enter guards against reference chains between non-schema components.
+func (d *dereferencer) enter(ref string) bool {
+	if d.active[ref] > 0 {
+		d.unresolved[ref] = true
+		return false
+	}
+	d.active[ref]++
+	return true
+}
+
+func (d *dereferencer) leave(ref string) {
+	d.active[ref]--
+}
+
+func (d *dereferencer) parameters(params oas3.Parameters) oas3.Parameters {
+	if params == nil {
+		return nil
+	}
+	out := oas3.Parameters{}
+	for _, paramRef := range params {
+		out = append(out, d.parameterRef(paramRef))
+	}
+	return out
+}
+
+func (d *dereferencer) parameterRef(paramRef *oas3.ParameterRef) *oas3.ParameterRef {
+	if paramRef == nil {
+		return nil
+	} else if paramRef.Ref != "" {
+		name, ok := d.component(paramRef.Ref, openapi3.PathParameters)
+		if !ok || d.spec.Components.Parameters[name] == nil {
+			d.unresolved[paramRef.Ref] = true
+			return paramRef
+		} else if !d.enter(paramRef.Ref) {
+			return paramRef
+		}
+		defer d.leave(paramRef.Ref)
+		comp := d.spec.Components.Parameters[name]
+		if resolved := d.parameterRef(comp); resolved.Ref == "" {
+			d.report.Inlined++
+			return resolved
+		}
+		return paramRef
+	} else if paramRef.Value == nil {
+		return paramRef
+	}
+	param := *paramRef.Value
+	d.parameter(&param)
+	return &oas3.ParameterRef{Value: &param}
+}
+
+func (d *dereferencer) parameter(param *oas3.Parameter) {
+	param.Schema = d.schemaRef(param.Schema)
+	param.Content = d.content(param.Content)
+	param.Examples = d.examples(param.Examples)
+}
+
+func (d *dereferencer) requestBodyRef(bodyRef *oas3.RequestBodyRef) *oas3.RequestBodyRef {
+	if bodyRef == nil {
+		return nil
+	} else if bodyRef.Ref != "" {
+		name, ok := d.component(bodyRef.Ref, "requestBodies")
+		if !ok || d.spec.Components.RequestBodies[name] == nil {
+			d.unresolved[bodyRef.Ref] = true
+			return bodyRef
+		} else if !d.enter(bodyRef.Ref) {
+			return bodyRef
+		}
+		defer d.leave(bodyRef.Ref)
+		comp := d.spec.Components.RequestBodies[name]
+		if resolved := d.requestBodyRef(comp); resolved.Ref == "" {
+			d.report.Inlined++
+			return resolved
+		}
+		return bodyRef
+	} else if bodyRef.Value == nil {
+		return bodyRef
+	}
+	body := *bodyRef.Value
+	body.Content = d.content(body.Content)
+	return &oas3.RequestBodyRef{Value: &body}
+}
+
+func (d *dereferencer) responseRef(respRef *oas3.ResponseRef) *oas3.ResponseRef {
+	if respRef == nil {
+		return nil
+	} else if respRef.Ref != "" {
+		name, ok := d.component(respRef.Ref, "responses")
+		if !ok || d.spec.Components.Responses[name] == nil {
+			d.unresolved[respRef.Ref] = true
+			return respRef
+		} else if !d.enter(respRef.Ref) {
+			return respRef
+		}
+		defer d.leave(respRef.Ref)
+		comp := d.spec.Components.Responses[name]
+		if resolved := d.responseRef(comp); resolved.Ref == "" {
+			d.report.Inlined++
+			return resolved
+		}
+		return respRef
+	} else if respRef.Value == nil {
+		return respRef
+	}
+	resp := *respRef.Value
+	resp.Headers = d.headers(resp.Headers)
+	resp.Content = d.content(resp.Content)
+	return &oas3.ResponseRef{Value: &resp}
+}
+
+func (d *dereferencer) headers(headers oas3.Headers) oas3.Headers {
+	if headers == nil {
+		return nil
+	}
+	out := oas3.Headers{}
+	for name, headerRef := range headers {
+		out[name] = d.headerRef(headerRef)
+	}
+	return out
+}
+
+func (d *dereferencer) headerRef(headerRef *oas3.HeaderRef) *oas3.HeaderRef {
+	if headerRef == nil {
+		return nil
+	} else if headerRef.Ref != "" {
+		name, ok := d.component(headerRef.Ref, "headers")
+		if !ok || d.spec.Components.Headers[name] == nil {
+			d.unresolved[headerRef.Ref] = true
+			return headerRef
+		} else if !d.enter(headerRef.Ref) {
+			return headerRef
+		}
+		defer d.leave(headerRef.Ref)
+		comp := d.spec.Components.Headers[name]
+		if resolved := d.headerRef(comp); resolved.Ref == "" {
+			d.report.Inlined++
+			return resolved
+		}
+		return headerRef
+	} else if headerRef.Value == nil {
+		return headerRef
+	}
+	header := *headerRef.Value
+	d.parameter(&header.Parameter)
+	return &oas3.HeaderRef{Value: &header}
+}
+
+func (d *dereferencer) examples(examples oas3.Examples) oas3.Examples {
+	if examples == nil {
+		return nil
+	}
+	out := oas3.Examples{}
+	for name, exRef := range examples {
+		out[name] = d.exampleRef(exRef)
+	}
+	return out
+}
+
+func (d *dereferencer) exampleRef(exRef *oas3.ExampleRef) *oas3.ExampleRef {
+	if exRef == nil || exRef.Ref == "" {
+		return exRef
+	}
+	name, ok := d.component(exRef.Ref, "examples")
+	if !ok || d.spec.Components.Examples[name] == nil {
+		d.unresolved[exRef.Ref] = true
+		return exRef
+	} else if !d.enter(exRef.Ref) {
+		return exRef
+	}
+	defer d.leave(exRef.Ref)
+	comp := d.spec.Components.Examples[name]
+	if resolved := d.exampleRef(comp); resolved.Ref == "" {
+		d.report.Inlined++
+		return &oas3.ExampleRef{Value: resolved.Value}
+	}
+	return exRef
+}
+
+func (d *dereferencer) content(content oas3.Content) oas3.Content {
+	if content == nil {
+		return nil
+	}
+	out := oas3.Content{}
+	for mediaType, mt := range content {
+		if mt == nil {
+			out[mediaType] = nil
+			continue
+		}
+		mtCopy := *mt
+		mtCopy.Schema = d.schemaRef(mtCopy.Schema)
+		mtCopy.Examples = d.examples(mtCopy.Examples)
+		if mtCopy.Encoding != nil {
+			encs := map[string]*oas3.Encoding{}
+			for encName, enc := range mtCopy.Encoding {
+				if enc != nil {
+					encCopy := *enc
+					encCopy.Headers = d.headers(encCopy.Headers)
+					enc = &encCopy
+				}
+				encs[encName] = enc
+			}
+			mtCopy.Encoding = encs
+		}
+		out[mediaType] = &mtCopy
+	}
+	return out
+}
+
+func sortedSet(m map[string]bool) []string {
+	out := []string{}
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
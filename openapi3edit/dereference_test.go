@@ -0,0 +1,107 @@
+package openapi3edit
+
+import (
+	"testing"
+
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const dereferenceTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {
+    "/pets/{petId}": {
+      "parameters": [{"$ref": "#/components/parameters/PetId"}],
+      "get": {
+        "responses": {
+          "200": {"$ref": "#/components/responses/PetResponse"},
+          "404": {"$ref": "errors.yaml#/components/responses/NotFound"}
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "owner": {"$ref": "#/components/schemas/Owner"},
+          "parent": {"$ref": "#/components/schemas/Pet"}
+        }
+      },
+      "Owner": {"type": "object", "properties": {"name": {"type": "string"}}}
+    },
+    "parameters": {
+      "PetId": {"name": "petId", "in": "path", "required": true, "schema": {"$ref": "#/components/schemas/Id"}}
+    },
+    "responses": {
+      "PetResponse": {
+        "description": "A pet",
+        "headers": {"X-Rate-Limit": {"$ref": "#/components/headers/RateLimit"}},
+        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+      }
+    },
+    "headers": {
+      "RateLimit": {"schema": {"type": "integer"}}
+    }
+  }
+}`
+
+func TestDereference(t *testing.T) {
+	tests := []struct {
+		opts           *DereferenceOptions
+		wantParentRef  string
+		wantCircular   bool
+		wantUnresolved []string
+	}{
+		{nil, "#/components/schemas/Pet", false, []string{"#/components/schemas/Id", "errors.yaml#/components/responses/NotFound"}},
+		{&DereferenceOptions{Circular: DereferenceCircularDepth}, "", true, []string{"#/components/schemas/Id", "errors.yaml#/components/responses/NotFound"}},
+	}
+	for _, tt := range tests {
+		spec, err := openapi3.Parse([]byte(dereferenceTestSpec))
+		if err != nil {
+			t.Fatalf("openapi3.Parse() error [%v]", err)
+		}
+		se := NewSpecEdit(spec)
+		report, err := se.Dereference(tt.opts)
+		if err != nil {
+			t.Fatalf("SpecEdit.Dereference() error [%v]", err)
+		}
+		pathItem := spec.Paths.Find("/pets/{petId}")
+		if ref := pathItem.Parameters[0].Ref; ref != "" || pathItem.Parameters[0].Value.Name != "petId" {
+			t.Errorf("SpecEdit.Dereference() parameter not inlined [%s]", ref)
+		}
+		resp := pathItem.Get.Responses.Value("200")
+		if resp.Ref != "" || resp.Value.Headers["X-Rate-Limit"].Ref != "" {
+			t.Errorf("SpecEdit.Dereference() response not inlined")
+		}
+		petRef := resp.Value.Content["application/json"].Schema
+		if petRef.Ref != "" || petRef.Value.Properties["owner"].Ref != "" {
+			t.Errorf("SpecEdit.Dereference() schema not inlined")
+		}
+		parentRef := petRef.Value.Properties["parent"]
+		if parentRef.Ref != tt.wantParentRef {
+			t.Errorf("SpecEdit.Dereference() circular mismatch: want [%s], got [%s]", tt.wantParentRef, parentRef.Ref)
+		}
+		if tt.wantCircular {
+			// Inlined once within itself, then marked.
+			grandParent := parentRef.Value.Properties["parent"]
+			if grandParent.Value.Extensions[XCircular] != "#/components/schemas/Pet" {
+				t.Errorf("SpecEdit.Dereference() missing [%s] marker", XCircular)
+			}
+		}
+		if len(report.Circular) != 1 || report.Circular[0] != "#/components/schemas/Pet" {
+			t.Errorf("SpecEdit.Dereference() circular report mismatch: got [%v]", report.Circular)
+		}
+		if len(report.Unresolved) != len(tt.wantUnresolved) {
+			t.Errorf("SpecEdit.Dereference() unresolved mismatch: want [%v], got [%v]", tt.wantUnresolved, report.Unresolved)
+		} else {
+			for i, ref := range tt.wantUnresolved {
+				if report.Unresolved[i] != ref {
+					t.Errorf("SpecEdit.Dereference() unresolved mismatch: want [%v], got [%v]", tt.wantUnresolved, report.Unresolved)
+				}
+			}
+		}
+	}
+}
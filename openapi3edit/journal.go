@@ -0,0 +1,204 @@
+package openapi3edit
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+)
+
+// JournalLabelUntracked labels changes made outside of `SpecEdit.Edit()`.
+const JournalLabelUntracked = "untracked"
+
+// Journal records edits of a `SpecEdit` as RFC 6902 JSON Patch operations.
+// `SpecEdit` methods which modify the spec are labeled with the method name
+// and other changes are recorded as `untracked` by the next edit, `Patch()`
+// or `WriteFile()`. Edits are committed as they are made unless a batch is
+// started with `SpecEdit.Begin()`.
+type Journal struct {
+	Entries []JournalEntry
+	se      *SpecEdit
+	pending []JournalEntry
+	// last is the spec after the last recorded edit.
+	last    any
+	base    any
+	inBatch bool
+	// depth counts the edits in progress so nested edits are recorded by
+	// the outermost one.
+	depth int
+}
+
+// JournalEntry is a labeled edit.
+type JournalEntry struct {
+	Label string    `json:"label"`
+	Patch JSONPatch `json:"patch"`
+}
+
+// Patch returns the committed entries as one JSON Patch after recording
+// untracked changes.
+func (j *Journal) Patch() (JSONPatch, error) {
+	if _, err := j.se.journalSync(); err != nil {
+		return nil, err
+	}
+	patch := JSONPatch{}
+	for _, entry := range j.Entries {
+		patch = append(patch, entry.Patch...)
+	}
+	return patch, nil
+}
+
+// WriteFile writes the committed entries with their labels as JSON after
+// recording untracked changes.
+func (j *Journal) WriteFile(filename string, perm os.FileMode) error {
+	if _, err := j.se.journalSync(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(j.Entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, perm)
+}
+
+// JournalStart starts recording edits and returns the journal.
+func (se *SpecEdit) JournalStart() (*Journal, error) {
+	last, err := specToJSONValue(se.SpecMore.Spec)
+	if err != nil {
+		return nil, err
+	}
+	se.journal = &Journal{se: se, last: last}
+	return se.journal, nil
+}
+
+// Journal returns the journal or nil if edits are not being recorded.
+func (se *SpecEdit) Journal() *Journal {
+	return se.journal
+}
+
+// Edit runs `fn` and records its changes to the spec with `label`. If `fn`
+// returns an error, the spec is restored. Without a journal, `fn` is run
+// as is. Edits nested in another edit are recorded with the outer label.
+func (se *SpecEdit) Edit(label string, fn func(se *SpecEdit) error) error {
+	j := se.journal
+	if j == nil || j.depth > 0 {
+		return fn(se)
+	}
+	before, err := se.journalSync()
+	if err != nil {
+		return err
+	}
+	j.depth++
+	err = fn(se)
+	j.depth--
+	if err != nil {
+		if errRestore := se.specSetJSONValue(before); errRestore != nil {
+			return errors.Join(err, errRestore)
+		}
+		return err
+	}
+	after, err := specToJSONValue(se.SpecMore.Spec)
+	if err != nil {
+		return err
+	}
+	j.record(label, before, after)
+	return nil
+}
+
+// journalEdit records the changes of a `SpecEdit` method labeled with the
+// method name. It is used as `defer se.journalEdit("Method")()`. Unlike
+// `Edit()`, the spec is not restored on error so methods behave the same
+// with and without a journal.
+func (se *SpecEdit) journalEdit(label string) func() {
+	j := se.journal
+	if j == nil {
+		return func() {}
+	} else if j.depth > 0 {
+		j.depth++
+		return func() { j.depth-- }
+	}
+	before, err := se.journalSync()
+	if err != nil {
+		// Changes are recorded as untracked by the next sync.
+		return func() {}
+	}
+	j.depth++
+	return func() {
+		j.depth--
+		if after, err := specToJSONValue(se.SpecMore.Spec); err == nil {
+			j.record(label, before, after)
+		}
+	}
+}
+
+// Begin starts a batch of edits which is recorded by `Commit()` or undone
+// by `Rollback()`.
+func (se *SpecEdit) Begin() error {
+	if se.journal == nil {
+		return errors.New("journal not started")
+	} else if se.journal.inBatch {
+		return errors.New("journal batch already started")
+	}
+	base, err := se.journalSync()
+	if err != nil {
+		return err
+	}
+	se.journal.base = base
+	se.journal.inBatch = true
+	return nil
+}
+
+// Commit adds the edits of the current batch to the journal.
+func (se *SpecEdit) Commit() error {
+	if se.journal == nil || !se.journal.inBatch {
+		return errors.New("journal batch not started")
+	}
+	if _, err := se.journalSync(); err != nil {
+		return err
+	}
+	j := se.journal
+	j.Entries = append(j.Entries, j.pending...)
+	j.pending, j.base, j.inBatch = nil, nil, false
+	return nil
+}
+
+// Rollback restores the spec to the start of the current batch and discards
+// its edits.
+func (se *SpecEdit) Rollback() error {
+	if se.journal == nil || !se.journal.inBatch {
+		return errors.New("journal batch not started")
+	}
+	j := se.journal
+	if err := se.specSetJSONValue(jsonValueCopy(j.base)); err != nil {
+		return err
+	}
+	j.last = j.base
+	j.pending, j.base, j.inBatch = nil, nil, false
+	return nil
+}
+
+// journalSync records changes made since the last recorded edit and
+// returns the current spec value.
+func (se *SpecEdit) journalSync() (any, error) {
+	cur, err := specToJSONValue(se.SpecMore.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(se.journal.last, cur) {
+		se.journal.record(JournalLabelUntracked, se.journal.last, cur)
+	}
+	return cur, nil
+}
+
+func (j *Journal) record(label string, before, after any) {
+	j.last = after
+	patch := JSONPatchDiff(before, after)
+	if len(patch) == 0 {
+		return
+	}
+	entry := JournalEntry{Label: label, Patch: patch}
+	if j.inBatch {
+		j.pending = append(j.pending, entry)
+	} else {
+		j.Entries = append(j.Entries, entry)
+	}
+}
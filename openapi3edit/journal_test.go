@@ -0,0 +1,163 @@
+package openapi3edit
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/grokify/spectrum/openapi3"
+)
+
+func TestJournal(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(pipelineTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	se := NewSpecEdit(spec)
+	j, err := se.JournalStart()
+	if err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.JournalStart() error [%v]", err)
+	}
+	err = se.Edit("rename tag", func(se *SpecEdit) error {
+		se.TagsModify(map[string]string{"pet": "Pets"})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.Edit() error [%v]", err)
+	}
+	if err := se.Begin(); err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.Begin() error [%v]", err)
+	}
+	err = se.Edit("set version", func(se *SpecEdit) error {
+		se.SpecMore.Spec.Info.Version = "2.0.0"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.Edit() error [%v]", err)
+	}
+	if err := se.Rollback(); err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.Rollback() error [%v]", err)
+	}
+	if spec.Info.Version != "1.0.0" || spec.Tags[0].Name != "Pets" {
+		t.Errorf("openapi3edit.SpecEdit.Rollback() mismatch: want [1.0.0 Pets], got [%s %s]", spec.Info.Version, spec.Tags[0].Name)
+	}
+	if err := se.Begin(); err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.Begin() error [%v]", err)
+	}
+	spec.Info.Title = "Pet Store"
+	if err := se.Commit(); err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.Commit() error [%v]", err)
+	}
+	labels := []string{}
+	for _, entry := range j.Entries {
+		labels = append(labels, entry.Label)
+	}
+	if want := []string{"rename tag", JournalLabelUntracked}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("openapi3edit.Journal.Entries mismatch: want [%v], got [%v]", want, labels)
+	}
+
+	// Replay onto a newer upstream spec with an added path.
+	upstream, err := openapi3.Parse([]byte(pipelineTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	upstream.Paths.Set("/stores", upstream.Paths.Value("/pets"))
+	jPatch, err := j.Patch()
+	if err != nil {
+		t.Fatalf("openapi3edit.Journal.Patch() error [%v]", err)
+	}
+	data, err := json.Marshal(jPatch)
+	if err != nil {
+		t.Fatalf("json.Marshal() error [%v]", err)
+	}
+	patch := JSONPatch{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		t.Fatalf("json.Unmarshal() error [%v]", err)
+	}
+	seUp := NewSpecEdit(upstream)
+	if err := seUp.ApplyPatch(patch); err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.ApplyPatch() error [%v]", err)
+	}
+	if upstream.Info.Title != "Pet Store" || upstream.Tags[0].Name != "Pets" || upstream.Paths.Value("/stores") == nil {
+		t.Errorf("openapi3edit.SpecEdit.ApplyPatch() mismatch: patch [%s]", string(data))
+	}
+}
+
+func TestJournalMethods(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(pipelineTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	se := NewSpecEdit(spec)
+	j, err := se.JournalStart()
+	if err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.JournalStart() error [%v]", err)
+	}
+	se.TagsModify(map[string]string{"pet": "Pets"})
+	se.TagsModifyMore(&TagsModifyOpts{TagsMap: map[string]string{"Pets": "Animals"}})
+	spec.Info.Title = "Pet Store"
+	patch, err := j.Patch()
+	if err != nil {
+		t.Fatalf("openapi3edit.Journal.Patch() error [%v]", err)
+	}
+	labels := []string{}
+	for _, entry := range j.Entries {
+		labels = append(labels, entry.Label)
+	}
+	if want := []string{"TagsModify", "TagsModifyMore", JournalLabelUntracked}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("openapi3edit.Journal.Entries mismatch: want [%v], got [%v]", want, labels)
+	}
+	if len(patch) == 0 {
+		t.Errorf("openapi3edit.Journal.Patch() mismatch: want operations, got [0]")
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		doc     string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{`{"a": [1, 2]}`, `[{"op": "add", "path": "/a/1", "value": 9}]`, `{"a": [1, 9, 2]}`, false},
+		{`{"a": [1, 2]}`, `[{"op": "add", "path": "/a/-", "value": 3}]`, `{"a": [1, 2, 3]}`, false},
+		{`{"a": [1, 2]}`, `[{"op": "remove", "path": "/a/0"}]`, `{"a": [2]}`, false},
+		{`{"a/b": 1}`, `[{"op": "replace", "path": "/a~1b", "value": null}]`, `{"a/b": null}`, false},
+		{`{"a": {"b": 1}}`, `[{"op": "move", "from": "/a/b", "path": "/c"}]`, `{"a": {}, "c": 1}`, false},
+		{`{"a": {"b": 1}}`, `[{"op": "copy", "from": "/a", "path": "/c"}]`, `{"a": {"b": 1}, "c": {"b": 1}}`, false},
+		{`{"a": 1}`, `[{"op": "test", "path": "/a", "value": 2}]`, ``, true},
+		{`{"a": 1}`, `[{"op": "remove", "path": "/b"}]`, ``, true},
+	}
+	for _, tt := range tests {
+		var doc, want any
+		patch := JSONPatch{}
+		if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+			t.Fatalf("json.Unmarshal() error [%v]", err)
+		}
+		if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+			t.Fatalf("json.Unmarshal() error [%v]", err)
+		}
+		got, err := ApplyJSONPatch(doc, patch)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("openapi3edit.ApplyJSONPatch() want error for [%s]", tt.patch)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("openapi3edit.ApplyJSONPatch() error [%v]", err)
+		}
+		if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+			t.Fatalf("json.Unmarshal() error [%v]", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("openapi3edit.ApplyJSONPatch() mismatch: want [%v], got [%v]", want, got)
+		}
+		// A diff of the documents applied to the original gives the result.
+		if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+			t.Fatalf("json.Unmarshal() error [%v]", err)
+		}
+		if got, err = ApplyJSONPatch(doc, JSONPatchDiff(doc, want)); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("openapi3edit.JSONPatchDiff() mismatch: want [%v], got [%v] error [%v]", want, got, err)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package openapi3edit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/mogo/encoding/jsonpointer"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	JSONPatchOpAdd     = "add"
+	JSONPatchOpRemove  = "remove"
+	JSONPatchOpReplace = "replace"
+	JSONPatchOpMove    = "move"
+	JSONPatchOpCopy    = "copy"
+	JSONPatchOpTest    = "test"
+)
+
+// JSONPatch is an RFC 6902 JSON Patch document.
+type JSONPatch []JSONPatchOperation
+
+type JSONPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// MarshalJSON includes `value` for operations which require it, including
+// null values.
+func (op JSONPatchOperation) MarshalJSON() ([]byte, error) {
+	m := map[string]any{"op": op.Op, "path": op.Path}
+	switch op.Op {
+	case JSONPatchOpAdd, JSONPatchOpReplace, JSONPatchOpTest:
+		m["value"] = op.Value
+	case JSONPatchOpMove, JSONPatchOpCopy:
+		m["from"] = op.From
+	}
+	return json.Marshal(m)
+}
+
+// ReadJSONPatchFile reads a JSON Patch file.
+func ReadJSONPatchFile(filename string) (JSONPatch, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	patch := JSONPatch{}
+	return patch, json.Unmarshal(data, &patch)
+}
+
+// WriteFile writes the patch as indented JSON.
+func (patch JSONPatch) WriteFile(filename string, perm os.FileMode) error {
+	data, err := json.MarshalIndent(patch, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, perm)
+}
+
+// ApplyPatch applies a JSON Patch to the spec. The spec is unchanged if an
+// operation fails.
+func (se *SpecEdit) ApplyPatch(patch JSONPatch) error {
+	defer se.journalEdit("ApplyPatch")()
+	if se.SpecMore.Spec == nil {
+		return openapi3.ErrSpecNotSet
+	}
+	doc, err := specToJSONValue(se.SpecMore.Spec)
+	if err != nil {
+		return err
+	}
+	if doc, err = ApplyJSONPatch(doc, patch); err != nil {
+		return err
+	}
+	return se.specSetJSONValue(doc)
+}
+
+// ApplyJSONPatch applies a JSON Patch to a document of `map[string]any`,
+// `[]any` and scalar values as decoded by `encoding/json`, and returns the
+// patched document. The document may be modified even if an error is
+// returned.
+func ApplyJSONPatch(doc any, patch JSONPatch) (any, error) {
+	var err error
+	for i, op := range patch {
+		switch op.Op {
+		case JSONPatchOpAdd:
+			doc, err = jsonPatchAdd(doc, op.Path, jsonValueCopy(op.Value))
+		case JSONPatchOpRemove:
+			doc, _, err = jsonPatchRemove(doc, op.Path)
+		case JSONPatchOpReplace:
+			if doc, _, err = jsonPatchRemove(doc, op.Path); err == nil {
+				doc, err = jsonPatchAdd(doc, op.Path, jsonValueCopy(op.Value))
+			}
+		case JSONPatchOpMove:
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				err = errors.New("cannot move a value into itself")
+				break
+			}
+			var val any
+			if doc, val, err = jsonPatchRemove(doc, op.From); err == nil {
+				doc, err = jsonPatchAdd(doc, op.Path, val)
+			}
+		case JSONPatchOpCopy:
+			var val any
+			if val, err = jsonPatchGet(doc, op.From); err == nil {
+				doc, err = jsonPatchAdd(doc, op.Path, jsonValueCopy(val))
+			}
+		case JSONPatchOpTest:
+			var val any
+			if val, err = jsonPatchGet(doc, op.Path); err == nil && !reflect.DeepEqual(val, jsonNormalize(op.Value)) {
+				err = errors.New("test failed")
+			}
+		default:
+			err = errors.New("unknown op")
+		}
+		if err != nil {
+			return doc, fmt.Errorf("json patch op %d [%s %s]: %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+// jsonNormalize converts a value to its `encoding/json` decoded form.
+func jsonNormalize(val any) any {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return val
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return val
+	}
+	return out
+}
+
+func jsonPointerTokens(ptr string) ([]string, error) {
+	if ptr == "" {
+		return []string{}, nil
+	} else if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("invalid json pointer [%s]", ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = jsonpointer.PropertyNameUnescape(t)
+	}
+	return tokens, nil
+}
+
+func jsonPatchGet(doc any, ptr string) (any, error) {
+	tokens, err := jsonPointerTokens(ptr)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		switch c := doc.(type) {
+		case map[string]any:
+			val, ok := c[t]
+			if !ok {
+				return nil, fmt.Errorf("path not found [%s]", ptr)
+			}
+			doc = val
+		case []any:
+			idx, err := strconv.Atoi(t)
+			if err != nil || idx < 0 || idx >= len(c) {
+				return nil, fmt.Errorf("invalid index [%s]", t)
+			}
+			doc = c[idx]
+		default:
+			return nil, fmt.Errorf("path not found [%s]", ptr)
+		}
+	}
+	return doc, nil
+}
+
+// jsonPatchParent returns the parent container of a pointer and the last
+// token.
+func jsonPatchParent(doc any, ptr string) (any, string, error) {
+	i := strings.LastIndex(ptr, "/")
+	if i < 0 {
+		return nil, "", fmt.Errorf("invalid json pointer [%s]", ptr)
+	}
+	parent, err := jsonPatchGet(doc, ptr[:i])
+	return parent, jsonpointer.PropertyNameUnescape(ptr[i+1:]), err
+}
+
+func jsonPatchAdd(doc any, ptr string, val any) (any, error) {
+	if ptr == "" {
+		return val, nil
+	}
+	parent, key, err := jsonPatchParent(doc, ptr)
+	if err != nil {
+		return doc, err
+	}
+	switch c := parent.(type) {
+	case map[string]any:
+		c[key] = val
+		return doc, nil
+	case []any:
+		idx := len(c)
+		if key != "-" {
+			if idx, err = strconv.Atoi(key); err != nil || idx < 0 || idx > len(c) {
+				return doc, fmt.Errorf("invalid index [%s]", key)
+			}
+		}
+		c = append(c, nil)
+		copy(c[idx+1:], c[idx:])
+		c[idx] = val
+		return jsonPatchSetContainer(doc, ptr[:strings.LastIndex(ptr, "/")], c)
+	}
+	return doc, fmt.Errorf("parent is not a container [%s]", ptr)
+}
+
+func jsonPatchRemove(doc any, ptr string) (any, any, error) {
+	if ptr == "" {
+		return nil, doc, nil
+	}
+	parent, key, err := jsonPatchParent(doc, ptr)
+	if err != nil {
+		return doc, nil, err
+	}
+	switch c := parent.(type) {
+	case map[string]any:
+		val, ok := c[key]
+		if !ok {
+			return doc, nil, fmt.Errorf("path not found [%s]", ptr)
+		}
+		delete(c, key)
+		return doc, val, nil
+	case []any:
+		idx, err := strconv.Atoi(key)
+		if err != nil || idx < 0 || idx >= len(c) {
+			return doc, nil, fmt.Errorf("invalid index [%s]", key)
+		}
+		val := c[idx]
+		c = append(c[:idx], c[idx+1:]...)
+		doc, err = jsonPatchSetContainer(doc, ptr[:strings.LastIndex(ptr, "/")], c)
+		return doc, val, err
+	}
+	return doc, nil, fmt.Errorf("parent is not a container [%s]", ptr)
+}
+
+// jsonPatchSetContainer replaces the array at `ptr`, which may have been
+// reallocated.
+func jsonPatchSetContainer(doc any, ptr string, arr []any) (any, error) {
+	if ptr == "" {
+		return arr, nil
+	}
+	parent, key, err := jsonPatchParent(doc, ptr)
+	if err != nil {
+		return doc, err
+	}
+	switch c := parent.(type) {
+	case map[string]any:
+		c[key] = arr
+	case []any:
+		idx, err := strconv.Atoi(key)
+		if err != nil {
+			return doc, err
+		}
+		c[idx] = arr
+	}
+	return doc, nil
+}
+
+// JSONPatchDiff returns a JSON Patch which transforms `doc1` into `doc2`.
+// Objects are compared by key, arrays of equal length by index and other
+// changed values are replaced.
+func JSONPatchDiff(doc1, doc2 any) JSONPatch {
+	patch := JSONPatch{}
+	jsonPatchDiff(&patch, "", doc1, doc2)
+	return patch
+}
+
+func jsonPatchDiff(patch *JSONPatch, ptr string, val1, val2 any) {
+	if reflect.DeepEqual(val1, val2) {
+		return
+	}
+	switch v1 := val1.(type) {
+	case map[string]any:
+		if v2, ok := val2.(map[string]any); ok {
+			keys := []string{}
+			for k := range v1 {
+				keys = append(keys, k)
+			}
+			for k := range v2 {
+				if _, ok := v1[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				childPtr := ptr + "/" + jsonpointer.PropertyNameEscape(k)
+				c1, in1 := v1[k]
+				c2, in2 := v2[k]
+				switch {
+				case !in2:
+					*patch = append(*patch, JSONPatchOperation{Op: JSONPatchOpRemove, Path: childPtr})
+				case !in1:
+					*patch = append(*patch, JSONPatchOperation{Op: JSONPatchOpAdd, Path: childPtr, Value: c2})
+				default:
+					jsonPatchDiff(patch, childPtr, c1, c2)
+				}
+			}
+			return
+		}
+	case []any:
+		if v2, ok := val2.([]any); ok && len(v1) == len(v2) {
+			for i := range v1 {
+				jsonPatchDiff(patch, ptr+"/"+strconv.Itoa(i), v1[i], v2[i])
+			}
+			return
+		}
+	}
+	*patch = append(*patch, JSONPatchOperation{Op: JSONPatchOpReplace, Path: ptr, Value: val2})
+}
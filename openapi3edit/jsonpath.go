@@ -0,0 +1,446 @@
+package openapi3edit
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed JSONPath expression supporting the subset used by
+// overlays: `$`, `.name`, `['name']`, `[0]`, `*`, `..` and filters such as
+// `[?(@.name == 'id' && @.in != 'query')]` and `[?@.deprecated]`.
+type jsonPath struct {
+	segments []jsonPathSegment
+}
+
+type jsonPathSegment struct {
+	recursive bool
+	wildcard  bool
+	names     []string
+	indexes   []int
+	filter    *jsonPathFilter
+}
+
+// jsonPathFilter is a filter expression. Expressions are `or` lists of `and`
+// lists of comparisons.
+type jsonPathFilter struct {
+	or [][]jsonPathComparison
+}
+
+type jsonPathComparison struct {
+	not  bool
+	path []string
+	op   string
+	val  any
+}
+
+// jsonPathLocation is a matched value and its path of map keys (string)
+// and array indexes (int) from the root.
+type jsonPathLocation struct {
+	path  []any
+	value any
+}
+
+func parseJSONPath(expr string) (*jsonPath, error) {
+	p := &jsonPathParser{expr: strings.TrimSpace(expr)}
+	if !strings.HasPrefix(p.expr, "$") {
+		return nil, fmt.Errorf("jsonpath must start with `$` [%s]", expr)
+	}
+	p.pos = 1
+	jp := &jsonPath{}
+	for !p.done() {
+		seg, err := p.segment()
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonpath [%s]: %w", expr, err)
+		}
+		jp.segments = append(jp.segments, seg)
+	}
+	return jp, nil
+}
+
+// Select returns the locations in `root` matched by the path.
+func (jp *jsonPath) Select(root any) []jsonPathLocation {
+	locs := []jsonPathLocation{{path: []any{}, value: root}}
+	for _, seg := range jp.segments {
+		var next []jsonPathLocation
+		for _, loc := range locs {
+			parents := []jsonPathLocation{loc}
+			if seg.recursive {
+				parents = jsonPathDescendants(loc)
+			}
+			for _, parent := range parents {
+				next = append(next, seg.children(parent)...)
+			}
+		}
+		locs = next
+	}
+	return locs
+}
+
+func (seg jsonPathSegment) children(loc jsonPathLocation) []jsonPathLocation {
+	var out []jsonPathLocation
+	add := func(key any, val any) {
+		path := append(append([]any{}, loc.path...), key)
+		out = append(out, jsonPathLocation{path: path, value: val})
+	}
+	switch v := loc.value.(type) {
+	case map[string]any:
+		switch {
+		case seg.wildcard || seg.filter != nil:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if seg.filter == nil || seg.filter.match(v[k]) {
+					add(k, v[k])
+				}
+			}
+		default:
+			for _, name := range seg.names {
+				if val, ok := v[name]; ok {
+					add(name, val)
+				}
+			}
+		}
+	case []any:
+		switch {
+		case seg.wildcard || seg.filter != nil:
+			for i, val := range v {
+				if seg.filter == nil || seg.filter.match(val) {
+					add(i, val)
+				}
+			}
+		default:
+			for _, idx := range seg.indexes {
+				if idx < 0 {
+					idx += len(v)
+				}
+				if idx >= 0 && idx < len(v) {
+					add(idx, v[idx])
+				}
+			}
+		}
+	}
+	return out
+}
+
+// jsonPathDescendants returns a location and all of its descendants.
+func jsonPathDescendants(loc jsonPathLocation) []jsonPathLocation {
+	out := []jsonPathLocation{loc}
+	for _, child := range (jsonPathSegment{wildcard: true}).children(loc) {
+		out = append(out, jsonPathDescendants(child)...)
+	}
+	return out
+}
+
+func (f *jsonPathFilter) match(val any) bool {
+	for _, and := range f.or {
+		ok := true
+		for _, cmp := range and {
+			if cmp.match(val) == cmp.not {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (cmp jsonPathComparison) match(val any) bool {
+	for _, key := range cmp.path {
+		m, ok := val.(map[string]any)
+		if !ok {
+			return false
+		}
+		if val, ok = m[key]; !ok {
+			return false
+		}
+	}
+	if cmp.op == "" {
+		return true
+	}
+	switch cmp.op {
+	case "==":
+		return jsonPathEqual(val, cmp.val)
+	case "!=":
+		return !jsonPathEqual(val, cmp.val)
+	}
+	if a, ok := val.(float64); ok {
+		if b, ok := cmp.val.(float64); ok {
+			return jsonPathCompare(cmp.op, a < b, a == b)
+		}
+	}
+	if a, ok := val.(string); ok {
+		if b, ok := cmp.val.(string); ok {
+			return jsonPathCompare(cmp.op, a < b, a == b)
+		}
+	}
+	return false
+}
+
+func jsonPathCompare(op string, less, equal bool) bool {
+	switch op {
+	case "<":
+		return less
+	case "<=":
+		return less || equal
+	case ">":
+		return !less && !equal
+	case ">=":
+		return !less
+	}
+	return false
+}
+
+func jsonPathEqual(a, b any) bool {
+	if ai, ok := a.(int); ok {
+		a = float64(ai)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+type jsonPathParser struct {
+	expr string
+	pos  int
+}
+
+func (p *jsonPathParser) done() bool { return p.pos >= len(p.expr) }
+
+func (p *jsonPathParser) peek(s string) bool { return strings.HasPrefix(p.expr[p.pos:], s) }
+
+func (p *jsonPathParser) skipSpace() {
+	for !p.done() && p.expr[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+var rxJSONPathName = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$-]*`)
+
+func (p *jsonPathParser) segment() (jsonPathSegment, error) {
+	seg := jsonPathSegment{}
+	switch {
+	case p.peek(".."):
+		seg.recursive = true
+		p.pos += 2
+		if p.peek("[") {
+			return p.bracket(seg)
+		}
+		return p.dotName(seg)
+	case p.peek("."):
+		p.pos++
+		return p.dotName(seg)
+	case p.peek("["):
+		return p.bracket(seg)
+	}
+	return seg, fmt.Errorf("unexpected character at %d", p.pos)
+}
+
+func (p *jsonPathParser) dotName(seg jsonPathSegment) (jsonPathSegment, error) {
+	if p.peek("*") {
+		p.pos++
+		seg.wildcard = true
+		return seg, nil
+	}
+	name := rxJSONPathName.FindString(p.expr[p.pos:])
+	if name == "" {
+		return seg, fmt.Errorf("missing name at %d", p.pos)
+	}
+	p.pos += len(name)
+	seg.names = []string{name}
+	return seg, nil
+}
+
+func (p *jsonPathParser) bracket(seg jsonPathSegment) (jsonPathSegment, error) {
+	p.pos++ // [
+	p.skipSpace()
+	switch {
+	case p.peek("*"):
+		p.pos++
+		seg.wildcard = true
+	case p.peek("?"):
+		p.pos++
+		p.skipSpace()
+		filter, err := p.filter()
+		if err != nil {
+			return seg, err
+		}
+		seg.filter = filter
+	default:
+		for {
+			p.skipSpace()
+			if p.peek("'") || p.peek(`"`) {
+				s, err := p.quoted()
+				if err != nil {
+					return seg, err
+				}
+				seg.names = append(seg.names, s)
+			} else {
+				end := p.pos
+				for end < len(p.expr) && strings.ContainsRune("-0123456789", rune(p.expr[end])) {
+					end++
+				}
+				idx, err := strconv.Atoi(p.expr[p.pos:end])
+				if err != nil {
+					return seg, fmt.Errorf("invalid index at %d", p.pos)
+				}
+				p.pos = end
+				seg.indexes = append(seg.indexes, idx)
+			}
+			p.skipSpace()
+			if !p.peek(",") {
+				break
+			}
+			p.pos++
+		}
+	}
+	p.skipSpace()
+	if !p.peek("]") {
+		return seg, fmt.Errorf("missing `]` at %d", p.pos)
+	}
+	p.pos++
+	return seg, nil
+}
+
+func (p *jsonPathParser) quoted() (string, error) {
+	quote := p.expr[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for !p.done() {
+		c := p.expr[p.pos]
+		p.pos++
+		switch {
+		case c == '\\' && !p.done():
+			sb.WriteByte(p.expr[p.pos])
+			p.pos++
+		case c == quote:
+			return sb.String(), nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// filter parses `(expr)` or `expr` up to the closing `]`.
+func (p *jsonPathParser) filter() (*jsonPathFilter, error) {
+	parens := p.peek("(")
+	if parens {
+		p.pos++
+	}
+	f := &jsonPathFilter{}
+	and := []jsonPathComparison{}
+	for {
+		p.skipSpace()
+		cmp, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, cmp)
+		p.skipSpace()
+		if p.peek("&&") {
+			p.pos += 2
+			continue
+		}
+		f.or = append(f.or, and)
+		if p.peek("||") {
+			p.pos += 2
+			and = []jsonPathComparison{}
+			continue
+		}
+		break
+	}
+	if parens {
+		if !p.peek(")") {
+			return nil, fmt.Errorf("missing `)` at %d", p.pos)
+		}
+		p.pos++
+	}
+	return f, nil
+}
+
+func (p *jsonPathParser) comparison() (jsonPathComparison, error) {
+	cmp := jsonPathComparison{}
+	if p.peek("!") && !p.peek("!=") {
+		cmp.not = true
+		p.pos++
+	}
+	if !p.peek("@") {
+		return cmp, fmt.Errorf("filter must start with `@` at %d", p.pos)
+	}
+	p.pos++
+	for p.peek(".") || p.peek("[") {
+		seg, err := p.segment()
+		if err != nil {
+			return cmp, err
+		} else if len(seg.names) != 1 || seg.recursive {
+			return cmp, fmt.Errorf("unsupported filter path at %d", p.pos)
+		}
+		cmp.path = append(cmp.path, seg.names[0])
+	}
+	p.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.peek(op) {
+			cmp.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if cmp.op == "" {
+		return cmp, nil
+	}
+	p.skipSpace()
+	switch {
+	case p.peek("'") || p.peek(`"`):
+		s, err := p.quoted()
+		if err != nil {
+			return cmp, err
+		}
+		cmp.val = s
+	case p.peek("true"):
+		cmp.val, p.pos = true, p.pos+4
+	case p.peek("false"):
+		cmp.val, p.pos = false, p.pos+5
+	case p.peek("null"):
+		cmp.val, p.pos = nil, p.pos+4
+	default:
+		end := p.pos
+		for end < len(p.expr) && strings.ContainsRune("-+.eE0123456789", rune(p.expr[end])) {
+			end++
+		}
+		num, err := strconv.ParseFloat(p.expr[p.pos:end], 64)
+		if err != nil {
+			return cmp, fmt.Errorf("invalid filter value at %d", p.pos)
+		}
+		cmp.val, p.pos = num, end
+	}
+	return cmp, nil
+}
+
+var rxJSONPathShorthand = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonPathString returns a normalized JSONPath for a location path.
+func jsonPathString(path []any) string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, key := range path {
+		switch k := key.(type) {
+		case int:
+			sb.WriteString("[" + strconv.Itoa(k) + "]")
+		case string:
+			if rxJSONPathShorthand.MatchString(k) {
+				sb.WriteString("." + k)
+			} else {
+				sb.WriteString("['" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(k) + "']")
+			}
+		}
+	}
+	return sb.String()
+}
@@ -6,6 +6,7 @@ import oas3 "github.com/getkin/kin-openapi/openapi3"
 // an empty set `{}` to satisfy OpenAPI Generator which will
 // fail on the following error "-attribute paths is not of type `object`"
 func (se *SpecEdit) PathsNullToEmpty() {
+	defer se.journalEdit("PathsNullToEmpty")()
 	if se.SpecMore.Spec != nil && se.SpecMore.Spec.Paths == nil {
 		se.SpecMore.Spec.Paths = oas3.NewPaths()
 		// se.SpecMore.Spec.Paths = map[string]*oas3.PathItem{} // getkin v0.121.0 to v0.122.0
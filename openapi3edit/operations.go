@@ -14,6 +14,7 @@ import (
 
 // SetOperation sets an operation in a OpenAPI Specification.
 func (se *SpecEdit) SetOperation(path, method string, op oas3.Operation) error {
+	defer se.journalEdit("SetOperation")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
@@ -63,6 +64,7 @@ func (se *SpecEdit) SetOperation(path, method string, op oas3.Operation) error {
 }
 
 func (se *SpecEdit) OperationIDsFromSummaries(errorOnEmpty bool) error {
+	defer se.journalEdit("OperationIDsFromSummaries")()
 	if se.SpecMore.Spec == nil {
 		return nil
 	}
@@ -85,6 +87,7 @@ func (se *SpecEdit) OperationIDsFromSummaries(errorOnEmpty bool) error {
 // where the keys are pathMethod values and the values are Summary strings.
 // This currently converts a Summary into an OperationID by using the supplied `opIDFunc`.
 func (se *SpecEdit) OperationsOperationIDSummaryReplace(customMapPathMethodToSummary map[string]string, opIDFunc func(s string) string, forceOpID, forceSummary bool) {
+	defer se.journalEdit("OperationsOperationIDSummaryReplace")()
 	if se.SpecMore.Spec == nil {
 		return
 	}
@@ -111,6 +114,7 @@ func (se *SpecEdit) OperationsOperationIDSummaryReplace(customMapPathMethodToSum
 }
 
 func (se *SpecEdit) AddCustomProperties(custom map[string]interface{}, addToOperations, addToSchemas bool) {
+	defer se.journalEdit("AddCustomProperties")()
 	if se.SpecMore.Spec == nil || len(custom) == 0 {
 		return
 	}
@@ -134,6 +138,7 @@ func (se *SpecEdit) AddCustomProperties(custom map[string]interface{}, addToOper
 }
 
 func (se *SpecEdit) AddOperationMetas(metas map[string]openapi3.OperationMeta, overwrite bool) {
+	defer se.journalEdit("AddOperationMetas")()
 	if se.SpecMore.Spec == nil || len(metas) == 0 {
 		return
 	}
@@ -191,6 +196,7 @@ func (se *SpecEdit) AddOperationMetas(metas map[string]openapi3.OperationMeta, o
 // include and exclude filters. SecurityRequirement is specified by OpenAPI/Swagger standard version 3.
 // See https://github.com/OAI/OpenAPI-Specification/blob/main/versions/3.0.3.md#securityRequirementObject
 func (se *SpecEdit) OperationsSecurityReplace(pathMethodsInclude, pathMethodsExclude []string, securityRequirement map[string][]string) {
+	defer se.journalEdit("OperationsSecurityReplace")()
 	if se.SpecMore.Spec == nil {
 		return
 	}
@@ -223,6 +229,7 @@ const (
 )
 
 func (se *SpecEdit) OperationsRequestBodyFlattenSchemas(schemaKeySuffix string) error {
+	defer se.journalEdit("OperationsRequestBodyFlattenSchemas")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
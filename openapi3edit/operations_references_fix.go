@@ -17,6 +17,7 @@ const (
 var rxOAS2RefDefinition = regexp.MustCompile(`^#/definitions/(.*)`)
 
 func (se *SpecEdit) OperationsFixResponseReferences() []*openapi3.OperationMeta {
+	defer se.journalEdit("OperationsFixResponseReferences")()
 	errorOperations := []*openapi3.OperationMeta{}
 	if se.SpecMore.Spec == nil {
 		return errorOperations
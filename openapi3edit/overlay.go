@@ -0,0 +1,316 @@
+package openapi3edit
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/grokify/spectrum/openapi3"
+	"sigs.k8s.io/yaml"
+)
+
+// OverlayVersion is the supported OpenAPI Overlay specification version.
+const OverlayVersion = "1.0.0"
+
+// Overlay is an OpenAPI Overlay document, a list of actions which update or
+// remove parts of a spec selected by JSONPath. See
+// https://spec.openapis.org/overlay/v1.0.0.html.
+type Overlay struct {
+	Overlay string          `json:"overlay"`
+	Info    OverlayInfo     `json:"info"`
+	Extends string          `json:"extends,omitempty"`
+	Actions []OverlayAction `json:"actions"`
+}
+
+type OverlayInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OverlayAction updates or removes the values selected by `Target`. An
+// `Update` object is merged into each selected object, with nested objects
+// merged and other values replaced, and appended to each selected array.
+type OverlayAction struct {
+	Target      string `json:"target"`
+	Description string `json:"description,omitempty"`
+	Update      any    `json:"update,omitempty"`
+	Remove      bool   `json:"remove,omitempty"`
+}
+
+// ParseOverlay parses a JSON or YAML overlay document.
+func ParseOverlay(data []byte) (*Overlay, error) {
+	bytes, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	ov := &Overlay{}
+	if err := json.Unmarshal(bytes, ov); err != nil {
+		return nil, err
+	} else if ov.Overlay == "" {
+		return nil, errors.New("overlay version not set")
+	}
+	return ov, nil
+}
+
+// ReadOverlayFile reads a JSON or YAML overlay file.
+func ReadOverlayFile(filename string) (*Overlay, error) {
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOverlay(bytes)
+}
+
+// MarshalYAML returns the overlay as YAML.
+func (ov *Overlay) MarshalYAML() ([]byte, error) {
+	bytes, err := json.Marshal(ov)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(bytes)
+}
+
+// ApplyOverlay applies the actions of an overlay to the spec in order.
+// Targets which match nothing are ignored.
+func (se *SpecEdit) ApplyOverlay(ov *Overlay) error {
+	defer se.journalEdit("ApplyOverlay")()
+	if se.SpecMore.Spec == nil {
+		return openapi3.ErrSpecNotSet
+	} else if ov == nil {
+		return nil
+	}
+	root, err := specToJSONValue(se.SpecMore.Spec)
+	if err != nil {
+		return err
+	}
+	for _, action := range ov.Actions {
+		jp, err := parseJSONPath(action.Target)
+		if err != nil {
+			return err
+		}
+		locs := jp.Select(root)
+		if action.Remove {
+			root = jsonRemoveLocations(root, locs)
+			continue
+		} else if action.Update == nil {
+			continue
+		}
+		for _, loc := range locs {
+			root = jsonSetPath(root, loc.path, overlayUpdate(loc.value, jsonValueCopy(action.Update)))
+		}
+	}
+	return se.specSetJSONValue(root)
+}
+
+// OverlayDiff returns an overlay which transforms `spec1` into `spec2`.
+// Objects are compared recursively, removed properties become `remove`
+// actions and added or changed properties become `update` actions on the
+// parent object. Changed arrays are replaced as a whole.
+func OverlayDiff(spec1, spec2 *openapi3.Spec) (*Overlay, error) {
+	val1, err := specToJSONValue(spec1)
+	if err != nil {
+		return nil, err
+	}
+	val2, err := specToJSONValue(spec2)
+	if err != nil {
+		return nil, err
+	}
+	ov := &Overlay{Overlay: OverlayVersion, Actions: []OverlayAction{}}
+	if spec2 != nil && spec2.Info != nil {
+		ov.Info = OverlayInfo{Title: spec2.Info.Title, Version: spec2.Info.Version}
+	}
+	overlayDiffWalk(ov, []any{}, val1, val2)
+	return ov, nil
+}
+
+func overlayDiffWalk(ov *Overlay, path []any, val1, val2 any) {
+	map1, ok1 := val1.(map[string]any)
+	map2, ok2 := val2.(map[string]any)
+	if !ok1 || !ok2 {
+		return
+	}
+	keys := make([]string, 0, len(map1)+len(map2))
+	for k := range map1 {
+		keys = append(keys, k)
+	}
+	for k := range map2 {
+		if _, ok := map1[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	update := map[string]any{}
+	for _, k := range keys {
+		v1, in1 := map1[k]
+		v2, in2 := map2[k]
+		childPath := append(append([]any{}, path...), k)
+		switch {
+		case !in2:
+			ov.Actions = append(ov.Actions, OverlayAction{Target: jsonPathString(childPath), Remove: true})
+		case !in1:
+			update[k] = v2
+		case reflect.DeepEqual(v1, v2):
+		default:
+			_, isMap1 := v1.(map[string]any)
+			_, isMap2 := v2.(map[string]any)
+			if isMap1 && isMap2 {
+				overlayDiffWalk(ov, childPath, v1, v2)
+			} else {
+				update[k] = v2
+			}
+		}
+	}
+	if len(update) > 0 {
+		ov.Actions = append(ov.Actions, OverlayAction{Target: jsonPathString(path), Update: update})
+	}
+}
+
+// overlayUpdate applies an update value to a target value.
+func overlayUpdate(target, update any) any {
+	switch t := target.(type) {
+	case map[string]any:
+		u, ok := update.(map[string]any)
+		if !ok {
+			return update
+		}
+		for k, uv := range u {
+			if tv, ok := t[k]; ok {
+				if _, isMap := tv.(map[string]any); isMap {
+					t[k] = overlayUpdate(tv, uv)
+					continue
+				}
+			}
+			t[k] = uv
+		}
+		return t
+	case []any:
+		if u, ok := update.([]any); ok {
+			return append(t, u...)
+		}
+		return append(t, update)
+	}
+	return update
+}
+
+func specToJSONValue(spec *openapi3.Spec) (any, error) {
+	if spec == nil {
+		return nil, openapi3.ErrSpecNotSet
+	}
+	bytes, err := spec.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var val any
+	return val, json.Unmarshal(bytes, &val)
+}
+
+// specSetJSONValue replaces the contents of the spec with a decoded JSON
+// value, keeping the spec pointer.
+func (se *SpecEdit) specSetJSONValue(val any) error {
+	bytes, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	spec, err := openapi3.Parse(bytes)
+	if err != nil {
+		return err
+	}
+	*se.SpecMore.Spec = *spec
+	return nil
+}
+
+func jsonValueCopy(val any) any {
+	switch v := val.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = jsonValueCopy(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = jsonValueCopy(item)
+		}
+		return out
+	}
+	return val
+}
+
+// jsonSetPath sets the value at a path of map keys and array indexes and
+// returns the root, which is replaced for an empty path.
+func jsonSetPath(root any, path []any, val any) any {
+	if len(path) == 0 {
+		return val
+	}
+	switch c := root.(type) {
+	case map[string]any:
+		if k, ok := path[0].(string); ok {
+			c[k] = jsonSetPath(c[k], path[1:], val)
+		}
+	case []any:
+		if i, ok := path[0].(int); ok && i < len(c) {
+			c[i] = jsonSetPath(c[i], path[1:], val)
+		}
+	}
+	return root
+}
+
+// jsonRemoveLocations removes locations from the root. Locations are removed
+// in reverse order so children are removed before parents and array indexes
+// remain valid.
+func jsonRemoveLocations(root any, locs []jsonPathLocation) any {
+	sort.Slice(locs, func(i, j int) bool {
+		return jsonPathLess(locs[j].path, locs[i].path)
+	})
+	for _, loc := range locs {
+		if len(loc.path) == 0 {
+			continue
+		}
+		root = jsonRemovePath(root, loc.path)
+	}
+	return root
+}
+
+func jsonRemovePath(root any, path []any) any {
+	switch c := root.(type) {
+	case map[string]any:
+		k, ok := path[0].(string)
+		if !ok {
+			return root
+		} else if len(path) == 1 {
+			delete(c, k)
+		} else if child, ok := c[k]; ok {
+			c[k] = jsonRemovePath(child, path[1:])
+		}
+	case []any:
+		i, ok := path[0].(int)
+		if !ok || i >= len(c) {
+			return root
+		} else if len(path) == 1 {
+			return append(c[:i], c[i+1:]...)
+		}
+		c[i] = jsonRemovePath(c[i], path[1:])
+	}
+	return root
+}
+
+// jsonPathLess orders location paths, with array indexes compared
+// numerically and prefixes first.
+func jsonPathLess(a, b []any) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		switch ai := a[i].(type) {
+		case int:
+			if bi, ok := b[i].(int); ok && ai != bi {
+				return ai < bi
+			}
+		case string:
+			if bi, ok := b[i].(string); ok && ai != bi {
+				return ai < bi
+			}
+		}
+	}
+	return len(a) < len(b)
+}
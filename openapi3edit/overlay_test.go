@@ -0,0 +1,166 @@
+package openapi3edit
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const overlayTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "tags": [{"name": "pets"}],
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer"}},
+          {"name": "x-internal-trace", "in": "header", "schema": {"type": "string"}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      },
+      "delete": {
+        "operationId": "deletePets",
+        "x-internal": true,
+        "responses": {"204": {"description": "Deleted"}}
+      }
+    }
+  }
+}`
+
+const overlayTestOverlay = `
+overlay: 1.0.0
+info:
+  title: Public
+  version: 1.0.0
+actions:
+  - target: $.info
+    update:
+      description: Public pets API
+  - target: $.paths.*[?(@.x-internal == true)]
+    remove: true
+  - target: $..parameters[?(@.in == 'header' && @.name == 'x-internal-trace')]
+    remove: true
+  - target: $.tags
+    update:
+      name: stores
+  - target: $.paths['/pets'].get.responses
+    update:
+      "200":
+        description: Pets
+`
+
+func TestApplyOverlay(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(overlayTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	ov, err := ParseOverlay([]byte(overlayTestOverlay))
+	if err != nil {
+		t.Fatalf("openapi3edit.ParseOverlay() error [%v]", err)
+	}
+	se := NewSpecEdit(spec)
+	if err := se.ApplyOverlay(ov); err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.ApplyOverlay() error [%v]", err)
+	}
+	spec = se.SpecMore.Spec
+	if spec.Info.Description != "Public pets API" {
+		t.Errorf("openapi3edit.SpecEdit.ApplyOverlay() info mismatch: want [Public pets API], got [%s]", spec.Info.Description)
+	}
+	pathItem := spec.Paths.Value("/pets")
+	if pathItem.Delete != nil {
+		t.Errorf("openapi3edit.SpecEdit.ApplyOverlay() filter remove: want [nil] delete operation")
+	}
+	if len(pathItem.Get.Parameters) != 1 || pathItem.Get.Parameters[0].Value.Name != "limit" {
+		t.Errorf("openapi3edit.SpecEdit.ApplyOverlay() parameters mismatch: want [limit], got [%d]", len(pathItem.Get.Parameters))
+	}
+	if len(spec.Tags) != 2 || spec.Tags[1].Name != "stores" {
+		t.Errorf("openapi3edit.SpecEdit.ApplyOverlay() array append mismatch: want [2], got [%d]", len(spec.Tags))
+	}
+	if desc := pathItem.Get.Responses.Value("200").Value.Description; desc == nil || *desc != "Pets" {
+		t.Errorf("openapi3edit.SpecEdit.ApplyOverlay() nested update mismatch: want [Pets]")
+	}
+}
+
+func TestOverlayDiff(t *testing.T) {
+	spec1, err := openapi3.Parse([]byte(overlayTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	spec2, err := openapi3.Parse([]byte(overlayTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	ov, err := ParseOverlay([]byte(overlayTestOverlay))
+	if err != nil {
+		t.Fatalf("openapi3edit.ParseOverlay() error [%v]", err)
+	}
+	se2 := NewSpecEdit(spec2)
+	if err := se2.ApplyOverlay(ov); err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.ApplyOverlay() error [%v]", err)
+	}
+	diff, err := OverlayDiff(spec1, spec2)
+	if err != nil {
+		t.Fatalf("openapi3edit.OverlayDiff() error [%v]", err)
+	}
+	// Round trip through YAML.
+	bytes, err := diff.MarshalYAML()
+	if err != nil {
+		t.Fatalf("openapi3edit.Overlay.MarshalYAML() error [%v]", err)
+	}
+	if diff, err = ParseOverlay(bytes); err != nil {
+		t.Fatalf("openapi3edit.ParseOverlay() error [%v]", err)
+	}
+	se1 := NewSpecEdit(spec1)
+	if err := se1.ApplyOverlay(diff); err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.ApplyOverlay() error [%v]", err)
+	}
+	got, err := specToJSONValue(spec1)
+	if err != nil {
+		t.Fatalf("specToJSONValue() error [%v]", err)
+	}
+	want, err := specToJSONValue(spec2)
+	if err != nil {
+		t.Fatalf("specToJSONValue() error [%v]", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("openapi3edit.OverlayDiff() round trip mismatch: overlay [%s]", string(bytes))
+	}
+}
+
+func TestJSONPathSelect(t *testing.T) {
+	doc := `{"a": {"b": [{"n": 1, "s": "x"}, {"n": 2, "s": "y"}], "c": {"b": 3}}, "d-e": true}`
+	var root any
+	if err := json.Unmarshal([]byte(doc), &root); err != nil {
+		t.Fatalf("json.Unmarshal() error [%v]", err)
+	}
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"$.a.b[0].n", []string{"$.a.b[0].n"}},
+		{"$['d-e']", []string{"$['d-e']"}},
+		{"$.a.b[-1]", []string{"$.a.b[1]"}},
+		{"$.a.b[*].s", []string{"$.a.b[0].s", "$.a.b[1].s"}},
+		{"$..b", []string{"$.a.b", "$.a.c.b"}},
+		{"$.a.b[?(@.n > 1)]", []string{"$.a.b[1]"}},
+		{"$.a.b[?@.s == 'x' || @.s == 'y']", []string{"$.a.b[0]", "$.a.b[1]"}},
+		{"$.a.*[?(!@.s)]", []string{"$.a.c.b"}},
+	}
+	for _, tt := range tests {
+		jp, err := parseJSONPath(tt.expr)
+		if err != nil {
+			t.Fatalf("parseJSONPath() error [%v]", err)
+		}
+		got := []string{}
+		for _, loc := range jp.Select(root) {
+			got = append(got, jsonPathString(loc.path))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("jsonPath.Select() mismatch: expr [%s] want [%v], got [%v]", tt.expr, tt.want, got)
+		}
+	}
+}
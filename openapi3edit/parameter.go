@@ -132,6 +132,7 @@ func (se *SpecEdit) paramPathNamesModifyPaths(xf func(s string) string) error {
 
 // ParamPathNamesModify should result in a spec that validates and performs post-modification validation.
 func (se *SpecEdit) ParamPathNamesModify(xf func(string) string) (map[string]string, error) {
+	defer se.journalEdit("ParamPathNamesModify")()
 	// Operations must come before components.
 	xfMap, err := se.paramPathNamesModifyOperations(xf)
 	if err != nil {
@@ -56,6 +56,7 @@ type SpecPathsModifyOpts struct {
 }
 
 func (se *SpecEdit) PathsModify(opts SpecPathsModifyOpts) error {
+	defer se.journalEdit("PathsModify")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
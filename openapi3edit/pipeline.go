@@ -0,0 +1,292 @@
+package openapi3edit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/grokify/spectrum/openapi3"
+	"sigs.k8s.io/yaml"
+)
+
+// Pipeline is a declarative list of edit steps, read from YAML or JSON:
+//
+//	steps:
+//	  - step: tagsModify
+//	    params:
+//	      tags: {"Pet": "Pets"}
+//	  - step: deleteOperations
+//	    params:
+//	      extensions: {"x-internal": true}
+type Pipeline struct {
+	Steps []PipelineStep `json:"steps"`
+	// Dir is the directory relative file parameters are resolved against. It
+	// is set by `ReadPipelineFile()`.
+	Dir string `json:"-"`
+}
+
+type PipelineStep struct {
+	Step   string         `json:"step"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// PipelineParams are the parameters of a pipeline step.
+type PipelineParams struct {
+	Values map[string]any
+	Dir    string
+}
+
+// Decode decodes the parameters into a struct using its `json` tags.
+// Unknown parameters are an error.
+func (pp PipelineParams) Decode(v any) error {
+	b, err := json.Marshal(pp.Values)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// Path returns a file path resolved against the pipeline directory.
+func (pp PipelineParams) Path(filename string) string {
+	if pp.Dir == "" || filepath.IsAbs(filename) {
+		return filename
+	}
+	return filepath.Join(pp.Dir, filename)
+}
+
+// PipelineStepFunc runs a pipeline step.
+type PipelineStepFunc func(se *SpecEdit, params PipelineParams) error
+
+var pipelineSteps = map[string]PipelineStepFunc{
+	"deleteOperations":             pipelineDeleteOperations,
+	"dereference":                  pipelineDereference,
+	"operationIdsFromSummaries":    pipelineOperationIDsFromSummaries,
+	"overlay":                      pipelineOverlay,
+	"pathsModify":                  pipelinePathsModify,
+	"pruneUnusedComponents":        pipelinePruneUnusedComponents,
+	"publish":                      pipelinePublish,
+	"schemaPropertiesSetOptional":  pipelineSchemaPropertiesSetOptional,
+	"schemasDedupe":                pipelineSchemasDedupe,
+	"schemasFlatten":               pipelineSchemasFlatten,
+	"securitySchemeAddBearertoken": pipelineSecuritySchemeAddBearertoken,
+	"tagsModify":                   pipelineTagsModify,
+}
+
+// RegisterPipelineStep adds or replaces a pipeline step.
+func RegisterPipelineStep(name string, fn PipelineStepFunc) {
+	pipelineSteps[name] = fn
+}
+
+// PipelineStepNames returns the sorted names of the registered steps.
+func PipelineStepNames() []string {
+	var names []string
+	for name := range pipelineSteps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParsePipeline parses a YAML or JSON pipeline and checks that all steps
+// are registered.
+func ParsePipeline(data []byte) (*Pipeline, error) {
+	b, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pipeline{}
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, err
+	}
+	for i, step := range p.Steps {
+		if _, ok := pipelineSteps[step.Step]; !ok {
+			return nil, fmt.Errorf("pipeline step %d: unknown step [%s]", i, step.Step)
+		}
+	}
+	return p, nil
+}
+
+// ReadPipelineFile reads a YAML or JSON pipeline file.
+func ReadPipelineFile(filename string) (*Pipeline, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	p, err := ParsePipeline(data)
+	if err != nil {
+		return nil, err
+	}
+	p.Dir = filepath.Dir(filename)
+	return p, nil
+}
+
+// RunPipeline runs the steps of a pipeline in order, stopping at the first
+// error. With a journal, each step is recorded with its step name.
+func (se *SpecEdit) RunPipeline(p *Pipeline) error {
+	if se.SpecMore.Spec == nil {
+		return openapi3.ErrSpecNotSet
+	} else if p == nil {
+		return nil
+	}
+	for i, step := range p.Steps {
+		fn, ok := pipelineSteps[step.Step]
+		if !ok {
+			return fmt.Errorf("pipeline step %d: unknown step [%s]", i, step.Step)
+		}
+		err := se.Edit(step.Step, func(se *SpecEdit) error {
+			return fn(se, PipelineParams{Values: step.Params, Dir: p.Dir})
+		})
+		if err != nil {
+			return fmt.Errorf("pipeline step %d [%s]: %w", i, step.Step, err)
+		}
+	}
+	return nil
+}
+
+func pipelineDeleteOperations(se *SpecEdit, params PipelineParams) error {
+	filter := openapi3.OperationFilter{}
+	if err := params.Decode(&filter); err != nil {
+		return err
+	} else if filter.IsEmpty() {
+		// An empty filter matches every operation.
+		return errors.New("no operation filter criterion set")
+	}
+	fn, err := filter.Func()
+	if err != nil {
+		return err
+	}
+	se.DeleteOperations(fn)
+	return nil
+}
+
+func pipelineDereference(se *SpecEdit, params PipelineParams) error {
+	opts := DereferenceOptions{}
+	if err := params.Decode(&opts); err != nil {
+		return err
+	}
+	_, err := se.Dereference(&opts)
+	return err
+}
+
+func pipelineOperationIDsFromSummaries(se *SpecEdit, params PipelineParams) error {
+	opts := struct {
+		ErrorOnEmpty bool `json:"errorOnEmpty"`
+	}{}
+	if err := params.Decode(&opts); err != nil {
+		return err
+	}
+	return se.OperationIDsFromSummaries(opts.ErrorOnEmpty)
+}
+
+func pipelineOverlay(se *SpecEdit, params PipelineParams) error {
+	opts := struct {
+		File string `json:"file"`
+	}{}
+	if err := params.Decode(&opts); err != nil {
+		return err
+	}
+	ov, err := ReadOverlayFile(params.Path(opts.File))
+	if err != nil {
+		return err
+	}
+	return se.ApplyOverlay(ov)
+}
+
+func pipelinePathsModify(se *SpecEdit, params PipelineParams) error {
+	opts := struct {
+		ServerPath string `json:"serverPath"`
+		PathBase   string `json:"pathBase"`
+	}{}
+	if err := params.Decode(&opts); err != nil {
+		return err
+	}
+	return se.PathsModify(SpecPathsModifyOpts{
+		ServerPathExec:          opts.ServerPath != "",
+		ServerPathNew:           opts.ServerPath,
+		OpPathRenameNewBaseExec: opts.PathBase != "",
+		OpPathRenameNewBase:     opts.PathBase})
+}
+
+func pipelinePruneUnusedComponents(se *SpecEdit, params PipelineParams) error {
+	if err := params.Decode(&struct{}{}); err != nil {
+		return err
+	}
+	_, err := se.PruneUnusedComponents(false)
+	return err
+}
+
+func pipelinePublish(se *SpecEdit, params PipelineParams) error {
+	opts := PublishOptions{}
+	if err := params.Decode(&opts); err != nil {
+		return err
+	}
+	_, err := se.Publish(opts)
+	return err
+}
+
+func pipelineSchemaPropertiesSetOptional(se *SpecEdit, params PipelineParams) error {
+	opts := struct {
+		Pattern string `json:"pattern"`
+	}{}
+	if err := params.Decode(&opts); err != nil {
+		return err
+	}
+	rx, err := regexp.Compile(opts.Pattern)
+	if err != nil {
+		return err
+	}
+	return se.SchemaPropertiesSetOptional(rx)
+}
+
+func pipelineSchemasDedupe(se *SpecEdit, params PipelineParams) error {
+	opts := struct {
+		IgnoreDocs bool     `json:"ignoreDocs"`
+		Preferred  []string `json:"preferred"`
+	}{}
+	if err := params.Decode(&opts); err != nil {
+		return err
+	}
+	_, err := se.SchemasDedupe(&SchemasDedupeOptions{
+		IgnoreDocs:    opts.IgnoreDocs,
+		CanonicalName: CanonicalNamePreferred(opts.Preferred, nil)})
+	return err
+}
+
+func pipelineSchemasFlatten(se *SpecEdit, params PipelineParams) error {
+	if err := params.Decode(&struct{}{}); err != nil {
+		return err
+	}
+	se.SchemasFlatten()
+	return nil
+}
+
+func pipelineSecuritySchemeAddBearertoken(se *SpecEdit, params PipelineParams) error {
+	opts := struct {
+		SchemeName   string   `json:"schemeName"`
+		BearerFormat string   `json:"bearerFormat"`
+		IncludeTags  []string `json:"includeTags"`
+		SkipTags     []string `json:"skipTags"`
+	}{}
+	if err := params.Decode(&opts); err != nil {
+		return err
+	}
+	return se.SecuritySchemeAddBearertoken(opts.SchemeName, opts.BearerFormat, opts.IncludeTags, opts.SkipTags)
+}
+
+func pipelineTagsModify(se *SpecEdit, params PipelineParams) error {
+	opts := struct {
+		Tags map[string]string `json:"tags"`
+	}{}
+	if err := params.Decode(&opts); err != nil {
+		return err
+	}
+	se.TagsModify(opts.Tags)
+	return nil
+}
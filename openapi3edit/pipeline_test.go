@@ -0,0 +1,88 @@
+package openapi3edit
+
+import (
+	"testing"
+
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const pipelineTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "tags": [{"name": "pet"}],
+  "paths": {
+    "/pets": {
+      "get": {"tags": ["pet"], "summary": "listPets", "responses": {"200": {"description": "OK"}}}
+    },
+    "/admin/pets/{petId}": {
+      "delete": {"tags": ["pet"], "summary": "purgePet", "x-internal": true, "responses": {"204": {"description": "Deleted"}}}
+    }
+  }
+}`
+
+const pipelineTestPipeline = `
+steps:
+  - step: deleteOperations
+    params:
+      pathGlob: /admin/**
+      extensions: {x-internal: true}
+  - step: tagsModify
+    params:
+      tags: {pet: Pets}
+  - step: operationIdsFromSummaries
+    params:
+      errorOnEmpty: true
+  - step: securitySchemeAddBearertoken
+    params:
+      schemeName: BearerAuth
+`
+
+func TestRunPipeline(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(pipelineTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	p, err := ParsePipeline([]byte(pipelineTestPipeline))
+	if err != nil {
+		t.Fatalf("openapi3edit.ParsePipeline() error [%v]", err)
+	}
+	se := NewSpecEdit(spec)
+	if err := se.RunPipeline(p); err != nil {
+		t.Fatalf("openapi3edit.SpecEdit.RunPipeline() error [%v]", err)
+	}
+	if spec.Paths.Value("/admin/pets/{petId}") != nil && spec.Paths.Value("/admin/pets/{petId}").Delete != nil {
+		t.Errorf("openapi3edit.SpecEdit.RunPipeline() deleteOperations: want [nil] operation")
+	}
+	op := spec.Paths.Value("/pets").Get
+	if op.OperationID != "listPets" || len(op.Tags) != 1 || op.Tags[0] != "Pets" {
+		t.Errorf("openapi3edit.SpecEdit.RunPipeline() operation mismatch: want [listPets Pets], got [%s %v]", op.OperationID, op.Tags)
+	}
+	if _, ok := spec.Components.SecuritySchemes["BearerAuth"]; !ok {
+		t.Errorf("openapi3edit.SpecEdit.RunPipeline() securitySchemeAddBearertoken: want [BearerAuth] scheme")
+	}
+}
+
+func TestParsePipelineErrors(t *testing.T) {
+	tests := []struct {
+		pipeline string
+	}{
+		{"steps:\n  - step: unknownStep\n"},
+		{"steps:\n  - step: tagsModify\n    params:\n      tagz: {a: b}\n"},
+		{"steps:\n  - step: deleteOperations\n"},
+		{"steps:\n  - step: deleteOperations\n    params: {}\n"},
+	}
+	for _, tt := range tests {
+		p, err := ParsePipeline([]byte(tt.pipeline))
+		if err == nil {
+			spec, err2 := openapi3.Parse([]byte(pipelineTestSpec))
+			if err2 != nil {
+				t.Fatalf("openapi3.Parse() error [%v]", err2)
+			}
+			se := NewSpecEdit(spec)
+			err = se.RunPipeline(p)
+		}
+		if err == nil {
+			t.Errorf("openapi3edit pipeline: want error for [%s]", tt.pipeline)
+		}
+	}
+}
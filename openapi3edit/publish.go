@@ -0,0 +1,466 @@
+package openapi3edit
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/grokify/gocharts/v2/data/table"
+	"github.com/grokify/mogo/encoding/jsonpointer"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	// XVisibility sets the audience of an operation, path item, parameter or
+	// schema: `internal`, `partner` or `public`.
+	XVisibility = "x-visibility"
+	// XLifecycle sets the stage of an operation, path item, parameter or
+	// schema: `alpha`, `beta` or `ga`.
+	XLifecycle = "x-lifecycle"
+	// XEnumVisibility and XEnumLifecycle are set on a schema with an `enum`
+	// and map enum values to their audience or stage.
+	XEnumVisibility = "x-enum-visibility"
+	XEnumLifecycle  = "x-enum-lifecycle"
+
+	VisibilityInternal = "internal"
+	VisibilityPartner  = "partner"
+	VisibilityPublic   = "public"
+
+	LifecycleAlpha = "alpha"
+	LifecycleBeta  = "beta"
+	LifecycleGA    = "ga"
+
+	PublishRemovedPath      = "path"
+	PublishRemovedOperation = "operation"
+	PublishRemovedParameter = "parameter"
+	PublishRemovedProperty  = "property"
+	PublishRemovedEnumValue = "enumValue"
+)
+
+// visibilityLevels and lifecycleLevels order the annotation values from
+// the narrowest to the widest audience.
+var (
+	visibilityLevels = map[string]int{VisibilityInternal: 0, VisibilityPartner: 1, VisibilityPublic: 2}
+	lifecycleLevels  = map[string]int{LifecycleAlpha: 0, LifecycleBeta: 1, LifecycleGA: 2}
+)
+
+// PublishOptions sets the target of `SpecEdit.Publish()`. An item is
+// visible when its `x-visibility` is at least as wide as `Audience` and its
+// `x-lifecycle` at least as mature as `Stage`, so a `partner` audience sees
+// `partner` and `public` items. Items without annotations are `public` and
+// `ga`.
+type PublishOptions struct {
+	// Audience is `internal`, `partner` or `public`, the default.
+	Audience string `json:"audience"`
+	// Stage is `alpha`, `beta` or `ga`, the default.
+	Stage string `json:"stage"`
+	// RemoveAnnotations removes the `x-visibility`, `x-lifecycle`,
+	// `x-enum-visibility` and `x-enum-lifecycle` extensions from the output.
+	RemoveAnnotations bool `json:"removeAnnotations"`
+}
+
+// PublishRemoval is an item removed by `SpecEdit.Publish()`. `Location` is
+// the JSON pointer of the object the item was removed from.
+type PublishRemoval struct {
+	Kind     string
+	Location string
+	Name     string
+}
+
+// PublishReport lists the items removed by `SpecEdit.Publish()` and the
+// components which were pruned because they were no longer used.
+type PublishReport struct {
+	Removed    []PublishRemoval
+	Components []openapi3.ComponentKey
+}
+
+func (r *PublishReport) Table() *table.Table {
+	tbl := table.NewTable("Removed")
+	tbl.Columns = []string{"Kind", "Location", "Name"}
+	for _, rm := range r.Removed {
+		tbl.Rows = append(tbl.Rows, []string{rm.Kind, rm.Location, rm.Name})
+	}
+	for _, key := range r.Components {
+		tbl.Rows = append(tbl.Rows, []string{key.Kind, key.Pointer(), key.Name})
+	}
+	return &tbl
+}
+
+// Publish removes everything which is not visible to the target audience
+// and stage: path items, operations, parameters, schema properties,
+// including properties whose array items are hidden, and enum values.
+// Removed properties are also removed from `required` lists. Components
+// which are no longer used are then pruned. The spec is unchanged if an
+// error is returned, such as for a hidden path parameter, an enum with no
+// visible values or a hidden component which is still referenced.
+func (se *SpecEdit) Publish(opts PublishOptions) (*PublishReport, error) {
+	defer se.journalEdit("Publish")()
+	if se.SpecMore.Spec == nil {
+		return nil, openapi3.ErrSpecNotSet
+	}
+	p, err := newPublisher(opts)
+	if err != nil {
+		return nil, err
+	}
+	if p.doc, err = specToJSONValue(se.SpecMore.Spec); err != nil {
+		return nil, err
+	}
+	p.paths()
+	p.walk(p.doc, []string{})
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	tmp := NewSpecEdit(&openapi3.Spec{})
+	if err := tmp.specSetJSONValue(p.doc); err != nil {
+		return nil, err
+	}
+	pruned, err := tmp.PruneUnusedComponents(false)
+	if err != nil {
+		return nil, err
+	}
+	p.report.Components = pruned.Removed
+	if p.doc, err = specToJSONValue(tmp.SpecMore.Spec); err != nil {
+		return nil, err
+	}
+	if err := p.checkComponents(); err != nil {
+		return nil, err
+	}
+	if opts.RemoveAnnotations {
+		publishRemoveAnnotations(p.doc)
+		if err := tmp.specSetJSONValue(p.doc); err != nil {
+			return nil, err
+		}
+	}
+	*se.SpecMore.Spec = *tmp.SpecMore.Spec
+	return p.report, nil
+}
+
+type publisher struct {
+	audience int
+	stage    int
+	doc      any
+	report   *PublishReport
+	err      error
+}
+
+func newPublisher(opts PublishOptions) (*publisher, error) {
+	p := &publisher{report: &PublishReport{Removed: []PublishRemoval{}}}
+	var ok bool
+	if opts.Audience == "" {
+		opts.Audience = VisibilityPublic
+	}
+	if p.audience, ok = visibilityLevels[opts.Audience]; !ok {
+		return nil, fmt.Errorf("invalid audience [%s]", opts.Audience)
+	}
+	if opts.Stage == "" {
+		opts.Stage = LifecycleGA
+	}
+	if p.stage, ok = lifecycleLevels[opts.Stage]; !ok {
+		return nil, fmt.Errorf("invalid stage [%s]", opts.Stage)
+	}
+	return p, nil
+}
+
+func (p *publisher) remove(kind string, loc []string, name string) {
+	p.report.Removed = append(p.report.Removed, PublishRemoval{
+		Kind:     kind,
+		Location: publishPointer(loc),
+		Name:     name})
+}
+
+// visible reports whether an audience and stage annotation pair is
+// visible. Unknown values are an error.
+func (p *publisher) visible(visibility, lifecycle any, loc []string) bool {
+	if v, ok := visibility.(string); ok {
+		level, ok := visibilityLevels[v]
+		if !ok {
+			p.setErr(fmt.Errorf("invalid %s [%s] at [%s]", XVisibility, v, publishPointer(loc)))
+			return false
+		} else if level < p.audience {
+			return false
+		}
+	}
+	if v, ok := lifecycle.(string); ok {
+		level, ok := lifecycleLevels[v]
+		if !ok {
+			p.setErr(fmt.Errorf("invalid %s [%s] at [%s]", XLifecycle, v, publishPointer(loc)))
+			return false
+		} else if level < p.stage {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *publisher) setErr(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// hidden reports whether an object or the components it references through
+// a chain of `$ref`s are not visible.
+func (p *publisher) hidden(obj any, loc []string) bool {
+	seen := map[string]bool{}
+	for {
+		m, ok := obj.(map[string]any)
+		if !ok {
+			return false
+		} else if !p.visible(m[XVisibility], m[XLifecycle], loc) {
+			return true
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok || seen[ref] {
+			return false
+		}
+		seen[ref] = true
+		if obj, ok = p.resolve(ref); !ok {
+			return false
+		}
+	}
+}
+
+// hiddenSchema reports whether a property schema or its array items are
+// not visible.
+func (p *publisher) hiddenSchema(obj any, loc []string) bool {
+	if p.hidden(obj, loc) {
+		return true
+	}
+	if m, ok := obj.(map[string]any); ok {
+		if items, ok := m["items"]; ok {
+			return p.hidden(items, append(loc, "items"))
+		}
+	}
+	return false
+}
+
+// resolve returns the component referenced by a local reference.
+func (p *publisher) resolve(ref string) (any, bool) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+	var cur any = p.doc
+	for _, tok := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[jsonpointer.PropertyNameUnescape(tok)]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// paths removes hidden path items and operations, and path items without
+// remaining operations.
+func (p *publisher) paths() {
+	root, _ := p.doc.(map[string]any)
+	paths, _ := root["paths"].(map[string]any)
+	for _, path := range sortedMapKeys(paths) {
+		loc := []string{"paths", path}
+		pathItem, ok := paths[path].(map[string]any)
+		if !ok {
+			continue
+		} else if p.hidden(pathItem, loc) {
+			delete(paths, path)
+			p.remove(PublishRemovedPath, []string{"paths"}, path)
+			continue
+		}
+		hasOps := false
+		for _, method := range publishMethods {
+			op, ok := pathItem[method]
+			if !ok {
+				continue
+			} else if p.hidden(op, append(loc, method)) {
+				delete(pathItem, method)
+				p.remove(PublishRemovedOperation, loc, strings.ToUpper(method))
+				continue
+			}
+			hasOps = true
+		}
+		if !hasOps {
+			delete(paths, path)
+		}
+	}
+}
+
+var publishMethods = []string{
+	strings.ToLower(http.MethodGet), strings.ToLower(http.MethodPut),
+	strings.ToLower(http.MethodPost), strings.ToLower(http.MethodDelete),
+	strings.ToLower(http.MethodOptions), strings.ToLower(http.MethodHead),
+	strings.ToLower(http.MethodPatch), strings.ToLower(http.MethodTrace)}
+
+// walk removes hidden parameters, properties and enum values below a
+// value. `loc` is the location of the value.
+func (p *publisher) walk(val any, loc []string) {
+	switch v := val.(type) {
+	case map[string]any:
+		inProperties := len(loc) > 0 && loc[len(loc)-1] == "properties"
+		if !inProperties {
+			if params, ok := v[openapi3.PathParameters].([]any); ok {
+				v[openapi3.PathParameters] = p.parameters(params, loc)
+			}
+			if props, ok := v["properties"].(map[string]any); ok {
+				p.properties(v, props, loc)
+			}
+			if _, ok := v["enum"].([]any); ok {
+				p.enum(v, loc)
+			}
+		}
+		for _, k := range sortedMapKeys(v) {
+			if !inProperties && publishValueKey(k) {
+				continue
+			}
+			p.walk(v[k], append(loc, k))
+		}
+	case []any:
+		for i, item := range v {
+			p.walk(item, append(loc, fmt.Sprintf("%d", i)))
+		}
+	}
+}
+
+func (p *publisher) parameters(params []any, loc []string) []any {
+	out := []any{}
+	for i, param := range params {
+		paramLoc := append(append([]string{}, loc...), openapi3.PathParameters, fmt.Sprintf("%d", i))
+		if !p.hidden(param, paramLoc) {
+			out = append(out, param)
+			continue
+		}
+		in, name := "", ""
+		if m, ok := param.(map[string]any); ok {
+			target := any(m)
+			if ref, ok := m["$ref"].(string); ok {
+				target, _ = p.resolve(ref)
+			}
+			if tm, ok := target.(map[string]any); ok {
+				in, _ = tm["in"].(string)
+				name, _ = tm["name"].(string)
+			}
+		}
+		if in == openapi3.InPath {
+			p.setErr(fmt.Errorf("path parameter cannot be hidden [%s] at [%s]", name, publishPointer(paramLoc)))
+			out = append(out, param)
+			continue
+		}
+		p.remove(PublishRemovedParameter, loc, in+"."+name)
+	}
+	return out
+}
+
+func (p *publisher) properties(schema, props map[string]any, loc []string) {
+	removed := map[string]bool{}
+	for _, name := range sortedMapKeys(props) {
+		if p.hiddenSchema(props[name], append(loc, "properties", name)) {
+			delete(props, name)
+			removed[name] = true
+			p.remove(PublishRemovedProperty, loc, name)
+		}
+	}
+	required, ok := schema["required"].([]any)
+	if !ok || len(removed) == 0 {
+		return
+	}
+	out := []any{}
+	for _, name := range required {
+		if s, ok := name.(string); !ok || !removed[s] {
+			out = append(out, name)
+		}
+	}
+	if len(out) > 0 {
+		schema["required"] = out
+	} else {
+		delete(schema, "required")
+	}
+}
+
+func (p *publisher) enum(schema map[string]any, loc []string) {
+	visibility, _ := schema[XEnumVisibility].(map[string]any)
+	lifecycle, _ := schema[XEnumLifecycle].(map[string]any)
+	if len(visibility) == 0 && len(lifecycle) == 0 {
+		return
+	}
+	values, _ := schema["enum"].([]any)
+	out := []any{}
+	for _, val := range values {
+		key := fmt.Sprintf("%v", val)
+		if p.visible(visibility[key], lifecycle[key], loc) {
+			out = append(out, val)
+		} else {
+			p.remove(PublishRemovedEnumValue, loc, key)
+		}
+	}
+	if len(out) == 0 {
+		p.setErr(fmt.Errorf("enum has no visible values at [%s]", publishPointer(loc)))
+		return
+	}
+	schema["enum"] = out
+}
+
+// checkComponents returns an error for hidden components which are still
+// used after pruning.
+func (p *publisher) checkComponents() error {
+	root, _ := p.doc.(map[string]any)
+	comps, _ := root[openapi3.PathComponents].(map[string]any)
+	for _, kind := range openapi3.ComponentKinds {
+		items, _ := comps[kind].(map[string]any)
+		for _, name := range sortedMapKeys(items) {
+			key := openapi3.ComponentKey{Kind: kind, Name: name}
+			if p.hidden(items[name], []string{openapi3.PathComponents, kind, name}) {
+				return fmt.Errorf("hidden component is still referenced [%s]", key.Pointer())
+			}
+		}
+	}
+	return p.err
+}
+
+// publishValueKey returns true for keys holding instance values or
+// extensions, which are not searched.
+func publishValueKey(key string) bool {
+	switch key {
+	case "example", "examples", "default", "enum":
+		return true
+	}
+	return strings.HasPrefix(key, "x-")
+}
+
+func publishRemoveAnnotations(val any) {
+	switch v := val.(type) {
+	case map[string]any:
+		delete(v, XVisibility)
+		delete(v, XLifecycle)
+		delete(v, XEnumVisibility)
+		delete(v, XEnumLifecycle)
+		for k, item := range v {
+			if k != "example" && k != "default" {
+				publishRemoveAnnotations(item)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			publishRemoveAnnotations(item)
+		}
+	}
+}
+
+func publishPointer(loc []string) string {
+	var sb strings.Builder
+	for _, tok := range loc {
+		sb.WriteString("/")
+		sb.WriteString(jsonpointer.PropertyNameEscape(tok))
+	}
+	return sb.String()
+}
+
+func sortedMapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,154 @@
+package openapi3edit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const publishTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer"}},
+          {"$ref": "#/components/parameters/Trace"},
+          {"name": "preview", "in": "query", "x-lifecycle": "beta", "schema": {"type": "boolean"}}
+        ],
+        "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}}
+      },
+      "delete": {
+        "operationId": "deletePets",
+        "x-visibility": "internal",
+        "responses": {"204": {"description": "Deleted"}}
+      }
+    },
+    "/partners": {
+      "x-visibility": "partner",
+      "get": {
+        "operationId": "listPartners",
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["name", "cost"],
+        "properties": {
+          "name": {"type": "string"},
+          "cost": {"type": "number", "x-visibility": "internal"},
+          "audit": {"type": "array", "items": {"$ref": "#/components/schemas/Audit"}},
+          "status": {
+            "type": "string",
+            "enum": ["available", "sold", "quarantined"],
+            "x-enum-visibility": {"quarantined": "partner"}
+          }
+        }
+      },
+      "Audit": {"type": "object", "x-visibility": "internal", "properties": {"user": {"type": "string"}}}
+    },
+    "parameters": {
+      "Trace": {"name": "X-Trace", "in": "header", "x-visibility": "internal", "schema": {"type": "string"}}
+    }
+  }
+}`
+
+func TestPublish(t *testing.T) {
+	tests := []struct {
+		opts    PublishOptions
+		removed []PublishRemoval
+		pruned  []string
+	}{
+		{
+			opts: PublishOptions{Audience: VisibilityPublic, Stage: LifecycleGA, RemoveAnnotations: true},
+			removed: []PublishRemoval{
+				{Kind: PublishRemovedPath, Location: "/paths", Name: "/partners"},
+				{Kind: PublishRemovedOperation, Location: "/paths/~1pets", Name: "DELETE"},
+				{Kind: PublishRemovedProperty, Location: "/components/schemas/Pet", Name: "audit"},
+				{Kind: PublishRemovedProperty, Location: "/components/schemas/Pet", Name: "cost"},
+				{Kind: PublishRemovedEnumValue, Location: "/components/schemas/Pet/properties/status", Name: "quarantined"},
+				{Kind: PublishRemovedParameter, Location: "/paths/~1pets/get", Name: "header.X-Trace"},
+				{Kind: PublishRemovedParameter, Location: "/paths/~1pets/get", Name: "query.preview"}},
+			pruned: []string{"#/components/parameters/Trace", "#/components/schemas/Audit"},
+		},
+		{
+			opts: PublishOptions{Audience: VisibilityPartner, Stage: LifecycleBeta},
+			removed: []PublishRemoval{
+				{Kind: PublishRemovedOperation, Location: "/paths/~1pets", Name: "DELETE"},
+				{Kind: PublishRemovedProperty, Location: "/components/schemas/Pet", Name: "audit"},
+				{Kind: PublishRemovedProperty, Location: "/components/schemas/Pet", Name: "cost"},
+				{Kind: PublishRemovedParameter, Location: "/paths/~1pets/get", Name: "header.X-Trace"}},
+			pruned: []string{"#/components/parameters/Trace", "#/components/schemas/Audit"},
+		},
+		{
+			opts:    PublishOptions{Audience: VisibilityInternal, Stage: LifecycleAlpha},
+			removed: []PublishRemoval{},
+			pruned:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		spec, err := openapi3.Parse([]byte(publishTestSpec))
+		if err != nil {
+			t.Fatalf("openapi3.Parse() error [%v]", err)
+		}
+		se := NewSpecEdit(spec)
+		report, err := se.Publish(tt.opts)
+		if err != nil {
+			t.Fatalf("openapi3edit.SpecEdit.Publish() error [%v]", err)
+		}
+		if !reflect.DeepEqual(report.Removed, tt.removed) {
+			t.Errorf("openapi3edit.SpecEdit.Publish() removed mismatch: audience [%s] want [%v], got [%v]", tt.opts.Audience, tt.removed, report.Removed)
+		}
+		pruned := []string{}
+		for _, key := range report.Components {
+			pruned = append(pruned, key.Pointer())
+		}
+		if !reflect.DeepEqual(pruned, tt.pruned) {
+			t.Errorf("openapi3edit.SpecEdit.Publish() pruned mismatch: audience [%s] want [%v], got [%v]", tt.opts.Audience, tt.pruned, pruned)
+		}
+		if err := se.SpecMore.Validate(); err != nil {
+			t.Errorf("openapi3edit.SpecEdit.Publish() validation error: audience [%s] error [%v]", tt.opts.Audience, err)
+		}
+		pet := spec.Components.Schemas["Pet"].Value
+		if tt.opts.Audience == VisibilityPublic {
+			if !reflect.DeepEqual(pet.Required, []string{"name"}) {
+				t.Errorf("openapi3edit.SpecEdit.Publish() required mismatch: want [name], got [%v]", pet.Required)
+			}
+			if _, ok := pet.Properties["status"].Value.Extensions[XEnumVisibility]; ok {
+				t.Errorf("openapi3edit.SpecEdit.Publish() annotations mismatch: want [%s] removed", XEnumVisibility)
+			}
+		}
+	}
+}
+
+func TestPublishErrors(t *testing.T) {
+	specs := []string{
+		`{"openapi": "3.0.3", "info": {"title": "T", "version": "1"}, "paths": {"/pets/{id}": {"get": {
+		  "parameters": [{"name": "id", "in": "path", "required": true, "x-visibility": "internal", "schema": {"type": "string"}}],
+		  "responses": {"200": {"description": "OK"}}}}}}`,
+		`{"openapi": "3.0.3", "info": {"title": "T", "version": "1"}, "paths": {"/pets": {"get": {
+		  "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Secret"}}}}}}}},
+		  "components": {"schemas": {"Secret": {"type": "string", "x-visibility": "internal"}}}}`,
+		`{"openapi": "3.0.3", "info": {"title": "T", "version": "1"}, "paths": {"/pets": {"get": {
+		  "x-visibility": "secret", "responses": {"200": {"description": "OK"}}}}}}`,
+	}
+	for _, data := range specs {
+		spec, err := openapi3.Parse([]byte(data))
+		if err != nil {
+			t.Fatalf("openapi3.Parse() error [%v]", err)
+		}
+		se := NewSpecEdit(spec)
+		if _, err := se.Publish(PublishOptions{}); err == nil {
+			t.Errorf("openapi3edit.SpecEdit.Publish() mismatch: want error, got [nil]")
+		}
+		if spec.Paths.Len() != 1 {
+			t.Errorf("openapi3edit.SpecEdit.Publish() mismatch: want spec unchanged on error")
+		}
+	}
+}
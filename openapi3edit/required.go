@@ -10,6 +10,7 @@ import (
 // SchemaPropertiesSetOptional sets properties as optional if the description matches a regexp
 // such as var rxOptionalDefault = regexp.MustCompile(`(?i)\boptional\b`)
 func (se *SpecEdit) SchemaPropertiesSetOptional(rxOptional *regexp.Regexp) error {
+	defer se.journalEdit("SchemaPropertiesSetOptional")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
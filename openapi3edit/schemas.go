@@ -16,6 +16,7 @@ import (
 )
 
 func (se *SpecEdit) AddSchemaDir(dir string, fileRx *regexp.Regexp) error {
+	defer se.journalEdit("AddSchemaDir")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
@@ -52,6 +53,7 @@ func (se *SpecEdit) AddSchemaDir(dir string, fileRx *regexp.Regexp) error {
 // `*regexp.Regexp` and replaces that with a string. It was originally
 // designed to convert `#schemas/` to `#components/schemas/`.
 func (se *SpecEdit) SchemaRefsModifyRx(rx *regexp.Regexp, repl string) {
+	defer se.journalEdit("SchemaRefsModifyRx")()
 	if se.SpecMore.Spec == nil || rx == nil {
 		return
 	}
@@ -60,59 +62,46 @@ func (se *SpecEdit) SchemaRefsModifyRx(rx *regexp.Regexp, repl string) {
 	})
 }
 
-// SchemaRefsModify modifys schema reference JSON pointers. The xf function
-// must return the entire JSON pointer.
+// SchemaRefsModify modifys schema reference JSON pointers in components,
+// path-level and operation parameters, request bodies, responses, headers,
+// media type encodings and callbacks. The xf function must return the entire
+// JSON pointer.
 func (se *SpecEdit) SchemaRefsModify(xf func(string) string) {
+	defer se.journalEdit("SchemaRefsModify")()
 	if se.SpecMore.Spec == nil || xf == nil {
 		return
 	}
 	spec := se.SpecMore.Spec
 
-	for _, paramRef := range spec.Components.Parameters {
-		if paramRef == nil {
-			continue
-		}
-		// paramRef.Ref = rx.ReplaceAllString(paramRef.Ref, repl)
-		paramRef.Ref = xf(paramRef.Ref)
-		if paramRef.Value != nil && paramRef.Value.Schema != nil {
-			//SchemaRefModifyRefs(paramRef.Value.Schema, rx, repl)
-			SchemaRefModifyRefs(paramRef.Value.Schema, xf)
-		}
-	}
-
-	openapi3.VisitOperations(spec, func(opPath, opMethod string, op *oas3.Operation) {
-		if op == nil {
-			return
+	if spec.Components != nil {
+		for _, paramRef := range spec.Components.Parameters {
+			parameterRefModifyRefs(paramRef, xf)
 		}
-		// Operation Parameters
-		for _, paramRef := range op.Parameters {
-			paramRef.Ref = xf(paramRef.Ref)
-			if paramRef.Value != nil && paramRef.Value.Schema != nil {
-				SchemaRefModifyRefs(paramRef.Value.Schema, xf)
-			}
+		for _, headerRef := range spec.Components.Headers {
+			headerRefModifyRefs(headerRef, xf)
 		}
-		// Operation Requests
-		if op.RequestBody != nil {
-			op.RequestBody.Ref = xf(op.RequestBody.Ref)
-			if op.RequestBody.Value != nil {
-				for _, mediaType := range op.RequestBody.Value.Content {
-					SchemaRefModifyRefs(mediaType.Schema, xf)
-				}
-			}
-		}
-		// Operation Responses
-		respsMap := op.Responses.Map()
-		for _, respRef := range respsMap {
-			// for _, respRef := range op.Responses { // getkin v0.121.0 to v0.122.0
-			respRef.Ref = xf(respRef.Ref)
-			if respRef.Value == nil {
+		for _, reqBodyRef := range spec.Components.RequestBodies {
+			if reqBodyRef == nil {
 				continue
 			}
-			for _, mediaType := range respRef.Value.Content {
-				SchemaRefModifyRefs(mediaType.Schema, xf)
+			reqBodyRef.Ref = xf(reqBodyRef.Ref)
+			if reqBodyRef.Value != nil {
+				contentModifyRefs(reqBodyRef.Value.Content, xf)
 			}
 		}
-	})
+		for _, respRef := range spec.Components.Responses {
+			responseRefModifyRefs(respRef, xf)
+		}
+		for _, callbackRef := range spec.Components.Callbacks {
+			callbackRefModifyRefs(callbackRef, xf)
+		}
+	}
+
+	if spec.Paths != nil {
+		for _, pathItem := range spec.Paths.Map() {
+			pathItemModifyRefs(pathItem, xf)
+		}
+	}
 
 	for _, schRef := range spec.Components.Schemas {
 		if schRef == nil {
@@ -151,6 +140,104 @@ func (se *SpecEdit) SchemaRefsModify(xf func(string) string) {
 	}
 }
 
+// pathItemModifyRefs modifies schema references of path-level parameters
+// and operations, including operation callbacks.
+func pathItemModifyRefs(pathItem *oas3.PathItem, xf func(string) string) {
+	if pathItem == nil {
+		return
+	}
+	for _, paramRef := range pathItem.Parameters {
+		parameterRefModifyRefs(paramRef, xf)
+	}
+	openapi3.VisitOperationsPathItem("", pathItem, func(opPath, opMethod string, op *oas3.Operation) {
+		if op == nil {
+			return
+		}
+		for _, paramRef := range op.Parameters {
+			parameterRefModifyRefs(paramRef, xf)
+		}
+		if op.RequestBody != nil {
+			op.RequestBody.Ref = xf(op.RequestBody.Ref)
+			if op.RequestBody.Value != nil {
+				contentModifyRefs(op.RequestBody.Value.Content, xf)
+			}
+		}
+		if op.Responses != nil {
+			for _, respRef := range op.Responses.Map() {
+				responseRefModifyRefs(respRef, xf)
+			}
+		}
+		for _, callbackRef := range op.Callbacks {
+			callbackRefModifyRefs(callbackRef, xf)
+		}
+	})
+}
+
+func callbackRefModifyRefs(callbackRef *oas3.CallbackRef, xf func(string) string) {
+	if callbackRef == nil {
+		return
+	}
+	callbackRef.Ref = xf(callbackRef.Ref)
+	if callbackRef.Value != nil {
+		for _, pathItem := range callbackRef.Value.Map() {
+			pathItemModifyRefs(pathItem, xf)
+		}
+	}
+}
+
+func parameterRefModifyRefs(paramRef *oas3.ParameterRef, xf func(string) string) {
+	if paramRef == nil {
+		return
+	}
+	paramRef.Ref = xf(paramRef.Ref)
+	if paramRef.Value != nil {
+		SchemaRefModifyRefs(paramRef.Value.Schema, xf)
+		contentModifyRefs(paramRef.Value.Content, xf)
+	}
+}
+
+func headerRefModifyRefs(headerRef *oas3.HeaderRef, xf func(string) string) {
+	if headerRef == nil {
+		return
+	}
+	headerRef.Ref = xf(headerRef.Ref)
+	if headerRef.Value != nil {
+		SchemaRefModifyRefs(headerRef.Value.Schema, xf)
+		contentModifyRefs(headerRef.Value.Content, xf)
+	}
+}
+
+func responseRefModifyRefs(respRef *oas3.ResponseRef, xf func(string) string) {
+	if respRef == nil {
+		return
+	}
+	respRef.Ref = xf(respRef.Ref)
+	if respRef.Value == nil {
+		return
+	}
+	for _, headerRef := range respRef.Value.Headers {
+		headerRefModifyRefs(headerRef, xf)
+	}
+	contentModifyRefs(respRef.Value.Content, xf)
+}
+
+func contentModifyRefs(content oas3.Content, xf func(string) string) {
+	for _, mediaType := range content {
+		if mediaType == nil {
+			continue
+		}
+		SchemaRefModifyRefs(mediaType.Schema, xf)
+		for _, enc := range mediaType.Encoding {
+			if enc == nil {
+				continue
+			}
+			for _, headerRef := range enc.Headers {
+				headerRefModifyRefs(headerRef, xf)
+			}
+		}
+	}
+}
+
 // SchemaRefModifyRefsRx modifies Schema reference schema pointers that match
 // the supplied `*regexp.Regexp` with the replacement string. It was originally
 // designed to convert `#schemas/` to `#components/schemas/`.
@@ -180,18 +267,24 @@ func SchemaRefModifyRefs(schRef *oas3.SchemaRef, xf func(string) string) {
 	if schRef.Value.Not != nil {
 		SchemaRefModifyRefs(schRef.Value.Not, xf)
 	}
-	for _, allOf := range schRef.Value.OneOf {
+	for _, allOf := range schRef.Value.AllOf {
 		SchemaRefModifyRefs(allOf, xf)
 	}
-	for _, anyOf := range schRef.Value.OneOf {
+	for _, anyOf := range schRef.Value.AnyOf {
 		SchemaRefModifyRefs(anyOf, xf)
 	}
 	for _, oneOf := range schRef.Value.OneOf {
 		SchemaRefModifyRefs(oneOf, xf)
 	}
+	if disc := schRef.Value.Discriminator; disc != nil {
+		for k, ref := range disc.Mapping {
+			disc.Mapping[k] = xf(ref)
+		}
+	}
 }
 
 func (se *SpecEdit) SchemaSetAdditionalPropertiesTrue(pointerBase string) []string {
+	defer se.journalEdit("SchemaSetAdditionalPropertiesTrue")()
 	mods := []string{}
 	if se.SpecMore.Spec == nil {
 		return mods
@@ -0,0 +1,301 @@
+package openapi3edit
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grokify/mogo/encoding/jsonpointer"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+// SchemasDedupeOptions configures `SpecEdit.SchemasDedupe()`.
+type SchemasDedupeOptions struct {
+	// IgnoreDocs compares schemas without `title`, `description`, `example`
+	// and `externalDocs` so schemas differing only in documentation are
+	// merged.
+	IgnoreDocs bool
+	// CanonicalName picks the name kept from a sorted set of equivalent
+	// schema names. Defaults to `CanonicalNameShortest`.
+	CanonicalName func(names []string) string
+}
+
+// CanonicalNameShortest returns the shortest name, then the first
+// alphabetically, e.g. `User` for `User`, `UserResponse` and `user_1`.
+func CanonicalNameShortest(names []string) string {
+	canonical := ""
+	for _, name := range names {
+		if canonical == "" || len(name) < len(canonical) ||
+			(len(name) == len(canonical) && name < canonical) {
+			canonical = name
+		}
+	}
+	return canonical
+}
+
+// CanonicalNameAlphabetical returns the first name alphabetically.
+func CanonicalNameAlphabetical(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return slices.Min(names)
+}
+
+// CanonicalNamePreferred returns a canonical name function which picks
+// the first name of `preferred` that is in the set, using `fallback`
+// otherwise. `fallback` defaults to `CanonicalNameShortest`.
+func CanonicalNamePreferred(preferred []string, fallback func(names []string) string) func(names []string) string {
+	if fallback == nil {
+		fallback = CanonicalNameShortest
+	}
+	return func(names []string) string {
+		for _, name := range preferred {
+			if slices.Contains(names, name) {
+				return name
+			}
+		}
+		return fallback(names)
+	}
+}
+
+// SchemasDedupe merges structurally identical component schemas into one
+// canonical schema, deletes the others and rewrites references using
+// `SchemaRefsModify()`. Schemas are compared after normalization: `required`
+// is sorted, self references are treated alike and references to schemas
+// already merged point to the canonical schema, so the pass repeats until
+// no more schemas merge. Schemas which are separate `oneOf` or `anyOf`
+// branches or discriminator mapping targets of the same schema are not
+// merged with each other. It returns a mapping of removed schema names to
+// canonical schema names.
+func (se *SpecEdit) SchemasDedupe(opts *SchemasDedupeOptions) (map[string]string, error) {
+	defer se.journalEdit("SchemasDedupe")()
+	if se.SpecMore.Spec == nil {
+		return nil, openapi3.ErrSpecNotSet
+	}
+	mapping := map[string]string{}
+	spec := se.SpecMore.Spec
+	if spec.Components == nil {
+		return mapping, nil
+	}
+	if opts == nil {
+		opts = &SchemasDedupeOptions{}
+	}
+	canonicalName := opts.CanonicalName
+	if canonicalName == nil {
+		canonicalName = CanonicalNameShortest
+	}
+	for {
+		distinct, err := schemaUnionMembers(spec)
+		if err != nil {
+			return mapping, err
+		}
+		groups := map[string][]string{}
+		for name, schRef := range spec.Components.Schemas {
+			if schRef == nil {
+				continue
+			}
+			fp, err := schemaFingerprint(name, schRef, opts.IgnoreDocs)
+			if err != nil {
+				return mapping, err
+			}
+			groups[fp] = append(groups[fp], name)
+		}
+		merged := map[string]string{}
+		for _, names := range groups {
+			sort.Strings(names)
+			for len(names) > 1 {
+				canonical := canonicalName(names)
+				if !slices.Contains(names, canonical) {
+					canonical = names[0]
+				}
+				cluster := []string{canonical}
+				rest := []string{}
+				for _, name := range names {
+					if name == canonical {
+						continue
+					} else if schemaNamesDistinct(name, cluster, distinct) {
+						rest = append(rest, name)
+					} else {
+						cluster = append(cluster, name)
+						merged[name] = canonical
+					}
+				}
+				names = rest
+			}
+		}
+		if len(merged) == 0 {
+			return mapping, nil
+		}
+		for name, canonical := range merged {
+			delete(spec.Components.Schemas, name)
+			mapping[name] = canonical
+		}
+		for name, canonical := range mapping {
+			if next, ok := merged[canonical]; ok {
+				mapping[name] = next
+			}
+		}
+		se.SchemaRefsModify(func(ref string) string {
+			return schemaRefRename(ref, merged)
+		})
+	}
+}
+
+// schemaRefRename rewrites a component schema reference, including pointers
+// into the schema, for renamed schemas.
+func schemaRefRename(ref string, renames map[string]string) string {
+	prefix := openapi3.PointerComponentsSchemas + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return ref
+	}
+	name, rest, hasRest := strings.Cut(strings.TrimPrefix(ref, prefix), "/")
+	newName, ok := renames[jsonpointer.PropertyNameUnescape(name)]
+	if !ok {
+		return ref
+	}
+	ref = prefix + jsonpointer.PropertyNameEscape(newName)
+	if hasRest {
+		ref += "/" + rest
+	}
+	return ref
+}
+
+// schemaUnionMembers returns the sets of component schema names which are
+// referenced as `oneOf` or `anyOf` branches or discriminator mapping targets
+// of the same schema and so must stay distinct.
+func schemaUnionMembers(spec *openapi3.Spec) ([]map[string]bool, error) {
+	bytes, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var val any
+	if err := json.Unmarshal(bytes, &val); err != nil {
+		return nil, err
+	}
+	members := []map[string]bool{}
+	var walk func(v any)
+	walk = func(v any) {
+		switch vt := v.(type) {
+		case map[string]any:
+			set := map[string]bool{}
+			for _, key := range []string{"oneOf", "anyOf"} {
+				branches, _ := vt[key].([]any)
+				for _, branch := range branches {
+					if branchMap, ok := branch.(map[string]any); ok {
+						addSchemaRefName(set, branchMap["$ref"])
+					}
+				}
+			}
+			if disc, ok := vt["discriminator"].(map[string]any); ok {
+				if mapping, ok := disc["mapping"].(map[string]any); ok {
+					for _, ref := range mapping {
+						addSchemaRefName(set, ref)
+					}
+				}
+			}
+			if len(set) > 1 {
+				members = append(members, set)
+			}
+			for _, child := range vt {
+				walk(child)
+			}
+		case []any:
+			for _, child := range vt {
+				walk(child)
+			}
+		}
+	}
+	walk(val)
+	return members, nil
+}
+
+func addSchemaRefName(set map[string]bool, ref any) {
+	refStr, ok := ref.(string)
+	if !ok {
+		return
+	}
+	prefix := openapi3.PointerComponentsSchemas + "/"
+	if !strings.HasPrefix(refStr, prefix) {
+		return
+	}
+	name, _, _ := strings.Cut(strings.TrimPrefix(refStr, prefix), "/")
+	set[jsonpointer.PropertyNameUnescape(name)] = true
+}
+
+// schemaNamesDistinct reports whether `name` must stay distinct from any
+// of `names`.
+func schemaNamesDistinct(name string, names []string, distinct []map[string]bool) bool {
+	for _, set := range distinct {
+		if !set[name] {
+			continue
+		}
+		for _, other := range names {
+			if set[other] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var schemaDocKeys = []string{"title", "description", "example", "externalDocs"}
+
+// schemaFingerprint returns a normalized JSON encoding of a schema.
+func schemaFingerprint(name string, schRef any, ignoreDocs bool) (string, error) {
+	bytes, err := json.Marshal(schRef)
+	if err != nil {
+		return "", err
+	}
+	var val any
+	if err := json.Unmarshal(bytes, &val); err != nil {
+		return "", err
+	}
+	self := openapi3.PointerComponentsSchemas + "/" + jsonpointer.PropertyNameEscape(name)
+	val = schemaNormalize(val, self, ignoreDocs)
+	bytes, err = json.Marshal(val)
+	return string(bytes), err
+}
+
+func schemaNormalize(val any, self string, ignoreDocs bool) any {
+	sch, ok := val.(map[string]any)
+	if !ok {
+		return val
+	}
+	if ref, ok := sch["$ref"].(string); ok {
+		if ref == self || strings.HasPrefix(ref, self+"/") {
+			sch["$ref"] = "#" + strings.TrimPrefix(ref, self)
+		}
+		return sch
+	}
+	if ignoreDocs {
+		for _, key := range schemaDocKeys {
+			delete(sch, key)
+		}
+	}
+	if required, ok := sch["required"].([]any); ok {
+		sort.Slice(required, func(i, j int) bool {
+			si, _ := required[i].(string)
+			sj, _ := required[j].(string)
+			return si < sj
+		})
+	}
+	if props, ok := sch["properties"].(map[string]any); ok {
+		for k, v := range props {
+			props[k] = schemaNormalize(v, self, ignoreDocs)
+		}
+	}
+	for _, key := range []string{"items", "not", "additionalProperties"} {
+		if sub, ok := sch[key]; ok {
+			sch[key] = schemaNormalize(sub, self, ignoreDocs)
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if subs, ok := sch[key].([]any); ok {
+			for i, sub := range subs {
+				subs[i] = schemaNormalize(sub, self, ignoreDocs)
+			}
+		}
+	}
+	return sch
+}
@@ -0,0 +1,219 @@
+package openapi3edit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const schemasDedupeTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Users", "version": "1.0.0"},
+  "paths": {
+    "/users": {
+      "get": {
+        "responses": {
+          "200": {
+            "description": "Users",
+            "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/UserResponse"}}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "User": {
+        "type": "object",
+        "required": ["id", "name"],
+        "properties": {
+          "id": {"type": "string"},
+          "name": {"type": "string"},
+          "address": {"$ref": "#/components/schemas/Address"},
+          "manager": {"$ref": "#/components/schemas/User"}
+        }
+      },
+      "UserResponse": {
+        "type": "object",
+        "required": ["name", "id"],
+        "properties": {
+          "id": {"type": "string"},
+          "name": {"type": "string"},
+          "address": {"$ref": "#/components/schemas/Address_1"},
+          "manager": {"$ref": "#/components/schemas/UserResponse"}
+        }
+      },
+      "user_1": {
+        "type": "object",
+        "description": "A user.",
+        "required": ["id", "name"],
+        "properties": {
+          "id": {"type": "string"},
+          "name": {"type": "string"},
+          "address": {"$ref": "#/components/schemas/Address"},
+          "manager": {"$ref": "#/components/schemas/user_1"}
+        }
+      },
+      "Address": {"type": "object", "properties": {"city": {"type": "string"}}},
+      "Address_1": {"type": "object", "properties": {"city": {"type": "string"}}},
+      "Team": {"allOf": [{"$ref": "#/components/schemas/UserResponse"}]}
+    }
+  }
+}`
+
+func TestSchemasDedupe(t *testing.T) {
+	tests := []struct {
+		opts *SchemasDedupeOptions
+		want map[string]string
+	}{
+		{nil, map[string]string{"Address_1": "Address", "UserResponse": "User"}},
+		{&SchemasDedupeOptions{IgnoreDocs: true}, map[string]string{"Address_1": "Address", "UserResponse": "User", "user_1": "User"}},
+		{&SchemasDedupeOptions{IgnoreDocs: true, CanonicalName: CanonicalNamePreferred([]string{"UserResponse"}, nil)},
+			map[string]string{"Address_1": "Address", "User": "UserResponse", "user_1": "UserResponse"}},
+	}
+	for _, tt := range tests {
+		spec, err := openapi3.Parse([]byte(schemasDedupeTestSpec))
+		if err != nil {
+			t.Fatalf("openapi3.Parse() error [%v]", err)
+		}
+		se := NewSpecEdit(spec)
+		got, err := se.SchemasDedupe(tt.opts)
+		if err != nil {
+			t.Fatalf("SpecEdit.SchemasDedupe() error [%v]", err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SpecEdit.SchemasDedupe() mismatch: want [%v], got [%v]", tt.want, got)
+		}
+		canonical := tt.want["Address_1"]
+		if _, ok := spec.Components.Schemas["Address_1"]; ok {
+			t.Errorf("SpecEdit.SchemasDedupe() duplicate not removed [%s]", "Address_1")
+		}
+		userName := "User"
+		if tt.want["User"] != "" {
+			userName = tt.want["User"]
+		}
+		user := spec.Components.Schemas[userName].Value
+		if ref := user.Properties["address"].Ref; ref != "#/components/schemas/"+canonical {
+			t.Errorf("SpecEdit.SchemasDedupe() ref mismatch: got [%s]", ref)
+		}
+		if ref := user.Properties["manager"].Ref; ref != "#/components/schemas/"+userName {
+			t.Errorf("SpecEdit.SchemasDedupe() self ref mismatch: got [%s]", ref)
+		}
+		if ref := spec.Components.Schemas["Team"].Value.AllOf[0].Ref; ref != "#/components/schemas/"+userName {
+			t.Errorf("SpecEdit.SchemasDedupe() allOf ref mismatch: got [%s]", ref)
+		}
+		items := spec.Paths.Find("/users").Get.Responses.Value("200").Value.Content["application/json"].Schema.Value.Items
+		if items.Ref != "#/components/schemas/"+userName {
+			t.Errorf("SpecEdit.SchemasDedupe() operation ref mismatch: got [%s]", items.Ref)
+		}
+	}
+}
+
+const schemasDedupeRefsTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Ids", "version": "1.0.0"},
+  "paths": {
+    "/items/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"$ref": "#/components/schemas/IdResponse"}}],
+      "get": {
+        "parameters": [{"name": "filter", "in": "query", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/IdResponse"}}}}],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "headers": {"X-Id": {"schema": {"$ref": "#/components/schemas/IdResponse"}}, "X-Trace": {"$ref": "#/components/headers/Trace"}}
+          }
+        },
+        "callbacks": {
+          "onUpdate": {
+            "{$request.query.url}": {
+              "post": {
+                "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/IdResponse"}}}},
+                "responses": {"200": {"description": "OK"}}
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Id": {"type": "string"},
+      "IdResponse": {"type": "string"}
+    },
+    "headers": {
+      "Trace": {"schema": {"$ref": "#/components/schemas/IdResponse"}}
+    }
+  }
+}`
+
+func TestSchemasDedupeRefs(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(schemasDedupeRefsTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	se := NewSpecEdit(spec)
+	if _, err := se.SchemasDedupe(nil); err != nil {
+		t.Fatalf("SpecEdit.SchemasDedupe() error [%v]", err)
+	}
+	bytes, err := spec.MarshalJSON()
+	if err != nil {
+		t.Fatalf("openapi3.Spec.MarshalJSON() error [%v]", err)
+	}
+	if strings.Contains(string(bytes), "IdResponse") {
+		t.Errorf("SpecEdit.SchemasDedupe() mismatch: want no [IdResponse] refs, got [%s]", string(bytes))
+	}
+	if _, err := openapi3.Parse(bytes); err != nil {
+		t.Errorf("SpecEdit.SchemasDedupe() reload error [%v]", err)
+	}
+}
+
+const schemasDedupeUnionTestSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {
+        "oneOf": [{"$ref": "#/components/schemas/Cat"}, {"$ref": "#/components/schemas/Dog"}],
+        "discriminator": {
+          "propertyName": "petType",
+          "mapping": {"cat": "#/components/schemas/Cat", "dog": "#/components/schemas/Dog", "hound": "#/components/schemas/Hound"}
+        }
+      },
+      "Cat": {"type": "object", "properties": {"petType": {"type": "string"}}},
+      "Dog": {"type": "object", "properties": {"petType": {"type": "string"}}},
+      "Hound": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "HoundResponse": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "DogResponse": {"type": "object", "properties": {"petType": {"type": "string"}}}
+    }
+  }
+}`
+
+func TestSchemasDedupeUnion(t *testing.T) {
+	spec, err := openapi3.Parse([]byte(schemasDedupeUnionTestSpec))
+	if err != nil {
+		t.Fatalf("openapi3.Parse() error [%v]", err)
+	}
+	se := NewSpecEdit(spec)
+	got, err := se.SchemasDedupe(nil)
+	if err != nil {
+		t.Fatalf("SpecEdit.SchemasDedupe() error [%v]", err)
+	}
+	want := map[string]string{"DogResponse": "Cat", "HoundResponse": "Hound"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SpecEdit.SchemasDedupe() mismatch: want [%v], got [%v]", want, got)
+	}
+	pet := spec.Components.Schemas["Pet"].Value
+	if pet.OneOf[0].Ref == pet.OneOf[1].Ref {
+		t.Errorf("SpecEdit.SchemasDedupe() oneOf branches merged: got [%s]", pet.OneOf[0].Ref)
+	}
+	for key, ref := range pet.Discriminator.Mapping {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf("SpecEdit.SchemasDedupe() discriminator mapping [%s] dangles: got [%s]", key, ref)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 )
 
 func (se *SpecEdit) SchemasFlatten() {
+	defer se.journalEdit("SchemasFlatten")()
 	if se.SpecMore.Spec == nil {
 		return
 	}
@@ -23,6 +24,7 @@ func (se *SpecEdit) SchemasFlatten() {
 }
 
 func (se *SpecEdit) SchemasFlattenSchemaRef(baseName, schName string, schRef *oas3.SchemaRef) {
+	defer se.journalEdit("SchemasFlattenSchemaRef")()
 	if se.SpecMore.Spec == nil || schRef == nil {
 		return
 	}
@@ -83,6 +85,7 @@ var ErrEmptySchemaName = errors.New("empty schema name encountered")
 
 // SchemaRefsFlatten flattens Schema refs.
 func (se *SpecEdit) SchemaRefsFlatten() error {
+	defer se.journalEdit("SchemaRefsFlatten")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
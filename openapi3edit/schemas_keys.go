@@ -11,6 +11,7 @@ import (
 )
 
 func (se *SpecEdit) SchemaKeysModify(xf func(string) string) error {
+	defer se.journalEdit("SchemaKeysModify")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	} else if xf == nil {
@@ -20,6 +20,7 @@ const (
 
 // SecuritySchemeAddBearertoken adds bearer token auth to spec and operations.
 func (se *SpecEdit) SecuritySchemeAddBearertoken(schemeName, bearerFormat string, inclTags, skipTags []string) error {
+	defer se.journalEdit("SecuritySchemeAddBearertoken")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
@@ -35,6 +36,7 @@ func (se *SpecEdit) SecuritySchemeAddBearertoken(schemeName, bearerFormat string
 }
 
 func (se *SpecEdit) SecuritySchemeBearertokenAddOperationsByTags(schemeName string, inclTags, skipTags []string) error {
+	defer se.journalEdit("SecuritySchemeBearertokenAddOperationsByTags")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
@@ -84,6 +86,7 @@ func (se *SpecEdit) SecuritySchemeBearertokenAddOperationsByTags(schemeName stri
 }
 
 func (se *SpecEdit) SecuritySchemeBearertokenAddDefinition(schemeName, bearerFormat string) error {
+	defer se.journalEdit("SecuritySchemeBearertokenAddDefinition")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
@@ -100,6 +103,12 @@ func (se *SpecEdit) SecuritySchemeBearertokenAddDefinition(schemeName, bearerFor
 	if len(bearerFormat) > 0 {
 		scheme.Value.BearerFormat = bearerFormat
 	}
+	if spec.Components == nil {
+		spec.Components = &oas3.Components{}
+	}
+	if spec.Components.SecuritySchemes == nil {
+		spec.Components.SecuritySchemes = map[string]*oas3.SecuritySchemeRef{}
+	}
 	spec.Components.SecuritySchemes[schemeName] = scheme
 	return nil
 }
@@ -107,6 +116,7 @@ func (se *SpecEdit) SecuritySchemeBearertokenAddDefinition(schemeName, bearerFor
 // AddAPIKey adds an API Key definition to the spec.
 // https://swagger.io/docs/specification/authentication/api-keys/
 func (se *SpecEdit) SecuritySchemeApikeyAddDefinition(schemeName, location, name string) error {
+	defer se.journalEdit("SecuritySchemeApikeyAddDefinition")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
@@ -139,6 +149,7 @@ func (se *SpecEdit) SecuritySchemeApikeyAddDefinition(schemeName, location, name
 }
 
 func (se *SpecEdit) SecuritySchemeApikeyAddOperations(tags []string, keyName string) error {
+	defer se.journalEdit("SecuritySchemeApikeyAddOperations")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
@@ -190,6 +201,7 @@ func MapSliceIntersection(haystack map[string]int, needles []string, unique bool
 // to get individual specs to validate before setting the
 // correct security property.
 func (se *SpecEdit) OperationsSecurityRemove(inclPathMethods []string) error {
+	defer se.journalEdit("OperationsSecurityRemove")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
@@ -4,6 +4,7 @@ import "github.com/grokify/spectrum/openapi3"
 
 type SpecEdit struct {
 	SpecMore openapi3.SpecMore
+	journal  *Journal
 }
 
 func NewSpecEdit(spec *openapi3.Spec) SpecEdit {
@@ -12,10 +13,12 @@ func NewSpecEdit(spec *openapi3.Spec) SpecEdit {
 }
 
 func (se *SpecEdit) ExtensionSet(key string, val any) {
+	defer se.journalEdit("ExtensionSet")()
 	// se.SpecMore.Spec.ExtensionProps.Extensions[key] = val
 	se.SpecMore.Spec.Extensions[key] = val
 }
 
 func (se *SpecEdit) SpecSet(spec *openapi3.Spec) {
+	defer se.journalEdit("SpecSet")()
 	se.SpecMore = openapi3.SpecMore{Spec: spec}
 }
@@ -11,6 +11,7 @@ import (
 )
 
 func (se *SpecEdit) TagsModifyMore(opts *TagsModifyOpts) {
+	defer se.journalEdit("TagsModifyMore")()
 	if opts == nil {
 		return
 	}
@@ -27,6 +28,7 @@ func (se *SpecEdit) TagsModifyMore(opts *TagsModifyOpts) {
 
 // TagsModify renames tags using mapping of old tags to new tags.
 func (se *SpecEdit) TagsModify(mapTagsOldToNew map[string]string) {
+	defer se.journalEdit("TagsModify")()
 	spec := se.SpecMore.Spec
 	if spec == nil {
 		return
@@ -70,6 +72,7 @@ TAG:
 // and explitcit sort order. The remaining tags are sorted
 // alphabetically.
 func (se *SpecEdit) TagsOrder(explicitSortedTagNames []string) error {
+	defer se.journalEdit("TagsOrder")()
 	if se.SpecMore.Spec == nil {
 		return openapi3.ErrSpecNotSet
 	}
@@ -127,6 +130,7 @@ func TagsOrder(curTags oas3.Tags, explicitSortedTagNames []string) (oas3.Tags, e
 // level specification by comparing with tags used
 // in operations.
 func (se *SpecEdit) SpecTagsCondense() {
+	defer se.journalEdit("SpecTagsCondense")()
 	if se.SpecMore.Spec == nil {
 		return
 	}
@@ -63,6 +63,7 @@ func SortParameters(sourceParams oas3.Parameters, sortOrder []string) oas3.Param
 // and re-sort parameters so required path parameters are on top and
 // sorted by their position in the path.
 func (se *SpecEdit) ValidateFixOperationPathParameters(fix bool) ([]*openapi3.OperationMeta, error) {
+	defer se.journalEdit("ValidateFixOperationPathParameters")()
 	errorOperations := []*openapi3.OperationMeta{}
 	if se.SpecMore.Spec == nil {
 		return errorOperations, openapi3.ErrSpecNotSet
@@ -130,6 +131,7 @@ func (se *SpecEdit) ValidateFixOperationPathParameters(fix bool) ([]*openapi3.Op
 // OperationsRequestBodyMove moves `requestBody` `$ref` to the operation
 // which appears to be supported by more tools.
 func (se *SpecEdit) OperationsRequestBodyMove(move bool) ([]*openapi3.OperationMeta, error) {
+	defer se.journalEdit("OperationsRequestBodyMove")()
 	errorOperations := []*openapi3.OperationMeta{}
 	if se.SpecMore.Spec == nil {
 		return errorOperations, openapi3.ErrSpecNotSet
@@ -166,6 +168,7 @@ func (se *SpecEdit) OperationsRequestBodyMove(move bool) ([]*openapi3.OperationM
 // with response schema types that are not `array` or `object`. If the responses
 // is a string or integer, it will reset the response mime type to `text/plain`.
 func (se *SpecEdit) ValidateFixOperationResponseTypes(fix bool) ([]*openapi3.OperationMeta, error) {
+	defer se.journalEdit("ValidateFixOperationResponseTypes")()
 	errorOperations := []*openapi3.OperationMeta{}
 	if se.SpecMore.Spec == nil {
 		return errorOperations, openapi3.ErrSpecNotSet
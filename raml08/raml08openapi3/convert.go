@@ -0,0 +1,359 @@
+package raml08openapi3
+
+import (
+	"fmt"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/mogo/errors/errorsutil"
+	"github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/grokify/spectrum/internal/ramlutil"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	RAMLKeyBaseURI           = "baseUri"
+	RAMLKeyBaseURIParameters = "baseUriParameters"
+	RAMLKeyBody              = "body"
+	RAMLKeyDocumentation     = "documentation"
+	RAMLKeyExample           = "example"
+	RAMLKeyFormParameters    = "formParameters"
+	RAMLKeyHeaders           = "headers"
+	RAMLKeyMediaType         = "mediaType"
+	RAMLKeyProtocols         = "protocols"
+	RAMLKeyQueryParameters   = "queryParameters"
+	RAMLKeyResponses         = "responses"
+	RAMLKeySchema            = "schema"
+	RAMLKeySchemas           = "schemas"
+	RAMLKeySecuredBy         = "securedBy"
+	RAMLKeySecuritySchemes   = "securitySchemes"
+	RAMLKeyTitle             = "title"
+	RAMLKeyURIParameters     = "uriParameters"
+	RAMLKeyVersion           = "version"
+)
+
+// converter holds the document level state used while walking resources.
+type converter struct {
+	doc           map[string]any
+	spec          *openapi3.Spec
+	mediaType     string
+	resourceTypes map[string]map[string]any
+	traits        map[string]map[string]any
+	schemaNames   map[string]int
+	securedBy     any
+}
+
+// ConvertDocument converts a parsed RAML v0.8 document, such as returned by
+// `ParseDocument`, into a complete OpenAPI 3 spec. Resource types and traits
+// are applied before resources are converted.
+func ConvertDocument(doc map[string]any) (*openapi3.Spec, error) {
+	if doc == nil {
+		return nil, ErrRAMLDocumentEmpty
+	}
+	title, _ := doc[RAMLKeyTitle].(string)
+	version := ""
+	if v, ok := doc[RAMLKeyVersion]; ok && v != nil {
+		version = fmt.Sprintf("%v", v)
+	}
+	c := &converter{
+		doc:         doc,
+		spec:        openapi3.NewSpec(openapi3.OASVersionDefault, title, version),
+		schemaNames: map[string]int{},
+		securedBy:   doc[RAMLKeySecuredBy]}
+	c.spec.Paths = oas3.NewPaths()
+	c.spec.Components = &oas3.Components{
+		Schemas: oas3.Schemas{}}
+	c.mediaType, _ = doc[RAMLKeyMediaType].(string)
+	c.mediaType = strings.TrimSpace(c.mediaType)
+
+	var err error
+	if c.resourceTypes, err = ramlutil.NamedTemplates(doc[RAMLKeyResourceType]); err != nil {
+		return nil, errorsutil.Wrap(err, "error reading raml resourceTypes")
+	}
+	if c.traits, err = ramlutil.NamedTemplates(doc[RAMLKeyTraits]); err != nil {
+		return nil, errorsutil.Wrap(err, "error reading raml traits")
+	}
+	c.convertInfo(version)
+	if err := c.convertSchemas(); err != nil {
+		return nil, err
+	}
+	if err := c.convertSecuritySchemes(); err != nil {
+		return nil, err
+	}
+	if c.securedBy != nil {
+		secReqs, err := ramlutil.SecurityRequirements(c.securedBy, c.spec.Components.SecuritySchemes)
+		if err != nil {
+			return nil, err
+		}
+		if secReqs != nil {
+			c.spec.Security = secReqs
+		}
+	}
+	rc := &ramlutil.ResourceConverter{
+		Spec:          c.spec,
+		ResourceTypes: c.resourceTypes,
+		Traits:        c.traits,
+		ConvertMethod: c.convertMethod}
+	if err := rc.ConvertResources("", doc, map[string]any{}); err != nil {
+		return nil, err
+	}
+	return c.spec, nil
+}
+
+func (c *converter) convertInfo(version string) {
+	if docs, ok := c.doc[RAMLKeyDocumentation].([]any); ok {
+		parts := []string{}
+		for _, docAny := range docs {
+			docMap, ok := docAny.(map[string]any)
+			if !ok {
+				continue
+			}
+			docTitle, _ := docMap[RAMLKeyTitle].(string)
+			docContent, _ := docMap["content"].(string)
+			parts = append(parts, strings.TrimSpace("## "+strings.TrimSpace(docTitle)+"\n\n"+strings.TrimSpace(docContent)))
+		}
+		c.spec.Info.Description = strings.Join(parts, "\n\n")
+	}
+	baseURI, _ := c.doc[RAMLKeyBaseURI].(string)
+	baseURI = strings.TrimSpace(baseURI)
+	if len(baseURI) == 0 {
+		return
+	}
+	baseURI = strings.ReplaceAll(baseURI, "{version}", version)
+	urls := []string{baseURI}
+	if protocols, ok := c.doc[RAMLKeyProtocols].([]any); ok && len(protocols) > 0 {
+		urls = []string{}
+		for _, protoAny := range protocols {
+			proto, ok := protoAny.(string)
+			if !ok {
+				continue
+			}
+			proto = strings.ToLower(strings.TrimSpace(proto))
+			if idx := strings.Index(baseURI, "://"); idx > -1 {
+				urls = append(urls, proto+baseURI[idx:])
+			} else {
+				urls = append(urls, proto+"://"+strings.TrimLeft(baseURI, "/"))
+			}
+		}
+	}
+	baseParams, _ := c.doc[RAMLKeyBaseURIParameters].(map[string]any)
+	for _, u := range urls {
+		svr := &oas3.Server{URL: u}
+		for _, varName := range openapi3.PathParams(u) {
+			svrVar := &oas3.ServerVariable{}
+			if np, err := namedParameterMap(baseParams[varName]); err == nil {
+				if def, ok := np["default"]; ok {
+					svrVar.Default = fmt.Sprintf("%v", def)
+				}
+				if enumAny, ok := np["enum"].([]any); ok {
+					for _, e := range enumAny {
+						svrVar.Enum = append(svrVar.Enum, fmt.Sprintf("%v", e))
+					}
+				}
+				svrVar.Description = namedParameterDescription(np)
+			}
+			if svr.Variables == nil {
+				svr.Variables = map[string]*oas3.ServerVariable{}
+			}
+			svr.Variables[varName] = svrVar
+		}
+		c.spec.Servers = append(c.spec.Servers, svr)
+	}
+}
+
+// convertSchemas adds the RAML `schemas` to `#/components/schemas`. Schemas
+// that are not JSON, such as XML Schema, are skipped.
+func (c *converter) convertSchemas() error {
+	schemas, err := ramlutil.NamedTemplatesAny(c.doc[RAMLKeySchemas])
+	if err != nil {
+		return errorsutil.Wrap(err, "error reading raml schemas")
+	}
+	for name, schAny := range schemas {
+		schStr, ok := schAny.(string)
+		if !ok || !isJSONObjectString(schStr) {
+			continue
+		}
+		sch, err := openapi3.SchemaFromJSONSchema([]byte(schStr))
+		if err != nil {
+			return errorsutil.Wrapf(err, "error parsing raml schema (%s)", name)
+		}
+		c.spec.Components.Schemas[name] = oas3.NewSchemaRef("", sch)
+		c.schemaNames[name]++
+	}
+	return nil
+}
+
+func isJSONObjectString(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), "{")
+}
+
+func (c *converter) convertMethod(resourcePath string, resource, methodMap, uriParams map[string]any) (*oas3.Operation, error) {
+	op := oas3.NewOperation()
+	if desc, ok := methodMap[RAMLKeyDescription].(string); ok {
+		op.Description = strings.TrimSpace(desc)
+	}
+	if dispName, ok := methodMap[RAMLKeyDisplayName].(string); ok {
+		op.Summary = strings.TrimSpace(dispName)
+	}
+	for _, varName := range openapi3.PathParams(resourcePath) {
+		np, err := namedParameterMap(uriParams[varName])
+		if err != nil {
+			return nil, err
+		}
+		op.Parameters = append(op.Parameters, &oas3.ParameterRef{
+			Value: NamedParameter(varName, openapi3.InPath, np, true)})
+	}
+	queryParams, err := namedParameters(openapi3.InQuery, methodMap[RAMLKeyQueryParameters], false)
+	if err != nil {
+		return nil, err
+	}
+	op.Parameters = append(op.Parameters, queryParams...)
+	headerParams, err := namedParameters(openapi3.InHeader, methodMap[RAMLKeyHeaders], false)
+	if err != nil {
+		return nil, err
+	}
+	op.Parameters = append(op.Parameters, headerParams...)
+
+	if bodyAny, ok := methodMap[RAMLKeyBody]; ok && bodyAny != nil {
+		content, err := c.convertBody(bodyAny)
+		if err != nil {
+			return nil, errorsutil.Wrap(err, "error converting request body")
+		}
+		if len(content) > 0 {
+			op.RequestBody = &oas3.RequestBodyRef{
+				Value: oas3.NewRequestBody().WithContent(content)}
+		}
+	}
+
+	resps, err := c.convertResponses(methodMap[RAMLKeyResponses])
+	if err != nil {
+		return nil, err
+	}
+	op.Responses = resps
+
+	securedBy := methodMap[RAMLKeySecuredBy]
+	if securedBy == nil {
+		securedBy = resource[RAMLKeySecuredBy]
+	}
+	if securedBy != nil {
+		secReqs, err := ramlutil.SecurityRequirements(securedBy, c.spec.Components.SecuritySchemes)
+		if err != nil {
+			return nil, err
+		}
+		if secReqs != nil {
+			op.Security = &secReqs
+		}
+	}
+	return op, nil
+}
+
+func (c *converter) convertResponses(val any) (*oas3.Responses, error) {
+	if val == nil {
+		return oas3.NewResponses(), nil
+	}
+	respsMap, ok := val.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("raml responses is not a map (%v)", val)
+	}
+	resps := oas3.NewResponsesWithCapacity(len(respsMap))
+	for status, respAny := range respsMap {
+		resp := oas3.NewResponse()
+		respMap, _ := respAny.(map[string]any)
+		desc, _ := respMap[RAMLKeyDescription].(string)
+		desc = strings.TrimSpace(desc)
+		if len(desc) == 0 {
+			desc = openapi3.ResponseDescriptionDefault(status)
+		}
+		resp.Description = &desc
+		if headers, ok := respMap[RAMLKeyHeaders].(map[string]any); ok {
+			resp.Headers = oas3.Headers{}
+			for name, npAny := range headers {
+				np, err := namedParameterMap(npAny)
+				if err != nil {
+					return nil, err
+				}
+				param := NamedParameter(name, openapi3.InHeader, np, false)
+				param.Name = ""
+				param.In = ""
+				resp.Headers[name] = &oas3.HeaderRef{Value: &oas3.Header{Parameter: *param}}
+			}
+		}
+		if bodyAny, ok := respMap[RAMLKeyBody]; ok && bodyAny != nil {
+			content, err := c.convertBody(bodyAny)
+			if err != nil {
+				return nil, errorsutil.Wrapf(err, "error converting response body for status (%s)", status)
+			}
+			if len(content) > 0 {
+				resp.Content = content
+			}
+		}
+		resps.Set(status, &oas3.ResponseRef{Value: resp})
+	}
+	return resps, nil
+}
+
+// convertBody converts a RAML body which is either a map of media types to
+// bodies or, when a default `mediaType` is set, a body directly.
+func (c *converter) convertBody(bodyAny any) (oas3.Content, error) {
+	content := oas3.NewContent()
+	bodyMap, ok := bodyAny.(map[string]any)
+	if !ok {
+		return content, fmt.Errorf("raml body is not a map (%v)", bodyAny)
+	}
+	isDirect := false
+	for k := range bodyMap {
+		if k == RAMLKeySchema || k == RAMLKeyExample || k == RAMLKeyFormParameters {
+			isDirect = true
+		}
+	}
+	if isDirect {
+		mt := c.mediaType
+		if len(mt) == 0 {
+			mt = httputilmore.ContentTypeAppJSON
+		}
+		bodyMap = map[string]any{mt: bodyMap}
+	}
+	for mt, mtBodyAny := range bodyMap {
+		mediaType := oas3.NewMediaType()
+		mtBody, _ := mtBodyAny.(map[string]any)
+		if schAny, ok := mtBody[RAMLKeySchema]; ok && schAny != nil {
+			schRef, err := c.schemaRef(schAny)
+			if err != nil {
+				return content, err
+			}
+			mediaType.Schema = schRef
+		}
+		if fpAny, ok := mtBody[RAMLKeyFormParameters]; ok && fpAny != nil {
+			sch, err := namedParametersSchema(fpAny)
+			if err != nil {
+				return content, err
+			}
+			mediaType.Schema = oas3.NewSchemaRef("", sch)
+		}
+		if exAny, ok := mtBody[RAMLKeyExample]; ok && exAny != nil {
+			mediaType.Example = ramlutil.ExampleValue(exAny)
+		}
+		content[mt] = mediaType
+	}
+	return content, nil
+}
+
+// schemaRef returns a reference when `schAny` is the name of a RAML schema,
+// otherwise it parses an inline JSON Schema. Non-JSON schemas return `nil`.
+func (c *converter) schemaRef(schAny any) (*oas3.SchemaRef, error) {
+	schStr, ok := schAny.(string)
+	if !ok {
+		return nil, fmt.Errorf("raml schema is not a string (%v)", schAny)
+	}
+	schStr = strings.TrimSpace(schStr)
+	if _, ok := c.schemaNames[schStr]; ok {
+		return oas3.NewSchemaRef(openapi3.SchemaPointerExpand("", schStr), nil), nil
+	} else if !isJSONObjectString(schStr) {
+		return nil, nil
+	}
+	sch, err := openapi3.SchemaFromJSONSchema([]byte(schStr))
+	if err != nil {
+		return nil, err
+	}
+	return oas3.NewSchemaRef("", sch), nil
+}
@@ -0,0 +1,191 @@
+package raml08openapi3
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const ramlTestSchemaUser = `{
+  "$schema": "http://json-schema.org/draft-03/schema",
+  "type": "object",
+  "properties": {
+    "id": {"type": "string", "required": true},
+    "name": {"type": "string"}
+  }
+}`
+
+const ramlTestSpec = `#%RAML 0.8
+title: Users API
+version: v1
+baseUri: https://api.example.com/{version}
+mediaType: application/json
+schemas:
+  - user: !include user.json
+securitySchemes:
+  - oauth_2_0:
+      type: OAuth 2.0
+      settings:
+        authorizationUri: https://example.com/authorize
+        accessTokenUri: https://example.com/token
+        authorizationGrants: [ code ]
+        scopes: [ read ]
+traits:
+  - pageable:
+      queryParameters:
+        limit:
+          type: integer
+          maximum: 100
+resourceTypes:
+  - collection:
+      description: Collection of <<resourcePathName>>
+      get:
+        is: [ pageable ]
+        displayName: List <<resourcePathName>>
+      post?:
+        displayName: Create <<resourcePathName | !singularize>>
+        body:
+          schema: <<item>>
+securedBy: [ oauth_2_0 ]
+/users:
+  type: { collection: { item: user } }
+  post:
+  /{userId}:
+    uriParameters:
+      userId:
+        type: string
+    get:
+      displayName: Get user
+      responses:
+        200:
+          body:
+            schema: user
+`
+
+var ramlConvertTests = []struct {
+	path        string
+	method      string
+	summary     string
+	paramsCount int
+}{
+	{"/users", "GET", "List users", 1},
+	{"/users", "POST", "Create user", 0},
+	{"/users/{userId}", "GET", "Get user", 1},
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "user.json"), []byte(ramlTestSchemaUser), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := Parse([]byte(ramlTestSpec), dir)
+	if err != nil {
+		t.Fatalf("raml08openapi3.Parse() error [%v]", err)
+	}
+	if len(spec.Servers) != 1 || spec.Servers[0].URL != "https://api.example.com/v1" {
+		t.Errorf("raml08openapi3.Parse() server mismatch")
+	}
+	userSchRef, ok := spec.Components.Schemas["user"]
+	if !ok || len(userSchRef.Value.Required) != 1 || userSchRef.Value.Required[0] != "id" {
+		t.Errorf("raml08openapi3.Parse() schema [user] mismatch")
+	}
+	if _, ok := spec.Components.SecuritySchemes["oauth_2_0"]; !ok {
+		t.Errorf("raml08openapi3.Parse() security scheme [oauth_2_0] missing")
+	}
+	for _, tt := range ramlConvertTests {
+		pathItem := spec.Paths.Find(tt.path)
+		if pathItem == nil {
+			t.Errorf("raml08openapi3.Parse() path missing [%s]", tt.path)
+			continue
+		}
+		op := pathItem.GetOperation(tt.method)
+		if op == nil {
+			t.Errorf("raml08openapi3.Parse() operation missing [%s %s]", tt.method, tt.path)
+			continue
+		}
+		if op.Summary != tt.summary || len(op.Parameters) != tt.paramsCount {
+			t.Errorf("raml08openapi3.Parse() [%s %s] mismatch: want [%s][%d], got [%s][%d]",
+				tt.method, tt.path, tt.summary, tt.paramsCount, op.Summary, len(op.Parameters))
+		}
+	}
+	post := spec.Paths.Find("/users").Post
+	if post.RequestBody == nil || post.RequestBody.Value.Content["application/json"].Schema.Ref != "#/components/schemas/user" {
+		t.Errorf("raml08openapi3.Parse() request body schema ref mismatch")
+	}
+}
+
+func TestParseDocumentIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.yaml":    "b: !include b.yaml",
+		"b.yaml":    "a: !include a.yaml",
+		"desc.yaml": "text: shared"}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := ParseDocument([]byte("#%RAML 0.8\ntitle: Cycle\nfoo: !include a.yaml"), dir)
+	if !errors.Is(err, ErrRAMLIncludeCycle) {
+		t.Errorf("raml08openapi3.ParseDocument() error mismatch: want [%v], got [%v]", ErrRAMLIncludeCycle, err)
+	}
+	_, err = ParseDocument([]byte("#%RAML 0.8\ntitle: Repeat\none: !include desc.yaml\ntwo: !include desc.yaml"), dir)
+	if err != nil {
+		t.Errorf("raml08openapi3.ParseDocument() error [%v]", err)
+	}
+}
+
+const ramlTestSpecDropped = `#%RAML 0.8
+title: Orders API
+schemas:
+  - order: |
+      {
+        "type": "object",
+        "properties": {
+          "total": {"type": "number", "required": true},
+          "id": {"type": "string", "required": true},
+          "note": {"type": "string"},
+          "currency": {"type": "string", "required": true}
+        }
+      }
+securitySchemes:
+  - basic:
+      type: Basic Authentication
+  - custom:
+      type: x-custom
+securedBy: [ custom ]
+/orders:
+  get:
+    securedBy: [ custom, basic ]
+    responses:
+      200:
+        body:
+          application/json:
+            schema: order
+`
+
+func TestParseDroppedAndStable(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		spec, err := Parse([]byte(ramlTestSpecDropped), t.TempDir())
+		if err != nil {
+			t.Fatalf("raml08openapi3.Parse() error [%v]", err)
+		}
+		want := []string{"currency", "id", "total"}
+		if got := spec.Components.Schemas["order"].Value.Required; !reflect.DeepEqual(got, want) {
+			t.Errorf("raml08openapi3.Parse() required mismatch: want [%v], got [%v]", want, got)
+		}
+		if _, ok := spec.Components.SecuritySchemes["custom"]; ok {
+			t.Errorf("raml08openapi3.Parse() security scheme [custom] not dropped")
+		}
+		if len(spec.Security) != 0 {
+			t.Errorf("raml08openapi3.Parse() root security mismatch: want [0], got [%d]", len(spec.Security))
+		}
+		op := spec.Paths.Find("/orders").Get
+		if op.Security == nil || len(*op.Security) != 1 || (*op.Security)[0]["basic"] == nil {
+			t.Errorf("raml08openapi3.Parse() operation security mismatch: want [basic], got [%v]", op.Security)
+		}
+	}
+}
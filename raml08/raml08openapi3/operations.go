@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	oas3 "github.com/getkin/kin-openapi/openapi3"
@@ -15,13 +16,22 @@ import (
 
 // ReadFileOperations reads a RAML v0.8 file and returns a set of `openapi3edit.OperationMore` structs.
 // The properties `path`, `method`, `summary`, `description` are populated. OpenAPI `summary` is populated
-// by the `displayName` property. Currently, this reads a JSON formatted file into a map[string]interface.
-// This is useful after converting a RAML v0.8 spec using https://github.com/daviemakz/oas-raml-converter-cli.
+// by the `displayName` property. RAML YAML files, identified by the `#%RAML 0.8` header, are converted
+// using `ReadFile`. JSON formatted files are read into a map[string]interface. This is useful after
+// converting a RAML v0.8 spec using https://github.com/daviemakz/oas-raml-converter-cli.
 func ReadFileOperations(filename string) (*openapi3.OperationMores, error) {
 	bytes, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
+	if strings.HasPrefix(strings.TrimSpace(string(bytes)), RAMLVersionHeader) {
+		spec, err := Parse(bytes, filepath.Dir(filename))
+		if err != nil {
+			return nil, err
+		}
+		sm := openapi3.SpecMore{Spec: spec}
+		return sm.Operations([]string{}), nil
+	}
 	msa := map[string]any{}
 	err = json.Unmarshal(bytes, &msa)
 	if err != nil {
@@ -0,0 +1,193 @@
+package raml08openapi3
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	RAMLTypeBoolean = "boolean"
+	RAMLTypeDate    = "date"
+	RAMLTypeFile    = "file"
+	RAMLTypeInteger = "integer"
+	RAMLTypeNumber  = "number"
+	RAMLTypeString  = "string"
+
+	FormatBinary = "binary"
+)
+
+// NamedParameterSchema converts a RAML v0.8 named parameter, as used for
+// `uriParameters`, `queryParameters`, `headers` and `formParameters`, into
+// an OpenAPI 3 schema. A `repeat: true` parameter becomes an array.
+func NamedParameterSchema(np map[string]any) *oas3.Schema {
+	sch := oas3.NewSchema()
+	ramlType, _ := np["type"].(string)
+	switch strings.ToLower(strings.TrimSpace(ramlType)) {
+	case RAMLTypeBoolean:
+		sch.Type = openapi3.TypeBoolean
+	case RAMLTypeInteger:
+		sch.Type = openapi3.TypeInteger
+	case RAMLTypeNumber:
+		sch.Type = "number"
+	case RAMLTypeDate:
+		// RAML v0.8 dates are RFC 2616 dates which do not match OpenAPI `date-time`.
+		sch.Type = openapi3.TypeString
+	case RAMLTypeFile:
+		sch.Type = openapi3.TypeString
+		sch.Format = FormatBinary
+	default:
+		sch.Type = openapi3.TypeString
+	}
+	if enumAny, ok := np["enum"].([]any); ok {
+		sch.Enum = enumAny
+	}
+	if pattern, ok := np["pattern"].(string); ok {
+		sch.Pattern = pattern
+	}
+	if v, ok := toFloat(np["minLength"]); ok && v >= 0 {
+		sch.MinLength = uint64(v)
+	}
+	if v, ok := toFloat(np["maxLength"]); ok && v >= 0 {
+		maxLength := uint64(v)
+		sch.MaxLength = &maxLength
+	}
+	if v, ok := toFloat(np["minimum"]); ok {
+		sch.Min = &v
+	}
+	if v, ok := toFloat(np["maximum"]); ok {
+		sch.Max = &v
+	}
+	if v, ok := np["default"]; ok {
+		sch.Default = v
+	}
+	if v, ok := np["example"]; ok {
+		sch.Example = v
+	}
+	if repeat, ok := np["repeat"].(bool); ok && repeat {
+		return &oas3.Schema{
+			Type:  openapi3.TypeArray,
+			Items: oas3.NewSchemaRef("", sch)}
+	}
+	return sch
+}
+
+// NamedParameter converts a RAML v0.8 named parameter into an OpenAPI 3
+// parameter. `in` is one of `path`, `query` or `header`. Path parameters are
+// always required. Otherwise `requiredDefault` is used if `required` is not set.
+func NamedParameter(name, in string, np map[string]any, requiredDefault bool) *oas3.Parameter {
+	if np == nil {
+		np = map[string]any{}
+	}
+	param := &oas3.Parameter{
+		Name:   name,
+		In:     in,
+		Schema: oas3.NewSchemaRef("", NamedParameterSchema(np))}
+	param.Description = namedParameterDescription(np)
+	param.Required = requiredDefault
+	if req, ok := np["required"].(bool); ok {
+		param.Required = req
+	}
+	if in == openapi3.InPath {
+		param.Required = true
+	}
+	if ex, ok := np["example"]; ok {
+		param.Example = ex
+		param.Schema.Value.Example = nil
+	}
+	return param
+}
+
+func namedParameterDescription(np map[string]any) string {
+	desc, _ := np["description"].(string)
+	desc = strings.TrimSpace(desc)
+	if len(desc) == 0 {
+		if dispName, ok := np["displayName"].(string); ok {
+			desc = strings.TrimSpace(dispName)
+		}
+	}
+	return desc
+}
+
+// namedParameters converts a map of named parameters into a sorted slice of
+// OpenAPI 3 parameter refs. RAML allows a parameter to be a list of alternate
+// definitions in which case the first definition is used.
+func namedParameters(in string, val any, requiredDefault bool) (oas3.Parameters, error) {
+	params := oas3.Parameters{}
+	if val == nil {
+		return params, nil
+	}
+	msa, ok := val.(map[string]any)
+	if !ok {
+		return params, fmt.Errorf("raml named parameters is not a map (%v)", val)
+	}
+	names := []string{}
+	for name := range msa {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		np, err := namedParameterMap(msa[name])
+		if err != nil {
+			return params, fmt.Errorf("raml named parameter (%s): %w", name, err)
+		}
+		params = append(params, &oas3.ParameterRef{
+			Value: NamedParameter(name, in, np, requiredDefault)})
+	}
+	return params, nil
+}
+
+func namedParameterMap(val any) (map[string]any, error) {
+	switch v := val.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case map[string]any:
+		return v, nil
+	case []any:
+		if len(v) == 0 {
+			return map[string]any{}, nil
+		}
+		return namedParameterMap(v[0])
+	}
+	return nil, fmt.Errorf("named parameter is not a map (%v)", val)
+}
+
+// namedParametersSchema converts `formParameters` into an object schema.
+func namedParametersSchema(val any) (*oas3.Schema, error) {
+	msa, ok := val.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("raml form parameters is not a map (%v)", val)
+	}
+	sch := oas3.NewObjectSchema()
+	for name, npAny := range msa {
+		np, err := namedParameterMap(npAny)
+		if err != nil {
+			return nil, err
+		}
+		propSch := NamedParameterSchema(np)
+		propSch.Description = namedParameterDescription(np)
+		sch.Properties[name] = oas3.NewSchemaRef("", propSch)
+		if req, ok := np["required"].(bool); ok && req {
+			sch.Required = append(sch.Required, name)
+		}
+	}
+	sort.Strings(sch.Required)
+	return sch, nil
+}
+
+func toFloat(val any) (float64, bool) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,51 @@
+package raml08openapi3
+
+import (
+	"github.com/grokify/spectrum/internal/ramlutil"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	RAMLVersionHeader = "#%RAML 0.8"
+	TagInclude        = ramlutil.TagInclude
+)
+
+var (
+	ErrRAMLDocumentEmpty   = ramlutil.ErrDocumentEmpty
+	ErrRAMLDocumentNotMap  = ramlutil.ErrDocumentNotMap
+	ErrRAMLIncludeNotFound = ramlutil.ErrIncludeNotFound
+	ErrRAMLIncludeCycle    = ramlutil.ErrIncludeCycle
+)
+
+// ReadFile reads a RAML v0.8 file and converts it to an OpenAPI 3 spec. `!include`
+// tags are resolved against the local filesystem relative to the including file.
+func ReadFile(filename string) (*openapi3.Spec, error) {
+	doc, err := ReadFileDocument(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ConvertDocument(doc)
+}
+
+// Parse converts RAML v0.8 YAML bytes to an OpenAPI 3 spec. `baseDir` is used to
+// resolve relative `!include` file paths.
+func Parse(data []byte, baseDir string) (*openapi3.Spec, error) {
+	doc, err := ParseDocument(data, baseDir)
+	if err != nil {
+		return nil, err
+	}
+	return ConvertDocument(doc)
+}
+
+// ReadFileDocument reads a RAML v0.8 file and returns it as a `map[string]any` with
+// all `!include` tags resolved.
+func ReadFileDocument(filename string) (map[string]any, error) {
+	return ramlutil.ReadFileDocument(filename)
+}
+
+// ParseDocument parses RAML v0.8 YAML bytes and returns it as a `map[string]any`
+// with all `!include` tags resolved. Map keys are always strings, including
+// response status codes.
+func ParseDocument(data []byte, baseDir string) (map[string]any, error) {
+	return ramlutil.ParseDocument(data, baseDir)
+}
@@ -0,0 +1,58 @@
+package raml08openapi3
+
+import (
+	"fmt"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/mogo/errors/errorsutil"
+	"github.com/grokify/spectrum/internal/ramlutil"
+)
+
+const (
+	RAMLSecurityTypeOAuth2 = ramlutil.SecurityTypeOAuth2
+	RAMLSecurityTypeOAuth1 = ramlutil.SecurityTypeOAuth1
+	RAMLSecurityTypeBasic  = ramlutil.SecurityTypeBasic
+	RAMLSecurityTypeDigest = ramlutil.SecurityTypeDigest
+
+	RAMLKeyDescribedBy = ramlutil.KeyDescribedBy
+	RAMLKeySettings    = ramlutil.KeySettings
+)
+
+// ramlGrantsToFlows maps RAML v0.8 `authorizationGrants` to OpenAPI 3 OAuth flows.
+var ramlGrantsToFlows = map[string]string{
+	"code":        "authorizationCode",
+	"token":       "implicit",
+	"owner":       "password",
+	"credentials": "clientCredentials"}
+
+func (c *converter) convertSecuritySchemes() error {
+	schemes, err := ramlutil.NamedTemplatesAny(c.doc[RAMLKeySecuritySchemes])
+	if err != nil {
+		return errorsutil.Wrap(err, "error reading raml securitySchemes")
+	}
+	for name, schemeAny := range schemes {
+		schemeMap, ok := schemeAny.(map[string]any)
+		if !ok {
+			return fmt.Errorf("raml security scheme is not a map (%s)", name)
+		}
+		scheme := SecurityScheme(schemeMap)
+		if scheme == nil {
+			continue
+		}
+		if c.spec.Components.SecuritySchemes == nil {
+			c.spec.Components.SecuritySchemes = oas3.SecuritySchemes{}
+		}
+		c.spec.Components.SecuritySchemes[name] = &oas3.SecuritySchemeRef{Value: scheme}
+	}
+	return nil
+}
+
+// SecurityScheme converts a RAML v0.8 security scheme to an OpenAPI 3 security
+// scheme. Custom `x-` schemes are converted to API keys when `describedBy`
+// declares exactly one header or query parameter. It returns `nil` if the
+// scheme cannot be represented.
+func SecurityScheme(schemeMap map[string]any) *oas3.SecurityScheme {
+	return ramlutil.SecurityScheme(schemeMap, ramlutil.SecuritySchemeOptions{
+		Grants:       ramlGrantsToFlows,
+		GrantDefault: "code"})
+}
@@ -0,0 +1,22 @@
+package raml08openapi3
+
+import "github.com/grokify/spectrum/internal/ramlutil"
+
+const (
+	RAMLKeyIs           = ramlutil.KeyIs
+	RAMLKeyResourceType = "resourceTypes"
+	RAMLKeyTraits       = "traits"
+	RAMLKeyType         = ramlutil.KeyType
+
+	ParamResourcePath     = ramlutil.ParamResourcePath
+	ParamResourcePathName = ramlutil.ParamResourcePathName
+	ParamMethodName       = ramlutil.ParamMethodName
+)
+
+// Singularize returns a simple English singular form as used by
+// the RAML `!singularize` template function.
+func Singularize(s string) string { return ramlutil.Singularize(s) }
+
+// Pluralize returns a simple English plural form as used by
+// the RAML `!pluralize` template function.
+func Pluralize(s string) string { return ramlutil.Pluralize(s) }
@@ -0,0 +1,562 @@
+package raml10openapi3
+
+import (
+	"fmt"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/mogo/errors/errorsutil"
+	"github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/grokify/spectrum/internal/ramlutil"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	RAMLKeyAnnotationTypes   = "annotationTypes"
+	RAMLKeyBaseURI           = "baseUri"
+	RAMLKeyBaseURIParameters = "baseUriParameters"
+	RAMLKeyBody              = "body"
+	RAMLKeyDescription       = "description"
+	RAMLKeyDisplayName       = "displayName"
+	RAMLKeyDocumentation     = "documentation"
+	RAMLKeyExample           = "example"
+	RAMLKeyExamples          = "examples"
+	RAMLKeyHeaders           = "headers"
+	RAMLKeyItems             = "items"
+	RAMLKeyMediaType         = "mediaType"
+	RAMLKeyProperties        = "properties"
+	RAMLKeyProtocols         = "protocols"
+	RAMLKeyQueryParameters   = "queryParameters"
+	RAMLKeyQueryString       = "queryString"
+	RAMLKeyRequired          = "required"
+	RAMLKeyResponses         = "responses"
+	RAMLKeySchema            = "schema"
+	RAMLKeySchemas           = "schemas"
+	RAMLKeySecuredBy         = "securedBy"
+	RAMLKeySecuritySchemes   = "securitySchemes"
+	RAMLKeyTitle             = "title"
+	RAMLKeyTypes             = "types"
+	RAMLKeyURIParameters     = "uriParameters"
+	RAMLKeyUses              = "uses"
+	RAMLKeyVersion           = "version"
+)
+
+// converter holds the document level state used while walking resources.
+// Types, resource types, traits and security schemes declared in libraries
+// are stored under their qualified names, e.g. `lib.Person`.
+type converter struct {
+	doc           map[string]any
+	spec          *openapi3.Spec
+	issues        Issues
+	mediaTypes    []string
+	types         map[string]any
+	typeNS        map[string]string
+	resourceTypes map[string]map[string]any
+	traits        map[string]map[string]any
+	schemes       map[string]map[string]any
+}
+
+// ConvertDocument converts a parsed RAML 1.0 document, such as returned by
+// `ParseDocument`, into a complete OpenAPI 3 spec. Data types, including those
+// from libraries, are added to `#/components/schemas`. Constructs that cannot
+// be represented in OpenAPI 3 are returned as `Issues`.
+func ConvertDocument(doc map[string]any) (*openapi3.Spec, Issues, error) {
+	if doc == nil {
+		return nil, Issues{}, ErrRAMLDocumentEmpty
+	}
+	title, _ := doc[RAMLKeyTitle].(string)
+	version := ""
+	if v, ok := doc[RAMLKeyVersion]; ok && v != nil {
+		version = fmt.Sprintf("%v", v)
+	}
+	c := &converter{
+		doc:           doc,
+		spec:          openapi3.NewSpec(openapi3.OASVersionDefault, title, version),
+		issues:        Issues{},
+		types:         map[string]any{},
+		typeNS:        map[string]string{},
+		resourceTypes: map[string]map[string]any{},
+		traits:        map[string]map[string]any{},
+		schemes:       map[string]map[string]any{}}
+	c.spec.Paths = oas3.NewPaths()
+	c.spec.Components = &oas3.Components{
+		Schemas: oas3.Schemas{}}
+	switch mt := doc[RAMLKeyMediaType].(type) {
+	case string:
+		c.mediaTypes = []string{strings.TrimSpace(mt)}
+	case []any:
+		for _, mtAny := range mt {
+			if mtStr, ok := mtAny.(string); ok {
+				c.mediaTypes = append(c.mediaTypes, strings.TrimSpace(mtStr))
+			}
+		}
+	}
+	if len(c.mediaTypes) == 0 {
+		c.mediaTypes = []string{httputilmore.ContentTypeAppJSON}
+	}
+
+	if err := c.loadDeclarations("", doc); err != nil {
+		return nil, c.issues, err
+	}
+	c.convertInfo(version)
+	c.convertTypes()
+	c.convertSecuritySchemes()
+	if securedBy, ok := doc[RAMLKeySecuredBy]; ok && securedBy != nil {
+		secReqs, err := ramlutil.SecurityRequirements(securedBy, c.spec.Components.SecuritySchemes)
+		if err != nil {
+			return nil, c.issues, err
+		}
+		if secReqs != nil {
+			c.spec.Security = secReqs
+		}
+	}
+	rc := &ramlutil.ResourceConverter{
+		Spec:               c.spec,
+		ResourceTypes:      c.resourceTypes,
+		Traits:             c.traits,
+		TemplateKeysIgnore: []string{RAMLKeyUsage},
+		ConvertMethod:      c.convertMethod,
+		PathItem: func(pathItem *oas3.PathItem, resource map[string]any) {
+			c.applyAnnotations(&pathItem.Extensions, resource)
+		}}
+	if err := rc.ConvertResources("", doc, map[string]any{}); err != nil {
+		return nil, c.issues, err
+	}
+	c.applyAnnotations(&c.spec.Extensions, doc)
+	if _, ok := doc[RAMLKeyAnnotationTypes]; ok {
+		c.issues.Add("/"+RAMLKeyAnnotationTypes, "annotation type declarations are not represented; annotations are mapped to `x-` extensions")
+	}
+	return c.spec, c.issues, nil
+}
+
+// loadDeclarations registers the types, resource types, traits and security
+// schemes of a document or library under the namespace `ns`, recursing into
+// the libraries it uses.
+func (c *converter) loadDeclarations(ns string, doc map[string]any) error {
+	qualify := func(name string) string {
+		if len(ns) == 0 {
+			return name
+		}
+		return ns + "." + name
+	}
+	if uses, ok := doc[RAMLKeyUses].(map[string]any); ok {
+		for _, name := range sortedKeys(uses) {
+			lib, ok := uses[name].(map[string]any)
+			if !ok {
+				return fmt.Errorf("raml library not loaded (%s)", qualify(name))
+			}
+			if err := c.loadDeclarations(qualify(name), lib); err != nil {
+				return err
+			}
+		}
+	}
+	for _, key := range []string{RAMLKeySchemas, RAMLKeyTypes} {
+		types, err := ramlutil.NamedTemplatesAny(doc[key])
+		if err != nil {
+			return errorsutil.Wrapf(err, "error reading raml %s", key)
+		}
+		for name, decl := range types {
+			c.types[qualify(name)] = decl
+			c.typeNS[qualify(name)] = ns
+		}
+	}
+	resourceTypes, err := ramlutil.NamedTemplates(doc[RAMLKeyResourceType])
+	if err != nil {
+		return errorsutil.Wrap(err, "error reading raml resourceTypes")
+	}
+	for name, tmpl := range resourceTypes {
+		c.resourceTypes[qualify(name)] = tmpl
+	}
+	traits, err := ramlutil.NamedTemplates(doc[RAMLKeyTraits])
+	if err != nil {
+		return errorsutil.Wrap(err, "error reading raml traits")
+	}
+	for name, tmpl := range traits {
+		c.traits[qualify(name)] = tmpl
+	}
+	schemes, err := ramlutil.NamedTemplates(doc[RAMLKeySecuritySchemes])
+	if err != nil {
+		return errorsutil.Wrap(err, "error reading raml securitySchemes")
+	}
+	for name, scheme := range schemes {
+		c.schemes[qualify(name)] = scheme
+	}
+	return nil
+}
+
+// convertTypes adds all declared data types to `#/components/schemas` using
+// their qualified names.
+func (c *converter) convertTypes() {
+	for _, name := range sortedKeys(c.types) {
+		c.spec.Components.Schemas[name] = c.typeSchemaRef(c.types[name], c.typeNS[name], "/types/"+name)
+	}
+}
+
+func (c *converter) convertInfo(version string) {
+	parts := []string{}
+	if desc, ok := c.doc[RAMLKeyDescription].(string); ok {
+		parts = append(parts, strings.TrimSpace(desc))
+	}
+	if docs, ok := c.doc[RAMLKeyDocumentation].([]any); ok {
+		for _, docAny := range docs {
+			docMap, ok := docAny.(map[string]any)
+			if !ok {
+				continue
+			}
+			docTitle, _ := docMap[RAMLKeyTitle].(string)
+			docContent, _ := docMap["content"].(string)
+			parts = append(parts, strings.TrimSpace("## "+strings.TrimSpace(docTitle)+"\n\n"+strings.TrimSpace(docContent)))
+		}
+	}
+	c.spec.Info.Description = strings.Join(parts, "\n\n")
+	baseURI, _ := c.doc[RAMLKeyBaseURI].(string)
+	baseURI = strings.TrimSpace(baseURI)
+	if len(baseURI) == 0 {
+		return
+	}
+	baseURI = strings.ReplaceAll(baseURI, "{version}", version)
+	urls := []string{baseURI}
+	if protocols, ok := c.doc[RAMLKeyProtocols].([]any); ok && len(protocols) > 0 {
+		urls = []string{}
+		for _, protoAny := range protocols {
+			proto, ok := protoAny.(string)
+			if !ok {
+				continue
+			}
+			proto = strings.ToLower(strings.TrimSpace(proto))
+			if idx := strings.Index(baseURI, "://"); idx > -1 {
+				urls = append(urls, proto+baseURI[idx:])
+			} else {
+				urls = append(urls, proto+"://"+strings.TrimLeft(baseURI, "/"))
+			}
+		}
+	}
+	baseParams, _ := c.doc[RAMLKeyBaseURIParameters].(map[string]any)
+	for _, u := range urls {
+		svr := &oas3.Server{URL: u}
+		for _, varName := range openapi3.PathParams(u) {
+			svrVar := &oas3.ServerVariable{}
+			if np, ok := baseParams[varName].(map[string]any); ok {
+				if def, ok := np["default"]; ok {
+					svrVar.Default = fmt.Sprintf("%v", def)
+				}
+				if enumAny, ok := np["enum"].([]any); ok {
+					for _, e := range enumAny {
+						svrVar.Enum = append(svrVar.Enum, fmt.Sprintf("%v", e))
+					}
+				}
+				svrVar.Description, _ = np[RAMLKeyDescription].(string)
+				svrVar.Description = strings.TrimSpace(svrVar.Description)
+			}
+			if svr.Variables == nil {
+				svr.Variables = map[string]*oas3.ServerVariable{}
+			}
+			svr.Variables[varName] = svrVar
+		}
+		c.spec.Servers = append(c.spec.Servers, svr)
+	}
+}
+
+func (c *converter) convertMethod(resourcePath string, resource, methodMap, uriParams map[string]any) (*oas3.Operation, error) {
+	op := oas3.NewOperation()
+	loc := "/resources" + resourcePath
+	if desc, ok := methodMap[RAMLKeyDescription].(string); ok {
+		op.Description = strings.TrimSpace(desc)
+	}
+	if dispName, ok := methodMap[RAMLKeyDisplayName].(string); ok {
+		op.Summary = strings.TrimSpace(dispName)
+	}
+	for _, varName := range openapi3.PathParams(resourcePath) {
+		op.Parameters = append(op.Parameters, &oas3.ParameterRef{
+			Value: c.parameter(varName, openapi3.InPath, uriParams[varName], loc+"/uriParameters/"+varName)})
+	}
+	queryParams, err := c.parameters(openapi3.InQuery, methodMap[RAMLKeyQueryParameters], loc+"/queryParameters")
+	if err != nil {
+		return nil, err
+	}
+	op.Parameters = append(op.Parameters, queryParams...)
+	if qsAny, ok := methodMap[RAMLKeyQueryString]; ok && qsAny != nil {
+		op.Parameters = append(op.Parameters, c.queryStringParameters(qsAny, loc+"/queryString")...)
+	}
+	headerParams, err := c.parameters(openapi3.InHeader, methodMap[RAMLKeyHeaders], loc+"/headers")
+	if err != nil {
+		return nil, err
+	}
+	op.Parameters = append(op.Parameters, headerParams...)
+	if _, ok := methodMap[RAMLKeyProtocols]; ok {
+		c.issues.Add(loc+"/"+RAMLKeyProtocols, "method level `protocols` cannot be represented in OpenAPI 3")
+	}
+
+	if bodyAny, ok := methodMap[RAMLKeyBody]; ok && bodyAny != nil {
+		content := c.convertBody(bodyAny, loc+"/body")
+		if len(content) > 0 {
+			op.RequestBody = &oas3.RequestBodyRef{
+				Value: oas3.NewRequestBody().WithContent(content)}
+		}
+	}
+
+	resps, err := c.convertResponses(methodMap[RAMLKeyResponses], loc+"/responses")
+	if err != nil {
+		return nil, err
+	}
+	op.Responses = resps
+
+	securedBy := methodMap[RAMLKeySecuredBy]
+	if securedBy == nil {
+		securedBy = resource[RAMLKeySecuredBy]
+	}
+	if securedBy != nil {
+		secReqs, err := ramlutil.SecurityRequirements(securedBy, c.spec.Components.SecuritySchemes)
+		if err != nil {
+			return nil, err
+		}
+		if secReqs != nil {
+			op.Security = &secReqs
+		}
+	}
+	c.applyAnnotations(&op.Extensions, methodMap)
+	return op, nil
+}
+
+// parameters converts a map of RAML 1.0 parameter type declarations into a
+// sorted slice of OpenAPI 3 parameter refs.
+func (c *converter) parameters(in string, val any, loc string) (oas3.Parameters, error) {
+	params := oas3.Parameters{}
+	if val == nil {
+		return params, nil
+	}
+	msa, ok := val.(map[string]any)
+	if !ok {
+		return params, fmt.Errorf("raml parameters is not a map (%v)", val)
+	}
+	for _, key := range sortedKeys(msa) {
+		params = append(params, &oas3.ParameterRef{
+			Value: c.parameter(key, in, msa[key], loc+"/"+key)})
+	}
+	return params, nil
+}
+
+// parameter converts a RAML 1.0 parameter type declaration. Parameters are
+// required unless the name ends in `?` or `required: false` is set. Path
+// parameters are always required.
+func (c *converter) parameter(key, in string, decl any, loc string) *oas3.Parameter {
+	param := &oas3.Parameter{
+		Name:     strings.TrimSuffix(key, "?"),
+		In:       in,
+		Required: !strings.HasSuffix(key, "?")}
+	if declMap, ok := decl.(map[string]any); ok {
+		declMap = ramlutil.DeepCopy(declMap).(map[string]any)
+		if req, ok := declMap[RAMLKeyRequired].(bool); ok {
+			param.Required = req
+		}
+		if desc, ok := declMap[RAMLKeyDescription].(string); ok {
+			param.Description = strings.TrimSpace(desc)
+		}
+		if ex, ok := declMap[RAMLKeyExample]; ok {
+			param.Example = exampleValue(ex)
+		} else if exs := examplesMap(declMap[RAMLKeyExamples]); len(exs) > 0 {
+			param.Examples = oas3.Examples{}
+			for name, ex := range exs {
+				param.Examples[name] = &oas3.ExampleRef{Value: oas3.NewExample(ex)}
+			}
+		}
+		for _, k := range []string{RAMLKeyRequired, RAMLKeyDescription, RAMLKeyExample, RAMLKeyExamples} {
+			delete(declMap, k)
+		}
+		decl = declMap
+	}
+	if in == openapi3.InPath {
+		param.Required = true
+	}
+	param.Schema = c.typeSchemaRef(decl, "", loc)
+	return param
+}
+
+// queryStringParameters converts a `queryString` object type into query
+// parameters, one per property.
+func (c *converter) queryStringParameters(qsAny any, loc string) oas3.Parameters {
+	params := oas3.Parameters{}
+	qsMap, _ := qsAny.(map[string]any)
+	typeName, _ := qsAny.(string)
+	if qsMap != nil && qsMap[RAMLKeyProperties] == nil {
+		typeName, _ = qsMap[RAMLKeyType].(string)
+	}
+	if qname, ok := c.resolveType(strings.TrimSpace(typeName), ""); ok {
+		qsMap, _ = c.types[qname].(map[string]any)
+	}
+	props, ok := qsMap[RAMLKeyProperties].(map[string]any)
+	if !ok {
+		c.issues.Add(loc, "`queryString` is not an object type with properties and cannot be mapped to parameters")
+		return params
+	}
+	for _, key := range sortedKeys(props) {
+		params = append(params, &oas3.ParameterRef{
+			Value: c.parameter(key, openapi3.InQuery, props[key], loc+"/"+key)})
+	}
+	return params
+}
+
+func (c *converter) convertResponses(val any, loc string) (*oas3.Responses, error) {
+	if val == nil {
+		return oas3.NewResponses(), nil
+	}
+	respsMap, ok := val.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("raml responses is not a map (%v)", val)
+	}
+	resps := oas3.NewResponsesWithCapacity(len(respsMap))
+	for _, status := range sortedKeys(respsMap) {
+		resp := oas3.NewResponse()
+		respMap, _ := respsMap[status].(map[string]any)
+		desc, _ := respMap[RAMLKeyDescription].(string)
+		desc = strings.TrimSpace(desc)
+		if len(desc) == 0 {
+			desc = openapi3.ResponseDescriptionDefault(status)
+		}
+		resp.Description = &desc
+		if headers, ok := respMap[RAMLKeyHeaders].(map[string]any); ok {
+			resp.Headers = oas3.Headers{}
+			for _, key := range sortedKeys(headers) {
+				param := c.parameter(key, openapi3.InHeader, headers[key], loc+"/"+status+"/headers/"+key)
+				name := param.Name
+				param.Name = ""
+				param.In = ""
+				resp.Headers[name] = &oas3.HeaderRef{Value: &oas3.Header{Parameter: *param}}
+			}
+		}
+		if bodyAny, ok := respMap[RAMLKeyBody]; ok && bodyAny != nil {
+			content := c.convertBody(bodyAny, loc+"/"+status+"/body")
+			if len(content) > 0 {
+				resp.Content = content
+			}
+		}
+		c.applyAnnotations(&resp.Extensions, respMap)
+		resps.Set(status, &oas3.ResponseRef{Value: resp})
+	}
+	return resps, nil
+}
+
+// convertBody converts a RAML body which is either a map of media types to
+// type declarations or, using the default media types, a type declaration.
+func (c *converter) convertBody(bodyAny any, loc string) oas3.Content {
+	content := oas3.NewContent()
+	bodyMap, ok := bodyAny.(map[string]any)
+	isDirect := !ok
+	if ok {
+		isDirect = true
+		for k := range bodyMap {
+			if strings.Contains(k, "/") {
+				isDirect = false
+			}
+		}
+	}
+	if isDirect {
+		bodyMap = map[string]any{}
+		for _, mt := range c.mediaTypes {
+			bodyMap[mt] = bodyAny
+		}
+	}
+	for _, mt := range sortedKeys(bodyMap) {
+		content[mt] = c.mediaType(bodyMap[mt], loc+"/"+mt)
+	}
+	return content
+}
+
+// mediaType converts a body type declaration. Examples are set on the media
+// type instead of the schema. RAML bodies default to type `any` so a schema
+// is only set when the declaration has a type.
+func (c *converter) mediaType(decl any, loc string) *oas3.MediaType {
+	mediaType := oas3.NewMediaType()
+	declMap, ok := decl.(map[string]any)
+	if !ok {
+		if decl != nil {
+			mediaType.Schema = c.typeSchemaRef(decl, "", loc)
+		}
+		return mediaType
+	}
+	declMap = ramlutil.DeepCopy(declMap).(map[string]any)
+	if ex, ok := declMap[RAMLKeyExample]; ok {
+		mediaType.Example = exampleValue(ex)
+	}
+	if exsAny, ok := declMap[RAMLKeyExamples]; ok {
+		mediaType.Examples = oas3.Examples{}
+		exMaps, _ := exsAny.(map[string]any)
+		for name, val := range examplesMap(exsAny) {
+			ex := oas3.NewExample(val)
+			if exMap, ok := exMaps[name].(map[string]any); ok && isExampleSpec(exMap) {
+				ex.Summary, _ = exMap[RAMLKeyDisplayName].(string)
+				ex.Description, _ = exMap[RAMLKeyDescription].(string)
+			}
+			mediaType.Examples[name] = &oas3.ExampleRef{Value: ex}
+		}
+	}
+	delete(declMap, RAMLKeyExample)
+	delete(declMap, RAMLKeyExamples)
+	hasType := false
+	for _, k := range []string{RAMLKeyType, RAMLKeySchema, RAMLKeyProperties, RAMLKeyItems} {
+		if _, ok := declMap[k]; ok {
+			hasType = true
+		}
+	}
+	if hasType {
+		mediaType.Schema = c.typeSchemaRef(declMap, "", loc)
+	}
+	return mediaType
+}
+
+// applyAnnotations maps RAML 1.0 annotations, keys like `(name)`, to
+// `x-name` extensions.
+func (c *converter) applyAnnotations(exts *map[string]any, msa map[string]any) {
+	for k, v := range msa {
+		if strings.HasPrefix(k, "(") && strings.HasSuffix(k, ")") && len(k) > 2 {
+			setExtension(exts, "x-"+strings.TrimSpace(k[1:len(k)-1]), v)
+		}
+	}
+}
+
+func setExtension(exts *map[string]any, key string, val any) {
+	if *exts == nil {
+		*exts = map[string]any{}
+	}
+	(*exts)[key] = val
+}
+
+// isExampleSpec returns true when an example is the expanded form with a
+// `value` key, rather than the example value itself.
+func isExampleSpec(exMap map[string]any) bool {
+	_, ok := exMap["value"]
+	if !ok {
+		return false
+	}
+	for k := range exMap {
+		switch {
+		case k == "value", k == RAMLKeyDisplayName, k == RAMLKeyDescription, k == "strict":
+		case strings.HasPrefix(k, "("):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// exampleValue returns the example value, unwrapping the expanded
+// `value` form and parsing JSON strings.
+func exampleValue(exAny any) any {
+	if exMap, ok := exAny.(map[string]any); ok && isExampleSpec(exMap) {
+		exAny = exMap["value"]
+	}
+	return ramlutil.ExampleValue(exAny)
+}
+
+// examplesMap returns the values of a RAML 1.0 `examples` map.
+func examplesMap(val any) map[string]any {
+	out := map[string]any{}
+	exs, ok := val.(map[string]any)
+	if !ok {
+		return out
+	}
+	for name, exAny := range exs {
+		out[name] = exampleValue(exAny)
+	}
+	return out
+}
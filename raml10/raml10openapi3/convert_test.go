@@ -0,0 +1,209 @@
+package raml10openapi3
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const ramlTestLibrary = `#%RAML 1.0 Library
+types:
+  Address:
+    properties:
+      street: string
+      city?: string
+`
+
+const ramlTestSpec = `#%RAML 1.0
+title: Users API
+version: v1
+baseUri: https://api.example.com/{version}
+mediaType: application/json
+uses:
+  common: common.raml
+(owner): platform-team
+types:
+  User:
+    type: object
+    properties:
+      id:
+        type: string
+        pattern: ^[a-z0-9]+$
+      email?: string
+      address: common.Address
+      tags: string[]
+      manager: User | nil
+      created: datetime
+      size:
+        type: integer
+        format: int64
+        facets:
+          unit: string
+  Admin:
+    type: User
+    properties:
+      level: integer
+securitySchemes:
+  oauth_2_0:
+    type: OAuth 2.0
+    settings:
+      authorizationUri: https://example.com/authorize
+      accessTokenUri: https://example.com/token
+      authorizationGrants: [ authorization_code, urn:custom ]
+      scopes: [ read ]
+traits:
+  pageable:
+    queryParameters:
+      limit?:
+        type: integer
+        maximum: 100
+resourceTypes:
+  collection:
+    usage: Collections of items
+    description: Collection of <<resourcePathName>>
+    get:
+      is: [ pageable ]
+      displayName: List <<resourcePathName>>
+    post?:
+      displayName: Create <<resourcePathName | !singularize | !uppercamelcase>>
+      body:
+        type: <<item>>
+securedBy: [ oauth_2_0 ]
+/users:
+  type: { collection: { item: User } }
+  post:
+  /{userId}:
+    uriParameters:
+      userId: string
+    get:
+      (internal): true
+      displayName: Get user
+      responses:
+        200:
+          body:
+            application/json:
+              type: User
+              examples:
+                basic:
+                  displayName: Basic user
+                  value: { "id": "abc" }
+`
+
+var ramlConvertTests = []struct {
+	path        string
+	method      string
+	summary     string
+	paramsCount int
+}{
+	{"/users", "GET", "List users", 1},
+	{"/users", "POST", "Create User", 0},
+	{"/users/{userId}", "GET", "Get user", 1},
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "common.raml"), []byte(ramlTestLibrary), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, issues, err := Parse([]byte(ramlTestSpec), dir)
+	if err != nil {
+		t.Fatalf("raml10openapi3.Parse() error [%v]", err)
+	}
+	if len(spec.Servers) != 1 || spec.Servers[0].URL != "https://api.example.com/v1" {
+		t.Errorf("raml10openapi3.Parse() server mismatch")
+	}
+	if spec.Extensions["x-owner"] != "platform-team" {
+		t.Errorf("raml10openapi3.Parse() root annotation [x-owner] missing")
+	}
+	if len(issues) != 2 {
+		t.Errorf("raml10openapi3.Parse() issues mismatch: want [2], got [%d] [%s]", len(issues), issues.String())
+	}
+	addrSchRef, ok := spec.Components.Schemas["common.Address"]
+	if !ok || len(addrSchRef.Value.Required) != 1 || addrSchRef.Value.Required[0] != "street" {
+		t.Errorf("raml10openapi3.Parse() library schema [common.Address] mismatch")
+	}
+	userSchRef, ok := spec.Components.Schemas["User"]
+	if !ok {
+		t.Fatalf("raml10openapi3.Parse() schema [User] missing")
+	}
+	user := userSchRef.Value
+	if len(user.Required) != 6 || user.Properties["address"].Ref != "#/components/schemas/common.Address" {
+		t.Errorf("raml10openapi3.Parse() schema [User] properties mismatch")
+	}
+	if mgr := user.Properties["manager"].Value; mgr == nil || !mgr.Nullable || len(mgr.AllOf) != 1 {
+		t.Errorf("raml10openapi3.Parse() schema [User.manager] nullable union mismatch")
+	}
+	if user.Properties["tags"].Value.Type != "array" || user.Properties["created"].Value.Format != "date-time" {
+		t.Errorf("raml10openapi3.Parse() schema [User] array or datetime mismatch")
+	}
+	if admin := spec.Components.Schemas["Admin"].Value; len(admin.AllOf) != 2 || admin.AllOf[0].Ref != "#/components/schemas/User" {
+		t.Errorf("raml10openapi3.Parse() schema [Admin] inheritance mismatch")
+	}
+	for _, tt := range ramlConvertTests {
+		pathItem := spec.Paths.Find(tt.path)
+		if pathItem == nil {
+			t.Errorf("raml10openapi3.Parse() path missing [%s]", tt.path)
+			continue
+		}
+		op := pathItem.GetOperation(tt.method)
+		if op == nil {
+			t.Errorf("raml10openapi3.Parse() operation missing [%s %s]", tt.method, tt.path)
+			continue
+		}
+		if op.Summary != tt.summary || len(op.Parameters) != tt.paramsCount {
+			t.Errorf("raml10openapi3.Parse() [%s %s] mismatch: want [%s][%d], got [%s][%d]",
+				tt.method, tt.path, tt.summary, tt.paramsCount, op.Summary, len(op.Parameters))
+		}
+	}
+	get := spec.Paths.Find("/users/{userId}").Get
+	if get.Extensions["x-internal"] != true {
+		t.Errorf("raml10openapi3.Parse() operation annotation [x-internal] missing")
+	}
+	mt := get.Responses.Value("200").Value.Content["application/json"]
+	if mt.Schema.Ref != "#/components/schemas/User" || mt.Examples["basic"] == nil || mt.Examples["basic"].Value.Summary != "Basic user" {
+		t.Errorf("raml10openapi3.Parse() response body mismatch")
+	}
+	if limit := spec.Paths.Find("/users").Get.Parameters[0].Value; limit.Required {
+		t.Errorf("raml10openapi3.Parse() optional query parameter [limit] is required")
+	}
+}
+
+var parseTypeExprTests = []struct {
+	expr  string
+	valid bool
+}{
+	{"string", true},
+	{"(A | B)[]", true},
+	{"lib.Person[][] | nil", true},
+	{"A |", false},
+	{"(A | B", false},
+}
+
+func TestParseTypeExpr(t *testing.T) {
+	for _, tt := range parseTypeExprTests {
+		_, err := parseTypeExpr(tt.expr)
+		if (err == nil) != tt.valid {
+			t.Errorf("raml10openapi3.parseTypeExpr(\"%s\") mismatch: want valid [%v], got error [%v]",
+				tt.expr, tt.valid, err)
+		}
+	}
+}
+
+func TestReadFileDocumentUsesCycle(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"api.raml": "#%RAML 1.0\ntitle: Cycle\nuses:\n  a: a.raml",
+		"a.raml":   "#%RAML 1.0 Library\nuses:\n  b: b.raml",
+		"b.raml":   "#%RAML 1.0 Library\nuses:\n  a: a.raml"}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := ReadFileDocument(filepath.Join(dir, "api.raml"))
+	if !errors.Is(err, ErrRAMLIncludeCycle) {
+		t.Errorf("raml10openapi3.ReadFileDocument() error mismatch: want [%v], got [%v]", ErrRAMLIncludeCycle, err)
+	}
+}
@@ -0,0 +1,37 @@
+package raml10openapi3
+
+import (
+	"strings"
+)
+
+// Issue describes a RAML 1.0 construct that could not be fully mapped to
+// OpenAPI 3. `Location` is a slash delimited path in the RAML document,
+// e.g. `/types/User/properties/name`.
+type Issue struct {
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+func (iss Issue) String() string {
+	return iss.Location + ": " + iss.Message
+}
+
+// Issues is a list of conversion issues in document order.
+type Issues []Issue
+
+func (iss *Issues) Add(location, message string) {
+	*iss = append(*iss, Issue{Location: location, Message: message})
+}
+
+// Strings returns the issues as `location: message` strings.
+func (iss Issues) Strings() []string {
+	out := []string{}
+	for _, is := range iss {
+		out = append(out, is.String())
+	}
+	return out
+}
+
+func (iss Issues) String() string {
+	return strings.Join(iss.Strings(), "\n")
+}
@@ -0,0 +1,114 @@
+package raml10openapi3
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grokify/mogo/errors/errorsutil"
+	"github.com/grokify/spectrum/internal/ramlutil"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	RAMLVersionHeader = "#%RAML 1.0"
+	TagInclude        = ramlutil.TagInclude
+)
+
+var (
+	ErrRAMLDocumentEmpty   = ramlutil.ErrDocumentEmpty
+	ErrRAMLDocumentNotMap  = ramlutil.ErrDocumentNotMap
+	ErrRAMLIncludeNotFound = ramlutil.ErrIncludeNotFound
+	ErrRAMLIncludeCycle    = ramlutil.ErrIncludeCycle
+)
+
+// ReadFile reads a RAML 1.0 file and converts it to an OpenAPI 3 spec. `!include`
+// tags and `uses` libraries are resolved against the local filesystem relative to
+// the including file. RAML constructs that cannot be mapped are returned as `Issues`.
+func ReadFile(filename string) (*openapi3.Spec, Issues, error) {
+	doc, err := ReadFileDocument(filename)
+	if err != nil {
+		return nil, Issues{}, err
+	}
+	return ConvertDocument(doc)
+}
+
+// Parse converts RAML 1.0 YAML bytes to an OpenAPI 3 spec. `baseDir` is used to
+// resolve relative `!include` and `uses` file paths.
+func Parse(data []byte, baseDir string) (*openapi3.Spec, Issues, error) {
+	doc, err := ParseDocument(data, baseDir)
+	if err != nil {
+		return nil, Issues{}, err
+	}
+	return ConvertDocument(doc)
+}
+
+// ReadFileDocument reads a RAML 1.0 file and returns it as a `map[string]any` with
+// all `!include` tags resolved.
+func ReadFileDocument(filename string) (map[string]any, error) {
+	return readFileDocument(filename, map[string]bool{})
+}
+
+// ParseDocument parses RAML 1.0 YAML bytes and returns it as a `map[string]any`
+// with all `!include` tags resolved. Library paths in `uses` are replaced with
+// the parsed library documents. Map keys are always strings, including response
+// status codes.
+func ParseDocument(data []byte, baseDir string) (map[string]any, error) {
+	return parseDocument(data, baseDir, map[string]bool{})
+}
+
+// readFileDocument reads a document where `using` is the set of absolute
+// filenames currently being read, used to detect `uses` cycles.
+func readFileDocument(filename string, using map[string]bool) (map[string]any, error) {
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	if using[filename] {
+		return nil, errorsutil.Wrapf(ErrRAMLIncludeCycle, "filename (%s)", filename)
+	}
+	using[filename] = true
+	defer delete(using, filename)
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseDocument(data, filepath.Dir(filename), using)
+	if err != nil {
+		return nil, errorsutil.Wrapf(err, "error parsing raml file (%s)", filename)
+	}
+	return doc, nil
+}
+
+func parseDocument(data []byte, baseDir string, using map[string]bool) (map[string]any, error) {
+	msa, err := ramlutil.ParseDocument(data, baseDir)
+	if err != nil {
+		return nil, err
+	}
+	return msa, resolveUses(msa, baseDir, using)
+}
+
+// resolveUses loads the libraries referenced by `uses`, including libraries
+// used by libraries. It returns `ErrRAMLIncludeCycle` if libraries use each
+// other.
+func resolveUses(doc map[string]any, baseDir string, using map[string]bool) error {
+	uses, ok := doc[RAMLKeyUses].(map[string]any)
+	if !ok {
+		return nil
+	}
+	for _, name := range sortedKeys(uses) {
+		libPath, ok := uses[name].(string)
+		if !ok {
+			continue
+		}
+		libPath = strings.TrimSpace(libPath)
+		if !filepath.IsAbs(libPath) {
+			libPath = filepath.Join(baseDir, libPath)
+		}
+		lib, err := readFileDocument(libPath, using)
+		if err != nil {
+			return errorsutil.Wrapf(err, "error reading raml library (%s)", name)
+		}
+		uses[name] = lib
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package raml10openapi3
+
+import (
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/internal/ramlutil"
+)
+
+const (
+	RAMLSecurityTypeOAuth2      = ramlutil.SecurityTypeOAuth2
+	RAMLSecurityTypeOAuth1      = ramlutil.SecurityTypeOAuth1
+	RAMLSecurityTypeBasic       = ramlutil.SecurityTypeBasic
+	RAMLSecurityTypeDigest      = ramlutil.SecurityTypeDigest
+	RAMLSecurityTypePassThrough = ramlutil.SecurityTypePassThrough
+
+	RAMLKeyDescribedBy = ramlutil.KeyDescribedBy
+	RAMLKeySettings    = ramlutil.KeySettings
+)
+
+// ramlGrantsToFlows maps RAML 1.0 `authorizationGrants` to OpenAPI 3 OAuth flows.
+var ramlGrantsToFlows = map[string]string{
+	"authorization_code": "authorizationCode",
+	"implicit":           "implicit",
+	"password":           "password",
+	"client_credentials": "clientCredentials"}
+
+func (c *converter) convertSecuritySchemes() {
+	for _, name := range sortedKeys(c.schemes) {
+		scheme := c.securityScheme(name, c.schemes[name])
+		if scheme == nil {
+			continue
+		}
+		if c.spec.Components.SecuritySchemes == nil {
+			c.spec.Components.SecuritySchemes = oas3.SecuritySchemes{}
+		}
+		c.spec.Components.SecuritySchemes[name] = &oas3.SecuritySchemeRef{Value: scheme}
+	}
+}
+
+// securityScheme converts a RAML 1.0 security scheme to an OpenAPI 3 security
+// scheme. `Pass Through` and custom `x-` schemes are converted to API keys when
+// `describedBy` declares exactly one header or query parameter. It returns `nil`
+// and adds an issue if the scheme cannot be represented.
+func (c *converter) securityScheme(name string, schemeMap map[string]any) *oas3.SecurityScheme {
+	loc := "/" + RAMLKeySecuritySchemes + "/" + name
+	scheme := ramlutil.SecurityScheme(schemeMap, ramlutil.SecuritySchemeOptions{
+		Grants:       ramlGrantsToFlows,
+		GrantDefault: "authorization_code",
+		Issue:        func(message string) { c.issues.Add(loc, message) }})
+	if scheme != nil {
+		c.applyAnnotations(&scheme.Extensions, schemeMap)
+	}
+	return scheme
+}
@@ -0,0 +1,466 @@
+package raml10openapi3
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	oas3 "github.com/getkin/kin-openapi/openapi3"
+	"github.com/grokify/spectrum/openapi3"
+)
+
+const (
+	RAMLTypeAny          = "any"
+	RAMLTypeArray        = "array"
+	RAMLTypeBoolean      = "boolean"
+	RAMLTypeDateOnly     = "date-only"
+	RAMLTypeDatetime     = "datetime"
+	RAMLTypeDatetimeOnly = "datetime-only"
+	RAMLTypeFile         = "file"
+	RAMLTypeInteger      = "integer"
+	RAMLTypeNil          = "nil"
+	RAMLTypeNumber       = "number"
+	RAMLTypeObject       = "object"
+	RAMLTypeString       = "string"
+	RAMLTypeTimeOnly     = "time-only"
+	RAMLTypeUnion        = "union"
+
+	TypeNumber   = "number"
+	FormatBinary = "binary"
+)
+
+var (
+	rxTypeExprToken  = regexp.MustCompile(`\s*(\[\]|\||\(|\)|[A-Za-z0-9_.\-]+)`)
+	rxPatternPropKey = regexp.MustCompile(`^/.*/$`)
+)
+
+// ramlFormats maps RAML 1.0 number formats to OpenAPI 3 formats.
+var ramlFormats = map[string]string{
+	"int":    openapi3.FormatInt32,
+	"int8":   openapi3.FormatInt32,
+	"int16":  openapi3.FormatInt32,
+	"int32":  openapi3.FormatInt32,
+	"int64":  openapi3.FormatInt64,
+	"long":   openapi3.FormatInt64,
+	"float":  "float",
+	"double": "double"}
+
+// typeExpr is a parsed RAML 1.0 type expression such as `Person[] | nil`.
+type typeExpr struct {
+	name    string
+	items   *typeExpr
+	members []*typeExpr
+}
+
+// parseTypeExpr parses a RAML 1.0 type expression including unions `|`,
+// arrays `[]` and grouping parentheses.
+func parseTypeExpr(s string) (*typeExpr, error) {
+	tokens := []string{}
+	rest := strings.TrimSpace(s)
+	for len(rest) > 0 {
+		loc := rxTypeExprToken.FindStringSubmatchIndex(rest)
+		if loc == nil || loc[0] != 0 {
+			return nil, fmt.Errorf("invalid raml type expression (%s)", s)
+		}
+		tokens = append(tokens, rest[loc[2]:loc[3]])
+		rest = strings.TrimSpace(rest[loc[1]:])
+	}
+	p := &typeExprParser{tokens: tokens}
+	expr, err := p.union()
+	if err != nil {
+		return nil, err
+	} else if p.pos != len(tokens) {
+		return nil, fmt.Errorf("invalid raml type expression (%s)", s)
+	}
+	return expr, nil
+}
+
+type typeExprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *typeExprParser) union() (*typeExpr, error) {
+	first, err := p.postfix()
+	if err != nil {
+		return nil, err
+	}
+	members := []*typeExpr{first}
+	for p.pos < len(p.tokens) && p.tokens[p.pos] == "|" {
+		p.pos++
+		next, err := p.postfix()
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, next)
+	}
+	if len(members) == 1 {
+		return first, nil
+	}
+	return &typeExpr{members: members}, nil
+}
+
+func (p *typeExprParser) postfix() (*typeExpr, error) {
+	expr, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.pos < len(p.tokens) && p.tokens[p.pos] == "[]" {
+		p.pos++
+		expr = &typeExpr{items: expr}
+	}
+	return expr, nil
+}
+
+func (p *typeExprParser) primary() (*typeExpr, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of raml type expression")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	switch tok {
+	case "(":
+		expr, err := p.union()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis in raml type expression")
+		}
+		p.pos++
+		return expr, nil
+	case ")", "|", "[]":
+		return nil, fmt.Errorf("unexpected token (%s) in raml type expression", tok)
+	}
+	return &typeExpr{name: tok}, nil
+}
+
+// builtinSchema returns the OpenAPI 3 schema for a RAML 1.0 built-in type.
+func builtinSchema(name string) (*oas3.Schema, bool) {
+	switch name {
+	case RAMLTypeAny:
+		return oas3.NewSchema(), true
+	case RAMLTypeArray:
+		return oas3.NewArraySchema(), true
+	case RAMLTypeBoolean:
+		return oas3.NewBoolSchema(), true
+	case RAMLTypeDateOnly:
+		return oas3.NewStringSchema().WithFormat(openapi3.FormatDate), true
+	case RAMLTypeDatetime:
+		return oas3.NewDateTimeSchema(), true
+	case RAMLTypeDatetimeOnly, RAMLTypeTimeOnly:
+		return oas3.NewStringSchema(), true
+	case RAMLTypeFile:
+		return oas3.NewStringSchema().WithFormat(FormatBinary), true
+	case RAMLTypeInteger:
+		return oas3.NewIntegerSchema(), true
+	case RAMLTypeNumber:
+		return &oas3.Schema{Type: TypeNumber}, true
+	case RAMLTypeObject:
+		return oas3.NewObjectSchema(), true
+	case RAMLTypeString:
+		return oas3.NewStringSchema(), true
+	}
+	return nil, false
+}
+
+// exprSchemaRef converts a parsed type expression. `ns` is the library
+// namespace used to resolve unqualified type names.
+func (c *converter) exprSchemaRef(expr *typeExpr, ns, loc string) *oas3.SchemaRef {
+	switch {
+	case expr.items != nil:
+		sch := oas3.NewArraySchema()
+		sch.Items = c.exprSchemaRef(expr.items, ns, loc)
+		return oas3.NewSchemaRef("", sch)
+	case len(expr.members) > 0:
+		nullable := false
+		refs := oas3.SchemaRefs{}
+		for _, member := range expr.members {
+			if member.name == RAMLTypeNil {
+				nullable = true
+				continue
+			}
+			refs = append(refs, c.exprSchemaRef(member, ns, loc))
+		}
+		if len(refs) == 0 {
+			c.issues.Add(loc, "union of only `nil` cannot be represented in OpenAPI 3.0")
+			return oas3.NewSchemaRef("", oas3.NewSchema().WithNullable())
+		} else if len(refs) == 1 {
+			if !nullable {
+				return refs[0]
+			} else if refs[0].Ref == "" {
+				refs[0].Value.Nullable = true
+				return refs[0]
+			}
+			sch := oas3.NewAllOfSchema()
+			sch.AllOf = refs
+			sch.Nullable = true
+			return oas3.NewSchemaRef("", sch)
+		}
+		sch := oas3.NewAnyOfSchema()
+		sch.AnyOf = refs
+		sch.Nullable = nullable
+		return oas3.NewSchemaRef("", sch)
+	}
+	if expr.name == RAMLTypeNil {
+		c.issues.Add(loc, "type `nil` cannot be represented in OpenAPI 3.0")
+		return oas3.NewSchemaRef("", oas3.NewSchema().WithNullable())
+	} else if sch, ok := builtinSchema(expr.name); ok {
+		return oas3.NewSchemaRef("", sch)
+	}
+	if qname, ok := c.resolveType(expr.name, ns); ok {
+		return oas3.NewSchemaRef(openapi3.SchemaPointerExpand("", qname), nil)
+	}
+	c.issues.Add(loc, fmt.Sprintf("type `%s` not found", expr.name))
+	return oas3.NewSchemaRef("", oas3.NewSchema())
+}
+
+// resolveType returns the qualified name of a user defined type, trying the
+// library namespace first.
+func (c *converter) resolveType(name, ns string) (string, bool) {
+	if len(ns) > 0 {
+		if _, ok := c.types[ns+"."+name]; ok {
+			return ns + "." + name, true
+		}
+	}
+	if _, ok := c.types[name]; ok {
+		return name, true
+	}
+	return "", false
+}
+
+// typeSchemaRef converts a RAML 1.0 type declaration which is either a type
+// expression string, an inline JSON Schema or a map with facets.
+func (c *converter) typeSchemaRef(decl any, ns, loc string) *oas3.SchemaRef {
+	switch v := decl.(type) {
+	case nil:
+		return oas3.NewSchemaRef("", oas3.NewStringSchema())
+	case string:
+		return c.typeStringSchemaRef(v, ns, loc)
+	case []any:
+		return c.inheritSchemaRef(v, ns, loc)
+	case map[string]any:
+		return c.declSchemaRef(v, ns, loc)
+	}
+	c.issues.Add(loc, fmt.Sprintf("type declaration not supported (%v)", decl))
+	return oas3.NewSchemaRef("", oas3.NewSchema())
+}
+
+func (c *converter) typeStringSchemaRef(s, ns, loc string) *oas3.SchemaRef {
+	trimmed := strings.TrimSpace(s)
+	if strings.HasPrefix(trimmed, "{") {
+		sch, err := openapi3.SchemaFromJSONSchema([]byte(trimmed))
+		if err != nil {
+			c.issues.Add(loc, "json schema cannot be parsed: "+err.Error())
+			return oas3.NewSchemaRef("", oas3.NewSchema())
+		}
+		return oas3.NewSchemaRef("", sch)
+	} else if strings.HasPrefix(trimmed, "<") {
+		c.issues.Add(loc, "xml schema cannot be represented in OpenAPI 3")
+		return oas3.NewSchemaRef("", oas3.NewSchema())
+	}
+	expr, err := parseTypeExpr(trimmed)
+	if err != nil {
+		c.issues.Add(loc, err.Error())
+		return oas3.NewSchemaRef("", oas3.NewSchema())
+	}
+	return c.exprSchemaRef(expr, ns, loc)
+}
+
+// inheritSchemaRef converts multiple inheritance, e.g. `type: [A, B]`, to `allOf`.
+func (c *converter) inheritSchemaRef(parents []any, ns, loc string) *oas3.SchemaRef {
+	sch := oas3.NewAllOfSchema()
+	for _, parent := range parents {
+		sch.AllOf = append(sch.AllOf, c.typeSchemaRef(parent, ns, loc))
+	}
+	return oas3.NewSchemaRef("", sch)
+}
+
+func (c *converter) declSchemaRef(decl map[string]any, ns, loc string) *oas3.SchemaRef {
+	typeAny, ok := decl[RAMLKeyType]
+	if !ok {
+		typeAny = decl[RAMLKeySchema]
+	}
+	var base *oas3.SchemaRef
+	if typeAny == nil {
+		switch {
+		case decl[RAMLKeyProperties] != nil:
+			base = oas3.NewSchemaRef("", oas3.NewObjectSchema())
+		case decl[RAMLKeyItems] != nil:
+			base = oas3.NewSchemaRef("", oas3.NewArraySchema())
+		default:
+			base = oas3.NewSchemaRef("", oas3.NewStringSchema())
+		}
+	} else {
+		base = c.typeSchemaRef(typeAny, ns, loc)
+	}
+	if base.Ref == "" && base.Value != nil && len(base.Value.AllOf) == 0 && len(base.Value.AnyOf) == 0 {
+		c.applyFacets(base.Value, decl, ns, loc)
+		return base
+	}
+	own := oas3.NewSchema()
+	c.applyFacets(own, decl, ns, loc)
+	if reflect.DeepEqual(own, oas3.NewSchema()) {
+		return base
+	}
+	if base.Ref == "" && len(base.Value.AllOf) > 0 {
+		base.Value.AllOf = append(base.Value.AllOf, oas3.NewSchemaRef("", own))
+		return base
+	}
+	sch := oas3.NewAllOfSchema()
+	sch.AllOf = oas3.SchemaRefs{base, oas3.NewSchemaRef("", own)}
+	return oas3.NewSchemaRef("", sch)
+}
+
+// applyFacets sets OpenAPI 3 schema fields from RAML 1.0 facets.
+func (c *converter) applyFacets(sch *oas3.Schema, decl map[string]any, ns, loc string) {
+	if desc, ok := decl[RAMLKeyDescription].(string); ok {
+		sch.Description = strings.TrimSpace(desc)
+	}
+	if dispName, ok := decl[RAMLKeyDisplayName].(string); ok {
+		sch.Title = strings.TrimSpace(dispName)
+	}
+	if enumAny, ok := decl["enum"].([]any); ok {
+		sch.Enum = enumAny
+	}
+	if pattern, ok := decl["pattern"].(string); ok {
+		sch.Pattern = pattern
+	}
+	if v, ok := toFloat(decl["minLength"]); ok && v >= 0 {
+		sch.MinLength = uint64(v)
+	}
+	if v, ok := toFloat(decl["maxLength"]); ok && v >= 0 {
+		maxLength := uint64(v)
+		sch.MaxLength = &maxLength
+	}
+	if v, ok := toFloat(decl["minimum"]); ok {
+		sch.Min = &v
+	}
+	if v, ok := toFloat(decl["maximum"]); ok {
+		sch.Max = &v
+	}
+	if v, ok := toFloat(decl["multipleOf"]); ok {
+		sch.MultipleOf = &v
+	}
+	if v, ok := toFloat(decl["minItems"]); ok && v >= 0 {
+		sch.MinItems = uint64(v)
+	}
+	if v, ok := toFloat(decl["maxItems"]); ok && v >= 0 {
+		maxItems := uint64(v)
+		sch.MaxItems = &maxItems
+	}
+	if v, ok := toFloat(decl["minProperties"]); ok && v >= 0 {
+		sch.MinProps = uint64(v)
+	}
+	if v, ok := toFloat(decl["maxProperties"]); ok && v >= 0 {
+		maxProps := uint64(v)
+		sch.MaxProps = &maxProps
+	}
+	if v, ok := decl["uniqueItems"].(bool); ok {
+		sch.UniqueItems = v
+	}
+	if format, ok := decl["format"].(string); ok {
+		if oasFormat, ok := ramlFormats[format]; ok {
+			sch.Format = oasFormat
+		} else {
+			c.issues.Add(loc, fmt.Sprintf("format `%s` has no OpenAPI 3 equivalent", format))
+		}
+	}
+	if v, ok := decl["default"]; ok {
+		sch.Default = v
+	}
+	if v, ok := decl[RAMLKeyExample]; ok {
+		sch.Example = exampleValue(v)
+	} else if exs := examplesMap(decl[RAMLKeyExamples]); len(exs) > 0 {
+		names := sortedKeys(exs)
+		sch.Example = exs[names[0]]
+	}
+	if itemsAny, ok := decl[RAMLKeyItems]; ok {
+		sch.Items = c.typeSchemaRef(itemsAny, ns, loc+"/items")
+	}
+	if addlProps, ok := decl["additionalProperties"].(bool); ok {
+		sch.AdditionalProperties.Has = &addlProps
+	}
+	if disc, ok := decl["discriminator"].(string); ok {
+		sch.Discriminator = &oas3.Discriminator{PropertyName: disc}
+	}
+	if discVal, ok := decl["discriminatorValue"]; ok {
+		setExtension(&sch.Extensions, "x-discriminator-value", discVal)
+	}
+	if _, ok := decl["facets"]; ok {
+		c.issues.Add(loc, "user defined `facets` cannot be represented in OpenAPI 3")
+	}
+	if _, ok := decl["fileTypes"]; ok {
+		c.issues.Add(loc, "file `fileTypes` cannot be represented in OpenAPI 3 schemas")
+	}
+	if xmlAny, ok := decl["xml"].(map[string]any); ok {
+		sch.XML = xmlFacet(xmlAny)
+	}
+	if props, ok := decl[RAMLKeyProperties].(map[string]any); ok {
+		c.applyProperties(sch, props, ns, loc)
+	}
+	c.applyAnnotations(&sch.Extensions, decl)
+}
+
+func (c *converter) applyProperties(sch *oas3.Schema, props map[string]any, ns, loc string) {
+	if sch.Properties == nil {
+		sch.Properties = oas3.Schemas{}
+	}
+	for _, key := range sortedKeys(props) {
+		propDecl := props[key]
+		if rxPatternPropKey.MatchString(key) {
+			c.issues.Add(loc+"/properties/"+key, "pattern property mapped to `additionalProperties` without pattern")
+			has := true
+			sch.AdditionalProperties = oas3.AdditionalProperties{
+				Has:    &has,
+				Schema: c.typeSchemaRef(propDecl, ns, loc+"/properties/"+key)}
+			continue
+		}
+		name := strings.TrimSuffix(key, "?")
+		required := !strings.HasSuffix(key, "?")
+		if propMap, ok := propDecl.(map[string]any); ok {
+			if req, ok := propMap[RAMLKeyRequired].(bool); ok {
+				required = req
+			}
+		}
+		sch.Properties[name] = c.typeSchemaRef(propDecl, ns, loc+"/properties/"+name)
+		if required {
+			sch.Required = append(sch.Required, name)
+		}
+	}
+}
+
+func xmlFacet(m map[string]any) *oas3.XML {
+	x := &oas3.XML{}
+	x.Name, _ = m["name"].(string)
+	x.Namespace, _ = m["namespace"].(string)
+	x.Prefix, _ = m["prefix"].(string)
+	x.Attribute, _ = m["attribute"].(bool)
+	x.Wrapped, _ = m["wrapped"].(bool)
+	return x
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toFloat(val any) (float64, bool) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}